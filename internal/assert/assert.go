@@ -0,0 +1,273 @@
+// Package assert checks responses against the assertions saved with calls.
+package assert
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/itchyny/gojq"
+	"github.com/suprbdev/reqo/internal/output"
+	"github.com/suprbdev/reqo/internal/project"
+	"github.com/suprbdev/reqo/internal/template"
+)
+
+// Response is what assertions are checked against.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	Duration   time.Duration // until the body was read
+
+	Dir string // relative schema paths are resolved against it; "" for the current directory
+}
+
+// Result is the outcome of one assertion.
+type Result struct {
+	Name    string // e.g. `jq .id exists`
+	Passed  bool
+	Message string // why it failed
+}
+
+// Check evaluates every assertion against resp, in order.
+func Check(resp Response, list []project.Assertion) []Result {
+	c := &checker{resp: resp}
+	results := make([]Result, 0, len(list))
+	for _, a := range list {
+		r := Result{Name: Describe(a)}
+		if err := c.check(a); err != nil {
+			r.Message = err.Error()
+		} else {
+			r.Passed = true
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+// Expand applies template expansion to the strings of every assertion, so
+// that expected values can refer to variables.
+func Expand(list []project.Assertion, vars map[string]string) []project.Assertion {
+	out := make([]project.Assertion, len(list))
+	for i, a := range list {
+		a.Header = template.Expand(a.Header, vars)
+		a.JQ = template.Expand(a.JQ, vars)
+		a.BodyContains = template.Expand(a.BodyContains, vars)
+		a.Schema = template.Expand(a.Schema, vars)
+		a.Matches = template.Expand(a.Matches, vars)
+		a.Equals = template.ExpandValue(a.Equals, vars)
+		out[i] = a
+	}
+	return out
+}
+
+// Failed counts the results that did not pass.
+func Failed(results []Result) int {
+	n := 0
+	for _, r := range results {
+		if !r.Passed {
+			n++
+		}
+	}
+	return n
+}
+
+// Describe renders a as a short human-readable condition.
+func Describe(a project.Assertion) string {
+	switch {
+	case len(a.Status) == 1:
+		return fmt.Sprintf("status == %d", a.Status[0])
+	case len(a.Status) > 1:
+		return fmt.Sprintf("status in %v", []int(a.Status))
+	case a.Header != "":
+		return "header " + a.Header + " " + describeCondition(a)
+	case a.JQ != "":
+		return "jq " + a.JQ + " " + describeCondition(a)
+	case a.BodyContains != "":
+		return fmt.Sprintf("body contains %q", a.BodyContains)
+	case a.TimeUnderMS > 0:
+		return fmt.Sprintf("time < %dms", a.TimeUnderMS)
+	case a.Schema != "":
+		return "schema " + a.Schema
+	}
+	return "(empty assertion)"
+}
+
+func describeCondition(a project.Assertion) string {
+	switch {
+	case a.Equals != nil:
+		return "== " + compact(a.Equals)
+	case a.Matches != "":
+		return "matches " + a.Matches
+	case a.Exists != nil && !*a.Exists:
+		return "does not exist"
+	}
+	return "exists"
+}
+
+type checker struct {
+	resp Response
+
+	parsed  bool
+	json    interface{}
+	jsonErr error
+}
+
+func (c *checker) check(a project.Assertion) error {
+	if n := a.Subjects(); n != 1 {
+		return fmt.Errorf("invalid assertion: set exactly one of status, header, jq, body_contains, time_under_ms or schema (found %d)", n)
+	}
+	switch {
+	case len(a.Status) > 0:
+		for _, code := range a.Status {
+			if c.resp.StatusCode == code {
+				return nil
+			}
+		}
+		return fmt.Errorf("got %d", c.resp.StatusCode)
+	case a.Header != "":
+		if a.Equals != nil {
+			a.Equals = fmt.Sprint(a.Equals) // "equals: 3" matches the header "3"
+		}
+		vals, ok := c.resp.Header[http.CanonicalHeaderKey(a.Header)]
+		if !ok {
+			return compare(a, nil, false)
+		}
+		return compare(a, strings.Join(vals, ", "), true)
+	case a.JQ != "":
+		data, err := c.body()
+		if err != nil {
+			return err
+		}
+		results, err := output.RunJQ(a.JQ, data)
+		if err != nil {
+			return err
+		}
+		var v interface{}
+		switch len(results) {
+		case 0:
+		case 1:
+			v = results[0]
+		default:
+			v = results
+		}
+		return compare(a, v, v != nil)
+	case a.BodyContains != "":
+		if !bytes.Contains(c.resp.Body, []byte(a.BodyContains)) {
+			return fmt.Errorf("not found in the %d-byte body", len(c.resp.Body))
+		}
+		return nil
+	case a.Schema != "":
+		path := a.Schema
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(c.resp.Dir, path)
+		}
+		s, err := LoadSchema(path)
+		if err != nil {
+			return err
+		}
+		violations, err := s.Validate(c.resp.Body)
+		if err != nil {
+			return err
+		}
+		if len(violations) > 0 {
+			msgs := make([]string, len(violations))
+			for i, v := range violations {
+				msgs[i] = v.String()
+			}
+			return fmt.Errorf("%s", strings.Join(msgs, "; "))
+		}
+		return nil
+	default:
+		if ms := c.resp.Duration.Milliseconds(); ms >= int64(a.TimeUnderMS) {
+			return fmt.Errorf("took %dms", ms)
+		}
+		return nil
+	}
+}
+
+// body decodes the response as JSON once.
+func (c *checker) body() (interface{}, error) {
+	if !c.parsed {
+		c.parsed = true
+		if err := output.DecodeJSON(c.resp.Body, &c.json); err != nil {
+			c.jsonErr = fmt.Errorf("response is not JSON: %w", err)
+		}
+	}
+	return c.json, c.jsonErr
+}
+
+// compare applies the Equals, Matches or Exists condition of a to the value
+// found for its subject.
+func compare(a project.Assertion, got interface{}, present bool) error {
+	switch {
+	case a.Equals != nil:
+		if !present {
+			return fmt.Errorf("not present")
+		}
+		if !equal(a.Equals, got) {
+			return fmt.Errorf("got %s", compact(got))
+		}
+	case a.Matches != "":
+		re, err := regexp.Compile(a.Matches)
+		if err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+		if !present {
+			return fmt.Errorf("not present")
+		}
+		s, ok := got.(string)
+		if !ok {
+			s = compact(got)
+		}
+		if !re.MatchString(s) {
+			return fmt.Errorf("got %s", compact(got))
+		}
+	case a.Exists != nil && !*a.Exists:
+		if present {
+			return fmt.Errorf("got %s", compact(got))
+		}
+	default:
+		if !present {
+			return fmt.Errorf("not present")
+		}
+	}
+	return nil
+}
+
+// equal compares a YAML value with a decoded JSON value the way jq's ==
+// does. Both go through JSON and gojq's number handling so that, for
+// example, the YAML int 1 equals the JSON number 1.0 while ids above 2^53
+// are still told apart.
+func equal(want, got interface{}) bool {
+	return gojq.Compare(normalize(want), normalize(got)) == 0
+}
+
+// identity is the jq program ".", run to get gojq's form of a value.
+var identity, _ = gojq.Parse(".")
+
+func normalize(v interface{}) interface{} {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var out interface{}
+	if err := output.DecodeJSON(b, &out); err != nil {
+		return v
+	}
+	out, _ = identity.Run(out).Next()
+	return out
+}
+
+func compact(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
@@ -0,0 +1,128 @@
+package assert
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/suprbdev/reqo/internal/project"
+)
+
+func boolPtr(b bool) *bool { return &b }
+
+func TestCheck(t *testing.T) {
+	resp := Response{
+		StatusCode: 201,
+		Header:     http.Header{"Content-Type": {"application/json"}, "X-Count": {"3"}},
+		Body:       []byte(`{"id":7,"name":"Ada","email":"ada@example.com","active":false,"tags":["a","b"],"meta":null,"big":9007199254740993,"price":2.50}`),
+		Duration:   120 * time.Millisecond,
+	}
+	tests := []struct {
+		name string
+		a    project.Assertion
+		pass bool
+		msg  string
+	}{
+		{"status equals", project.Assertion{Status: project.StatusCodes{201}}, true, ""},
+		{"status mismatch", project.Assertion{Status: project.StatusCodes{200}}, false, "got 201"},
+		{"status in", project.Assertion{Status: project.StatusCodes{200, 201}}, true, ""},
+		{"header exists", project.Assertion{Header: "content-type"}, true, ""},
+		{"header missing", project.Assertion{Header: "ETag"}, false, "not present"},
+		{"header absent", project.Assertion{Header: "ETag", Exists: boolPtr(false)}, true, ""},
+		{"header matches", project.Assertion{Header: "Content-Type", Matches: "^application/"}, true, ""},
+		{"header equals number", project.Assertion{Header: "X-Count", Equals: 3}, true, ""},
+		{"jq equals number", project.Assertion{JQ: ".id", Equals: 7}, true, ""},
+		{"jq equals large id", project.Assertion{JQ: ".big", Equals: 9007199254740993}, true, ""},
+		{"jq large id off by one", project.Assertion{JQ: ".big", Equals: 9007199254740992}, false, "got 9007199254740993"},
+		{"jq equals decimal", project.Assertion{JQ: ".price", Equals: 2.5}, true, ""},
+		{"jq equals false", project.Assertion{JQ: ".active", Equals: false}, true, ""},
+		{"jq equals list", project.Assertion{JQ: ".tags", Equals: []interface{}{"a", "b"}}, true, ""},
+		{"jq equals mismatch", project.Assertion{JQ: ".name", Equals: "Bob"}, false, `got "Ada"`},
+		{"jq matches", project.Assertion{JQ: ".email", Matches: "@example\\.com$"}, true, ""},
+		{"jq exists", project.Assertion{JQ: ".id"}, true, ""},
+		{"jq null does not exist", project.Assertion{JQ: ".meta"}, false, "not present"},
+		{"jq absent", project.Assertion{JQ: ".missing", Exists: boolPtr(false)}, true, ""},
+		{"jq error", project.Assertion{JQ: ".["}, false, "invalid jq expression"},
+		{"body contains", project.Assertion{BodyContains: `"Ada"`}, true, ""},
+		{"body missing", project.Assertion{BodyContains: "Bob"}, false, "not found"},
+		{"time under", project.Assertion{TimeUnderMS: 500}, true, ""},
+		{"time over", project.Assertion{TimeUnderMS: 100}, false, "took 120ms"},
+		{"no subject", project.Assertion{}, false, "invalid assertion"},
+		{"two subjects", project.Assertion{JQ: ".id", Header: "X-Count"}, false, "invalid assertion"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Check(resp, []project.Assertion{tt.a})[0]
+			if r.Passed != tt.pass {
+				t.Fatalf("passed = %v (%s)", r.Passed, r.Message)
+			}
+			if tt.msg != "" && !strings.Contains(r.Message, tt.msg) {
+				t.Errorf("message = %q, want %q", r.Message, tt.msg)
+			}
+		})
+	}
+}
+
+func TestCheck_Schema(t *testing.T) {
+	path := writeSchema(t, t.TempDir(), "s.json", `{"type": "object", "required": ["id"], "properties": {"id": {"type": "string"}}}`)
+	resp := Response{StatusCode: 200, Body: []byte(`{"id": 7}`)}
+	r := Check(resp, []project.Assertion{{Schema: path}})[0]
+	if r.Passed || r.Message != "at /id: got number, want string" {
+		t.Errorf("schema result = %+v", r)
+	}
+	if r.Name != "schema "+path {
+		t.Errorf("name = %q", r.Name)
+	}
+	r = Check(resp, []project.Assertion{{Schema: "missing.json"}})[0]
+	if r.Passed || !strings.Contains(r.Message, "load schema missing.json") {
+		t.Errorf("missing schema = %+v", r)
+	}
+
+	// relative to Dir rather than the current directory
+	dir := t.TempDir()
+	writeSchema(t, dir, "user.json", `{"required": ["name"]}`)
+	resp.Dir = dir
+	if r := Check(resp, []project.Assertion{{Schema: "user.json"}})[0]; r.Passed || r.Message != "at /: missing property 'name'" || r.Name != "schema user.json" {
+		t.Errorf("schema in Dir = %+v", r)
+	}
+}
+
+func TestCheck_NotJSON(t *testing.T) {
+	results := Check(Response{StatusCode: 200, Body: []byte("<html>")}, []project.Assertion{{JQ: ".id"}, {Status: project.StatusCodes{200}}})
+	if results[0].Passed || !strings.Contains(results[0].Message, "not JSON") {
+		t.Errorf("jq on HTML = %+v", results[0])
+	}
+	if !results[1].Passed || Failed(results) != 1 {
+		t.Errorf("Failed = %d", Failed(results))
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	tests := map[string]project.Assertion{
+		"status == 200":              {Status: project.StatusCodes{200}},
+		"status in [200 204]":        {Status: project.StatusCodes{200, 204}},
+		"header ETag exists":         {Header: "ETag"},
+		"header ETag does not exist": {Header: "ETag", Exists: boolPtr(false)},
+		`jq .name == "Ada"`:          {JQ: ".name", Equals: "Ada"},
+		"jq .email matches @x$":      {JQ: ".email", Matches: "@x$"},
+		`body contains "ok"`:         {BodyContains: "ok"},
+		"time < 250ms":               {TimeUnderMS: 250},
+	}
+	for want, a := range tests {
+		if got := Describe(a); got != want {
+			t.Errorf("Describe = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestExpand(t *testing.T) {
+	in := []project.Assertion{{JQ: ".id", Equals: "${id}"}, {Header: "X-${h}", Matches: "^${v}$"}}
+	out := Expand(in, map[string]string{"id": "42", "h": "Trace", "v": "abc"})
+	if out[0].Equals != "42" || out[1].Header != "X-Trace" || out[1].Matches != "^abc$" {
+		t.Errorf("Expand = %+v", out)
+	}
+	if in[0].Equals != "${id}" {
+		t.Error("input should not be modified")
+	}
+}
@@ -0,0 +1,90 @@
+package assert
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+var printer = message.NewPrinter(language.English)
+
+// Schema is a compiled JSON Schema. Draft 2020-12 is assumed unless the
+// document names another draft in "$schema".
+type Schema struct {
+	Path string
+	s    *jsonschema.Schema
+}
+
+// LoadSchema compiles the schema at path; relative "$ref"s are resolved
+// against its directory.
+func LoadSchema(path string) (*Schema, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	c := jsonschema.NewCompiler()
+	c.DefaultDraft(jsonschema.Draft2020)
+	s, err := c.Compile(abs)
+	if err != nil {
+		return nil, fmt.Errorf("load schema %s: %w", path, err)
+	}
+	return &Schema{Path: path, s: s}, nil
+}
+
+// Violation is one place where a document breaks the schema.
+type Violation struct {
+	Path    string // JSON pointer into the document, "/" for the root
+	Message string
+}
+
+func (v Violation) String() string { return "at " + v.Path + ": " + v.Message }
+
+// Validate checks a JSON document and returns its violations, most
+// specific first and sorted by path; none means the document is valid.
+func (s *Schema) Validate(doc []byte) ([]Violation, error) {
+	v, err := jsonschema.UnmarshalJSON(bytes.NewReader(doc))
+	if err != nil {
+		return nil, fmt.Errorf("response is not JSON: %w", err)
+	}
+	err = s.s.Validate(v)
+	if err == nil {
+		return nil, nil
+	}
+	verr, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return nil, err
+	}
+	var out []Violation
+	collectViolations(verr, &out)
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Path < out[j].Path })
+	return out, nil
+}
+
+// collectViolations gathers the leaves of the error tree: "allOf failed"
+// and the like only summarise their causes.
+func collectViolations(e *jsonschema.ValidationError, out *[]Violation) {
+	if len(e.Causes) > 0 {
+		for _, c := range e.Causes {
+			collectViolations(c, out)
+		}
+		return
+	}
+	*out = append(*out, Violation{Path: JSONPointer(e.InstanceLocation), Message: e.ErrorKind.LocalizedString(printer)})
+}
+
+// JSONPointer joins reference tokens into a JSON Pointer such as /items/0,
+// escaping ~ and / inside them.
+func JSONPointer(tokens []string) string {
+	r := strings.NewReplacer("~", "~0", "/", "~1")
+	out := make([]string, len(tokens))
+	for i, t := range tokens {
+		out[i] = r.Replace(t)
+	}
+	return "/" + strings.Join(out, "/")
+}
@@ -0,0 +1,83 @@
+package assert
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeSchema(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+const userSchema = `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "type": "object",
+  "required": ["id", "email"],
+  "properties": {
+    "id": {"type": "integer"},
+    "email": {"type": "string"},
+    "roles": {"type": "array", "items": {"$ref": "role.json"}}
+  }
+}`
+
+func TestSchema_Validate(t *testing.T) {
+	dir := t.TempDir()
+	writeSchema(t, dir, "role.json", `{"type": "object", "required": ["name"], "properties": {"name": {"enum": ["admin", "user"]}}}`)
+	s, err := LoadSchema(writeSchema(t, dir, "user.json", userSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := s.Validate([]byte(`{"id": 1, "email": "a@b.c", "roles": [{"name": "admin"}]}`))
+	if err != nil || len(v) != 0 {
+		t.Errorf("valid document: %v, %v", v, err)
+	}
+
+	v, err = s.Validate([]byte(`{"id": "1", "roles": [{"name": "admin"}, {"name": "root"}, {}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, x := range v {
+		got = append(got, x.String())
+	}
+	joined := strings.Join(got, "\n")
+	for _, want := range []string{"at /: missing property 'email'", "at /id: got string, want integer", "at /roles/1/name: value must be one of", "at /roles/2: missing property 'name'"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("violations should contain %q:\n%s", want, joined)
+		}
+	}
+	if v[0].Path != "/" {
+		t.Errorf("violations should be sorted by path: %v", got)
+	}
+
+	if _, err := s.Validate([]byte("<html>")); err == nil || !strings.Contains(err.Error(), "not JSON") {
+		t.Errorf("expected not JSON error, got %v", err)
+	}
+}
+
+func TestLoadSchema_Errors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := LoadSchema(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("missing schema should error")
+	}
+	if _, err := LoadSchema(writeSchema(t, dir, "bad.json", `{"type": 12}`)); err == nil || !strings.Contains(err.Error(), "load schema") {
+		t.Errorf("invalid schema should error, got %v", err)
+	}
+}
+
+func TestJSONPointer(t *testing.T) {
+	if got := JSONPointer([]string{"a/b", "c~d"}); got != "/a~1b/c~0d" {
+		t.Errorf("JSONPointer = %s", got)
+	}
+	if got := JSONPointer(nil); got != "/" {
+		t.Errorf("JSONPointer(nil) = %s", got)
+	}
+}
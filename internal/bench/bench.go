@@ -0,0 +1,182 @@
+// Package bench sends a request repeatedly with bounded concurrency and an
+// optional rate limit and summarizes latencies, statuses and errors.
+package bench
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Options bound a run. It ends after Requests requests or when Duration
+// has passed, whichever comes first; at least one of them should be set.
+type Options struct {
+	Requests    int           // 0 for no limit
+	Concurrency int           // requests in flight at once
+	Duration    time.Duration // 0 for no limit
+	Rate        float64       // requests started per second, 0 for no limit
+}
+
+// Sample is the outcome of one request.
+type Sample struct {
+	Latency time.Duration // until the body was read
+	Status  int
+	Bytes   int64
+	Err     error
+}
+
+// Run calls do as often as o allows and collects the samples. Requests cut
+// off when Duration runs out are not counted.
+func Run(ctx context.Context, o Options, do func(context.Context) Sample) *Result {
+	if o.Duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.Duration)
+		defer cancel()
+	}
+	conc := max(o.Concurrency, 1)
+	if o.Requests > 0 {
+		conc = min(conc, o.Requests)
+	}
+
+	jobs := make(chan struct{})
+	go func() {
+		defer close(jobs)
+		var tick <-chan time.Time
+		if o.Rate > 0 {
+			// a rate above 1e9/s would round the interval down to zero
+			t := time.NewTicker(time.Duration(math.Max(float64(time.Second)/o.Rate, 1)))
+			defer t.Stop()
+			tick = t.C
+		}
+		for i := 0; o.Requests == 0 || i < o.Requests; i++ {
+			if tick != nil && i > 0 {
+				select {
+				case <-tick:
+				case <-ctx.Done():
+					return
+				}
+			}
+			select {
+			case jobs <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	start := time.Now()
+	var mu sync.Mutex
+	var samples []Sample
+	var wg sync.WaitGroup
+	for w := 0; w < conc; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range jobs {
+				s := do(ctx)
+				if s.Err != nil && ctx.Err() != nil {
+					continue // cut off at the end of the run
+				}
+				mu.Lock()
+				samples = append(samples, s)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return summarize(samples, time.Since(start), conc)
+}
+
+// Result summarizes a run.
+type Result struct {
+	Requests    int
+	Concurrency int
+	Elapsed     time.Duration
+	Throughput  float64 // requests per second
+	Bytes       int64   // response bodies
+	Statuses    map[int]int
+	Errors      map[string]int // requests without a response, per message
+	Latency     Latency        // of requests that got a response
+}
+
+// Latency holds the distribution of response times.
+type Latency struct {
+	Min, Mean, P50, P90, P95, P99, Max time.Duration
+}
+
+// ErrorCount is the number of requests that got no response.
+func (r *Result) ErrorCount() int {
+	n := 0
+	for _, c := range r.Errors {
+		n += c
+	}
+	return n
+}
+
+func summarize(samples []Sample, elapsed time.Duration, conc int) *Result {
+	r := &Result{
+		Requests:    len(samples),
+		Concurrency: conc,
+		Elapsed:     elapsed,
+		Statuses:    map[int]int{},
+		Errors:      map[string]int{},
+	}
+	if elapsed > 0 {
+		r.Throughput = float64(len(samples)) / elapsed.Seconds()
+	}
+	var lat []time.Duration
+	var sum time.Duration
+	for _, s := range samples {
+		r.Bytes += s.Bytes
+		if s.Err != nil {
+			r.Errors[s.Err.Error()]++
+			continue
+		}
+		r.Statuses[s.Status]++
+		lat = append(lat, s.Latency)
+		sum += s.Latency
+	}
+	if len(lat) == 0 {
+		return r
+	}
+	sort.Slice(lat, func(i, j int) bool { return lat[i] < lat[j] })
+	r.Latency = Latency{
+		Min:  lat[0],
+		Mean: sum / time.Duration(len(lat)),
+		P50:  percentile(lat, 50),
+		P90:  percentile(lat, 90),
+		P95:  percentile(lat, 95),
+		P99:  percentile(lat, 99),
+		Max:  lat[len(lat)-1],
+	}
+	return r
+}
+
+// percentile uses the nearest-rank method on sorted values.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	i := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	return sorted[max(i, 0)]
+}
+
+// ParseRate parses a --rate value: "100", "100/s" or "600/m".
+func ParseRate(s string) (float64, error) {
+	num, unit, _ := strings.Cut(strings.TrimSpace(s), "/")
+	n, err := strconv.ParseFloat(num, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid rate %q (want e.g. 100/s or 600/m)", s)
+	}
+	switch unit {
+	case "", "s":
+		return n, nil
+	case "m":
+		return n / 60, nil
+	case "h":
+		return n / 3600, nil
+	}
+	return 0, fmt.Errorf("invalid rate %q (want e.g. 100/s or 600/m)", s)
+}
@@ -0,0 +1,102 @@
+package bench
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRun_Requests(t *testing.T) {
+	var calls, inFlight, peak atomic.Int64
+	r := Run(context.Background(), Options{Requests: 50, Concurrency: 5}, func(context.Context) Sample {
+		n := calls.Add(1)
+		cur := inFlight.Add(1)
+		for {
+			p := peak.Load()
+			if cur <= p || peak.CompareAndSwap(p, cur) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		inFlight.Add(-1)
+		if n%10 == 0 {
+			return Sample{Err: errors.New("boom")}
+		}
+		return Sample{Latency: time.Duration(n) * time.Millisecond, Status: 200, Bytes: 10}
+	})
+	if calls.Load() != 50 || r.Requests != 50 {
+		t.Errorf("calls = %d, requests = %d", calls.Load(), r.Requests)
+	}
+	if peak.Load() > 5 {
+		t.Errorf("concurrency exceeded: %d", peak.Load())
+	}
+	if r.Statuses[200] != 45 || r.Errors["boom"] != 5 || r.ErrorCount() != 5 || r.Bytes != 450 {
+		t.Errorf("result = %+v", r)
+	}
+	if r.Latency.Min != time.Millisecond || r.Latency.Max != 49*time.Millisecond || r.Throughput <= 0 {
+		t.Errorf("latency = %+v, throughput %f", r.Latency, r.Throughput)
+	}
+}
+
+func TestRun_DurationAndRate(t *testing.T) {
+	start := time.Now()
+	r := Run(context.Background(), Options{Concurrency: 4, Duration: 200 * time.Millisecond, Rate: 50}, func(ctx context.Context) Sample {
+		return Sample{Status: 204}
+	})
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("run took %s", d)
+	}
+	// 50/s for 0.2s is about 10 requests
+	if r.Requests < 5 || r.Requests > 15 {
+		t.Errorf("requests = %d", r.Requests)
+	}
+}
+
+func TestRun_RateAboveOnePerNanosecond(t *testing.T) {
+	r := Run(context.Background(), Options{Requests: 5, Concurrency: 1, Rate: 1e12}, func(ctx context.Context) Sample {
+		return Sample{Status: 204}
+	})
+	if r.Requests != 5 {
+		t.Errorf("requests = %d", r.Requests)
+	}
+}
+
+func TestRun_CutOffNotCounted(t *testing.T) {
+	r := Run(context.Background(), Options{Concurrency: 2, Duration: 50 * time.Millisecond}, func(ctx context.Context) Sample {
+		<-ctx.Done()
+		return Sample{Err: ctx.Err()}
+	})
+	if r.Requests != 0 || len(r.Errors) != 0 {
+		t.Errorf("cut-off requests counted: %+v", r)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	var lat []time.Duration
+	for i := 1; i <= 100; i++ {
+		lat = append(lat, time.Duration(i))
+	}
+	for p, want := range map[float64]time.Duration{50: 50, 90: 90, 99: 99, 100: 100} {
+		if got := percentile(lat, p); got != want {
+			t.Errorf("p%v = %d, want %d", p, got, want)
+		}
+	}
+	if got := percentile(lat[:1], 99); got != 1 {
+		t.Errorf("single value = %d", got)
+	}
+}
+
+func TestParseRate(t *testing.T) {
+	for in, want := range map[string]float64{"100": 100, "100/s": 100, "600/m": 10, "7200/h": 2} {
+		if got, err := ParseRate(in); err != nil || got != want {
+			t.Errorf("ParseRate(%q) = %v, %v", in, got, err)
+		}
+	}
+	for _, in := range []string{"", "0", "-1/s", "10/d", "x"} {
+		if _, err := ParseRate(in); err == nil {
+			t.Errorf("ParseRate(%q) should fail", in)
+		}
+	}
+}
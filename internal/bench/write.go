@@ -0,0 +1,116 @@
+package bench
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/suprbdev/reqo/internal/output"
+)
+
+// WriteText prints r for people, e.g.
+//
+//	Requests:    1000 (20 concurrent) in 1.24s, 0 errors
+//	Throughput:  806.5 req/s, 1.2 MiB/s
+//	Latency:     min 3.1ms  mean 24.6ms  p50 21ms  p90 40ms  p95 52ms  p99 81ms  max 120ms
+//	Status:      990 × 200, 10 × 503
+func WriteText(w io.Writer, r *Result) {
+	fmt.Fprintf(w, "Requests:    %d (%d concurrent) in %s, %d errors\n",
+		r.Requests, r.Concurrency, round(r.Elapsed), r.ErrorCount())
+	rate := "0 B"
+	if r.Elapsed > 0 {
+		rate = output.HumanBytes(int64(float64(r.Bytes) / r.Elapsed.Seconds()))
+	}
+	fmt.Fprintf(w, "Throughput:  %.1f req/s, %s/s\n", r.Throughput, rate)
+	if len(r.Statuses) > 0 {
+		l := r.Latency
+		fmt.Fprintf(w, "Latency:     min %s  mean %s  p50 %s  p90 %s  p95 %s  p99 %s  max %s\n",
+			round(l.Min), round(l.Mean), round(l.P50), round(l.P90), round(l.P95), round(l.P99), round(l.Max))
+		codes := make([]int, 0, len(r.Statuses))
+		for c := range r.Statuses {
+			codes = append(codes, c)
+		}
+		sort.Ints(codes)
+		parts := make([]string, len(codes))
+		for i, c := range codes {
+			parts[i] = fmt.Sprintf("%d × %d", r.Statuses[c], c)
+		}
+		fmt.Fprintf(w, "Status:      %s\n", strings.Join(parts, ", "))
+	}
+	if len(r.Errors) > 0 {
+		msgs := make([]string, 0, len(r.Errors))
+		for m := range r.Errors {
+			msgs = append(msgs, m)
+		}
+		// most frequent first
+		sort.Slice(msgs, func(i, j int) bool {
+			if r.Errors[msgs[i]] != r.Errors[msgs[j]] {
+				return r.Errors[msgs[i]] > r.Errors[msgs[j]]
+			}
+			return msgs[i] < msgs[j]
+		})
+		fmt.Fprintln(w, "Errors:")
+		for _, m := range msgs {
+			fmt.Fprintf(w, "  %d × %s\n", r.Errors[m], m)
+		}
+	}
+}
+
+// jsonResult is the JSON form of a Result, with times in milliseconds.
+type jsonResult struct {
+	Requests    int                `json:"requests"`
+	Concurrency int                `json:"concurrency"`
+	DurationMS  float64            `json:"duration_ms"`
+	Throughput  float64            `json:"throughput"`
+	Bytes       int64              `json:"bytes"`
+	Statuses    map[string]int     `json:"statuses"`
+	Errors      map[string]int     `json:"errors"`
+	LatencyMS   map[string]float64 `json:"latency_ms,omitempty"`
+}
+
+// WriteJSON prints r as an indented JSON object.
+func WriteJSON(w io.Writer, r *Result) error {
+	j := jsonResult{
+		Requests:    r.Requests,
+		Concurrency: r.Concurrency,
+		DurationMS:  ms(r.Elapsed),
+		Throughput:  r.Throughput,
+		Bytes:       r.Bytes,
+		Statuses:    map[string]int{},
+		Errors:      r.Errors,
+	}
+	for c, n := range r.Statuses {
+		j.Statuses[strconv.Itoa(c)] = n
+	}
+	if len(r.Statuses) > 0 {
+		l := r.Latency
+		j.LatencyMS = map[string]float64{
+			"min": ms(l.Min), "mean": ms(l.Mean), "p50": ms(l.P50), "p90": ms(l.P90),
+			"p95": ms(l.P95), "p99": ms(l.P99), "max": ms(l.Max),
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(j)
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// round keeps three significant digits or so: 1.234567s → 1.23s.
+func round(d time.Duration) time.Duration {
+	switch {
+	case d >= time.Second:
+		return d.Round(10 * time.Millisecond)
+	case d >= 10*time.Millisecond:
+		return d.Round(100 * time.Microsecond)
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond)
+	}
+	return d.Round(time.Microsecond)
+}
@@ -0,0 +1,58 @@
+package bench
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func sampleResult() *Result {
+	return &Result{
+		Requests:    100,
+		Concurrency: 10,
+		Elapsed:     2 * time.Second,
+		Throughput:  50,
+		Bytes:       2048,
+		Statuses:    map[int]int{200: 97, 503: 1},
+		Errors:      map[string]int{"dial tcp: refused": 2},
+		Latency: Latency{
+			Min: time.Millisecond, Mean: 12 * time.Millisecond, P50: 10 * time.Millisecond,
+			P90: 20 * time.Millisecond, P95: 25 * time.Millisecond, P99: 40 * time.Millisecond, Max: 41234 * time.Microsecond,
+		},
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	var buf bytes.Buffer
+	WriteText(&buf, sampleResult())
+	want := `Requests:    100 (10 concurrent) in 2s, 2 errors
+Throughput:  50.0 req/s, 1.0 KiB/s
+Latency:     min 1ms  mean 12ms  p50 10ms  p90 20ms  p95 25ms  p99 40ms  max 41.2ms
+Status:      97 × 200, 1 × 503
+Errors:
+  2 × dial tcp: refused
+`
+	if buf.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, sampleResult()); err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	lat := got["latency_ms"].(map[string]interface{})
+	if got["requests"] != 100.0 || got["duration_ms"] != 2000.0 || lat["max"] != 41.234 || lat["p99"] != 40.0 {
+		t.Errorf("got %s", buf.String())
+	}
+	if got["statuses"].(map[string]interface{})["503"] != 1.0 || !strings.Contains(buf.String(), `"dial tcp: refused": 2`) {
+		t.Errorf("statuses/errors: %s", buf.String())
+	}
+}
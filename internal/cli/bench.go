@@ -0,0 +1,154 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/suprbdev/reqo/internal/bench"
+	httpx "github.com/suprbdev/reqo/internal/http"
+	"github.com/suprbdev/reqo/internal/project"
+)
+
+// newBenchCmd load-tests a saved call.
+func newBenchCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bench <alias>",
+		Short: "Send a saved call repeatedly and report latencies and throughput",
+		Long: `Send a saved HTTP call -n times with -c requests in flight, or for
+--duration when given, optionally limited to --rate requests per second.
+All requests share one connection pool sized for the concurrency. The
+report lists latency percentiles, throughput, a histogram of status codes
+and the errors of requests that got no response.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runBench,
+	}
+	cmd.Flags().IntP("requests", "n", 100, "number of requests (no limit with --duration unless set)")
+	cmd.Flags().IntP("concurrency", "c", 10, "requests in flight at once")
+	cmd.Flags().Duration("duration", 0, "run for this long, e.g. 30s")
+	cmd.Flags().String("rate", "", "start at most this many requests, e.g. 100/s or 600/m")
+	cmd.Flags().StringP("output", "o", "text", "report format (text|json)")
+	cmd.Flags().String("env", "", "environment to use")
+	cmd.Flags().StringArray("var", nil, "variables for template expansion (key=value)")
+	cmd.Flags().StringArray("header", nil, "extra header (Key: Value)")
+	cmd.Flags().Int("timeout", 30, "timeout per request in seconds")
+	cmd.Flags().BoolP("insecure", "k", false, "skip TLS certificate verification")
+	return cmd
+}
+
+func runBench(cmd *cobra.Command, args []string) error {
+	alias := args[0]
+	format := getString(cmd, "output")
+	if format != "text" && format != "json" {
+		return fmt.Errorf("unknown report format %q (supported: text, json)", format)
+	}
+	opts := bench.Options{
+		Requests:    getInt(cmd, "requests"),
+		Concurrency: getInt(cmd, "concurrency"),
+	}
+	opts.Duration, _ = cmd.Flags().GetDuration("duration")
+	if opts.Duration > 0 && !cmd.Flags().Changed("requests") {
+		opts.Requests = 0
+	}
+	if opts.Requests < 0 || opts.Concurrency < 1 || opts.Duration < 0 {
+		return fmt.Errorf("-n and --duration must not be negative and -c must be at least 1")
+	}
+	if opts.Requests == 0 && opts.Duration == 0 {
+		return fmt.Errorf("give -n or --duration")
+	}
+	if r := getString(cmd, "rate"); r != "" {
+		var err error
+		if opts.Rate, err = bench.ParseRate(r); err != nil {
+			return err
+		}
+	}
+
+	p, err := resolveProject(cmd)
+	if err != nil {
+		return err
+	}
+	call, ok := p.Project.Calls[alias]
+	if !ok {
+		return fmt.Errorf("call %q not defined in project %s", alias, p.Project.Name)
+	}
+	if call.Type != project.CallHTTP {
+		return fmt.Errorf("%q is a %s call, not an HTTP call", alias, call.Type)
+	}
+	vars := parseVars(cmd)
+	first, err := buildCallRequest(cmd, p, alias, vars)
+	if err != nil {
+		return err
+	}
+	// requests are cloned from the first one unless its body can only be
+	// read once, like a streamed file, which is opened again each time;
+	// standard input can only be read once, so it is kept in memory
+	streamed := first.Body != nil && first.Body != http.NoBody && first.GetBody == nil
+	if streamed && httpx.BinaryFile(first) == "-" {
+		data, err := io.ReadAll(first.Body)
+		first.Body.Close()
+		if err != nil {
+			return fmt.Errorf("read body from stdin: %w", err)
+		}
+		first.ContentLength = int64(len(data))
+		first.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(data)), nil }
+		streamed = false
+	} else if first.Body != nil {
+		first.Body.Close()
+	}
+	newRequest := func() (*http.Request, error) {
+		if streamed {
+			return buildCallRequest(cmd, p, alias, vars)
+		}
+		req := first.Clone(context.Background())
+		if first.GetBody != nil {
+			body, err := first.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+		return req, nil
+	}
+
+	execOpts := httpx.ExecOpts{
+		Timeout:      time.Duration(getInt(cmd, "timeout")) * time.Second,
+		MaxRedirects: 10,
+		Insecure:     getBool(cmd, "insecure"),
+	}
+	client := httpx.NewClient(httpx.NewTransport(execOpts.Insecure, opts.Concurrency), execOpts)
+	defer client.CloseIdleConnections()
+
+	limit := fmt.Sprintf("%d requests", opts.Requests)
+	if opts.Requests == 0 {
+		limit = opts.Duration.String()
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "Benchmarking %s %s (%s, %d concurrent)…\n", first.Method, first.URL.Redacted(), limit, opts.Concurrency)
+
+	result := bench.Run(context.Background(), opts, func(ctx context.Context) bench.Sample {
+		req, err := newRequest()
+		if err != nil {
+			return bench.Sample{Err: err}
+		}
+		start := time.Now()
+		resp, err := httpx.Execute(ctx, client, req, execOpts)
+		if err != nil {
+			return bench.Sample{Err: err}
+		}
+		n, err := io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return bench.Sample{Err: err}
+		}
+		return bench.Sample{Latency: time.Since(start), Status: resp.StatusCode, Bytes: n}
+	})
+
+	if format == "json" {
+		return bench.WriteJSON(cmd.OutOrStdout(), result)
+	}
+	bench.WriteText(cmd.OutOrStdout(), result)
+	return nil
+}
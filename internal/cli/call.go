@@ -1,15 +1,13 @@
 package cli
 
 import (
-	"context"
 	"fmt"
-	"os"
+	"net/http"
+	"sort"
 	"strings"
-	"time"
 
 	"github.com/spf13/cobra"
 	httpx "github.com/suprbdev/reqo/internal/http"
-	"github.com/suprbdev/reqo/internal/output"
 	"github.com/suprbdev/reqo/internal/project"
 	"github.com/suprbdev/reqo/internal/template"
 )
@@ -19,16 +17,16 @@ func newCallCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "call",
 		Short: "Manage and run saved calls (aliases)",
-        Args:  cobra.ArbitraryArgs,
-        RunE: func(cmd *cobra.Command, args []string) error {
-            // If a known subcommand matched, Cobra won't call this.
-            // If we are here and an argument is provided, treat it as an alias and run it.
-            if len(args) == 0 {
-                return cmd.Help()
-            }
-            alias := args[0]
-            return executeCall(cmd, alias)
-        },
+		Args:  cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// If a known subcommand matched, Cobra won't call this.
+			// If we are here and an argument is provided, treat it as an alias and run it.
+			if len(args) == 0 {
+				return cmd.Help()
+			}
+			alias := args[0]
+			return executeCall(cmd, alias)
+		},
 	}
 
 	// call create <alias> <method> <path>
@@ -42,7 +40,16 @@ func newCallCmd() *cobra.Command {
 			desc, _ := cmd.Flags().GetString("desc")
 			jsonBody, _ := cmd.Flags().GetString("json")
 			rawBody, _ := cmd.Flags().GetString("data")
-			formFields, _ := cmd.Flags().GetStringToString("form")
+			binBody, _ := cmd.Flags().GetString("data-binary")
+			formFields, err := formFlag(cmd)
+			if err != nil {
+				return err
+			}
+			urlEncoded, _ := cmd.Flags().GetStringArray("form-urlencoded")
+			gqlQuery, _ := cmd.Flags().GetString("graphql")
+			gqlVars, _ := cmd.Flags().GetString("variables")
+			gqlOp, _ := cmd.Flags().GetString("operation-name")
+			tags, _ := cmd.Flags().GetStringArray("tag")
 
 			p, err := resolveProject(cmd)
 			if err != nil {
@@ -57,9 +64,10 @@ func newCallCmd() *cobra.Command {
 				Path:         path,
 				UseHeaderSet: useHeaderSet,
 				Description:  desc,
+				Tags:         tags,
 			}
 
-			if jsonBody != "" || rawBody != "" || len(formFields) > 0 {
+			if jsonBody != "" || rawBody != "" || binBody != "" || len(formFields) > 0 || len(urlEncoded) > 0 || gqlQuery != "" {
 				bodySpec := &project.BodySpec{}
 				if jsonBody != "" {
 					bodySpec.JSON = &jsonBody
@@ -67,9 +75,35 @@ func newCallCmd() *cobra.Command {
 				if rawBody != "" {
 					bodySpec.Raw = &rawBody
 				}
+				if binBody != "" {
+					bodySpec.Binary = &binBody
+				}
 				if len(formFields) > 0 {
 					bodySpec.Form = formFields
 				}
+				if len(urlEncoded) > 0 {
+					for _, f := range urlEncoded {
+						if !strings.Contains(f, "=") {
+							return fmt.Errorf("invalid form field %q – must be k=v", f)
+						}
+					}
+					bodySpec.URLEncoded = urlEncoded
+				}
+				if gqlQuery != "" {
+					gql := &project.GraphQLSpec{Query: gqlQuery, OperationName: gqlOp}
+					if gqlVars != "" {
+						v, err := httpx.ParseGraphQLVariables(gqlVars)
+						if err != nil {
+							return err
+						}
+						vm, ok := v.(map[string]interface{})
+						if !ok {
+							return fmt.Errorf("GraphQL variables must be a JSON object")
+						}
+						gql.Variables = vm
+					}
+					bodySpec.GraphQL = gql
+				}
 				call.Body = bodySpec
 			}
 
@@ -79,9 +113,15 @@ func newCallCmd() *cobra.Command {
 	}
 	createCmd.Flags().String("use-headers", "", "header set to apply")
 	createCmd.Flags().String("desc", "", "short description for the call")
+	createCmd.Flags().StringArray("tag", nil, "tag for selecting the call, e.g. with 'reqo test --tag' (repeatable)")
 	createCmd.Flags().String("json", "", "JSON body or @file to save with the call")
 	createCmd.Flags().String("data", "", "raw body or @file to save with the call")
-	createCmd.Flags().StringToString("form", nil, "multipart form fields to save with the call (k=v, use @file for uploads)")
+	createCmd.Flags().String("data-binary", "", "binary body @file to save with the call (sent unmodified)")
+	createCmd.Flags().StringArray("form", nil, "multipart part to save with the call: name=value or name=@file[;type=...][;filename=...] (repeatable; one part per flag, commas belong to the value)")
+	createCmd.Flags().StringArray("form-urlencoded", nil, "URL-encoded form field k=v to save with the call (repeatable)")
+	createCmd.Flags().String("graphql", "", "GraphQL query or @file.graphql to save with the call")
+	createCmd.Flags().String("variables", "", "GraphQL variables (JSON or @file) to save with the call")
+	createCmd.Flags().String("operation-name", "", "GraphQL operation name to save with the call")
 	cmd.AddCommand(createCmd)
 
 	// call list
@@ -99,20 +139,36 @@ func newCallCmd() *cobra.Command {
 			}
 			fmt.Fprintln(cmd.OutOrStdout(), "Saved Calls:")
 			for alias, call := range p.Project.Calls {
-				fmt.Fprintf(cmd.OutOrStdout(), "  %s: %s %s", alias, call.Method, call.Path)
+				method := call.Method
+				switch call.Type {
+				case project.CallWebSocket:
+					method = "WS"
+				case project.CallGRPC:
+					method = "GRPC"
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "  %s: %s %s", alias, method, call.Path)
 				if call.Description != "" {
 					fmt.Fprintf(cmd.OutOrStdout(), " (%s)", call.Description)
 				}
 				if call.UseHeaderSet != "" {
 					fmt.Fprintf(cmd.OutOrStdout(), " [uses: %s]", call.UseHeaderSet)
 				}
+				if len(call.Tags) > 0 {
+					fmt.Fprintf(cmd.OutOrStdout(), " [tags: %s]", strings.Join(call.Tags, ", "))
+				}
 				if call.Body != nil {
 					if call.Body.JSON != nil {
 						fmt.Fprintf(cmd.OutOrStdout(), " [JSON body]")
 					} else if call.Body.Raw != nil {
 						fmt.Fprintf(cmd.OutOrStdout(), " [raw body]")
+					} else if call.Body.Binary != nil {
+						fmt.Fprintf(cmd.OutOrStdout(), " [binary body]")
 					} else if len(call.Body.Form) > 0 {
 						fmt.Fprintf(cmd.OutOrStdout(), " [form body]")
+					} else if len(call.Body.URLEncoded) > 0 {
+						fmt.Fprintf(cmd.OutOrStdout(), " [urlencoded body]")
+					} else if call.Body.GraphQL != nil {
+						fmt.Fprintf(cmd.OutOrStdout(), " [GraphQL body]")
 					}
 				}
 				fmt.Fprintln(cmd.OutOrStdout())
@@ -142,46 +198,86 @@ func newCallCmd() *cobra.Command {
 	}
 	cmd.AddCommand(rmCmd)
 
-    // call run <alias>
-    runCmd := &cobra.Command{
+	// call export [alias]
+	exportCmd := &cobra.Command{
+		Use:   "export [alias]",
+		Short: "Export saved calls as client code (curl, Go, Python, JS, ...)",
+		Long: `Render the fully resolved request of a saved call as a runnable snippet.
+Supported languages: ` + strings.Join(httpx.ExportLangs(), ", ") + `.
+Use --all to export every saved call of the project into one file: one
+snippet per call under a comment naming it, or for go and js-fetch one
+program with a function per call, run in alias order.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			lang := getString(cmd, "lang")
+			all := getBool(cmd, "all")
+			if all == (len(args) == 1) {
+				return fmt.Errorf("specify either an alias or --all")
+			}
+			p, err := resolveProject(cmd)
+			if err != nil {
+				return err
+			}
+			aliases := args
+			if all {
+				for alias := range p.Project.Calls {
+					aliases = append(aliases, alias)
+				}
+				sort.Strings(aliases)
+			}
+			reqs := make([]*http.Request, len(aliases))
+			for i, alias := range aliases {
+				req, err := buildCallRequest(cmd, p, alias, parseVars(cmd))
+				if err != nil {
+					return err
+				}
+				if getBool(cmd, "redact") {
+					req = httpx.RedactRequest(req)
+				}
+				reqs[i] = req
+			}
+			var code string
+			if all {
+				code, err = httpx.ExportAll(aliases, reqs, lang)
+			} else if code, err = httpx.Export(reqs[0], lang); err != nil {
+				err = fmt.Errorf("%s: %w", aliases[0], err)
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), strings.TrimRight(code, "\n"))
+			return nil
+		},
+	}
+	exportCmd.Flags().String("lang", "curl", "target language ("+strings.Join(httpx.ExportLangs(), "|")+")")
+	exportCmd.Flags().Bool("all", false, "export every saved call")
+	exportCmd.Flags().Bool("redact", false, "mask credentials in the exported code")
+	exportCmd.Flags().String("env", "", "environment to use")
+	exportCmd.Flags().StringArray("var", nil, "variables for template expansion (key=value)")
+	exportCmd.Flags().StringArray("header", nil, "extra header (Key: Value)")
+	exportCmd.Flags().StringArray("query", nil, "extra query param (k=v)")
+	cmd.AddCommand(exportCmd)
+
+	// call run <alias>
+	runCmd := &cobra.Command{
 		Use:     "run <alias>",
 		Aliases: []string{"exec"},
 		Short:   "Execute a saved call with optional overrides",
 		Args:    cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-            alias := args[0]
-            return executeCall(cmd, alias)
+			alias := args[0]
+			return executeCall(cmd, alias)
 		},
 	}
-	runCmd.Flags().StringArray("header", nil, "extra header (Key: Value)")
-	runCmd.Flags().StringArray("query", nil, "extra query param (k=v)")
-	runCmd.Flags().String("env", "", "environment to use")
-	runCmd.Flags().Bool("as-curl", false, "print equivalent curl command and exit")
-	runCmd.Flags().BoolP("include", "i", false, "show response headers")
-	runCmd.Flags().Bool("raw", false, "output raw body")
-	runCmd.Flags().Int("timeout", 30, "request timeout in seconds")
-	runCmd.Flags().Int("retries", 0, "number of retries on failure")
-	runCmd.Flags().StringArray("var", nil, "variables for template expansion (key=value)")
-	// data flags
-	runCmd.Flags().String("json", "", "JSON body or @file")
-	runCmd.Flags().String("data", "", "raw body or @file")
-	runCmd.Flags().StringToString("form", nil, "multipart form fields (k=v, use @file for uploads)")
+	addRequestFlags(runCmd)
+	addDataFileFlags(runCmd)
 	cmd.AddCommand(runCmd)
+	cmd.AddCommand(newRunManyCmd())
 
-    // Also add run-related flags to the parent command to support shorthand: `reqo call <alias> [flags]`
-    // These are duplicated so that Cobra can parse them at the parent level.
-    cmd.Flags().StringArray("header", nil, "extra header (Key: Value)")
-    cmd.Flags().StringArray("query", nil, "extra query param (k=v)")
-    cmd.Flags().String("env", "", "environment to use")
-    cmd.Flags().Bool("as-curl", false, "print equivalent curl command and exit")
-    cmd.Flags().BoolP("include", "i", false, "show response headers")
-    cmd.Flags().Bool("raw", false, "output raw body")
-    cmd.Flags().Int("timeout", 30, "request timeout in seconds")
-    cmd.Flags().Int("retries", 0, "number of retries on failure")
-    cmd.Flags().StringArray("var", nil, "variables for template expansion (key=value)")
-    cmd.Flags().String("json", "", "JSON body or @file")
-    cmd.Flags().String("data", "", "raw body or @file")
-    cmd.Flags().StringToString("form", nil, "multipart form fields (k=v, use @file for uploads)")
+	// Also add run-related flags to the parent command to support shorthand: `reqo call <alias> [flags]`
+	// These are duplicated so that Cobra can parse them at the parent level.
+	addRequestFlags(cmd)
+	addDataFileFlags(cmd)
 
 	return cmd
 }
@@ -190,95 +286,156 @@ func newCallCmd() *cobra.Command {
 // the `call run` subcommand and the parent `call` command when invoked as
 // `reqo call <alias>`.
 func executeCall(cmd *cobra.Command, alias string) error {
-    pCtx, err := resolveProject(cmd)
-    if err != nil {
-        return err
-    }
-    callDef, ok := pCtx.Project.Calls[alias]
-    if !ok {
-        return fmt.Errorf("call %q not defined in project %s", alias, pCtx.Project.Name)
-    }
+	pCtx, err := resolveProject(cmd)
+	if err != nil {
+		return err
+	}
+	switch pCtx.Project.Calls[alias].Type {
+	case project.CallWebSocket:
+		return fmt.Errorf("call %q is a WebSocket call; run it with 'reqo ws %s'", alias, alias)
+	case project.CallGRPC:
+		return fmt.Errorf("call %q is a gRPC call; run it with 'reqo grpc %s'", alias, alias)
+	}
+	if getString(cmd, "data-file") != "" {
+		return runDataFile(cmd, pCtx, alias)
+	}
+	req, err := buildCallRequest(cmd, pCtx, alias, parseVars(cmd))
+	if err != nil {
+		return err
+	}
 
-    vars := map[string]string{}
-    for _, v := range getStringArray(cmd, "var") {
-        kv := strings.SplitN(v, "=", 2)
-        if len(kv) == 2 {
-            vars[kv[0]] = kv[1]
-        }
-    }
+	return sendRequest(cmd, pCtx, req, alias)
+}
 
-    // derive environment name: flag > REQO_ENV > project default (handled in BuildRequest)
-    envName := getString(cmd, "env")
-    if envName == "" {
-        envName = os.Getenv("REQO_ENV")
-    }
+// hasBodyFlag reports whether a request body was given as a flag.
+func hasBodyFlag(cmd *cobra.Command) bool {
+	for _, name := range []string{"json", "data", "data-binary", "graphql"} {
+		if getString(cmd, name) != "" {
+			return true
+		}
+	}
+	return len(getStringArray(cmd, "form")) > 0 || len(getStringArray(cmd, "form-urlencoded")) > 0
+}
+
+// buildCallRequest resolves a saved call into a request with the template
+// vars, applying the overrides (env, headers, query, body) given as flags
+// on cmd.
+func buildCallRequest(cmd *cobra.Command, pCtx *projContext, alias string, vars map[string]string) (*http.Request, error) {
+	callDef, ok := pCtx.Project.Calls[alias]
+	if !ok {
+		return nil, fmt.Errorf("call %q not defined in project %s", alias, pCtx.Project.Name)
+	}
 
-    spec := httpx.RequestSpec{
-        Method:       callDef.Method,
-        Path:         callDef.Path,
-        QueryParams:  getStringArray(cmd, "query"),
-        Headers:      getStringArray(cmd, "header"),
-        UseHeaderSet: callDef.UseHeaderSet,
-        Vars:         vars,
-        EnvName:      envName,
-    }
+	envName := envFlag(cmd)
+
+	spec := httpx.RequestSpec{
+		Method:       callDef.Method,
+		Path:         callDef.Path,
+		QueryParams:  getStringArray(cmd, "query"),
+		Headers:      getStringArray(cmd, "header"),
+		UseHeaderSet: callDef.UseHeaderSet,
+		Vars:         vars,
+		EnvName:      envName,
+	}
+	// only `reqo ws --save` keeps headers and query params with a call
+	if callDef.Type == project.CallWebSocket {
+		spec.QueryParams = savedQuery(callDef.Query, spec.QueryParams)
+		spec.Headers = savedHeaders(callDef.Headers, spec.Headers)
+	}
 
-    if jsonBody := getString(cmd, "json"); jsonBody != "" {
-        spec.JSONBody = &jsonBody
-    } else if callDef.Body != nil && callDef.Body.JSON != nil {
-        expandedJSON := template.Expand(*callDef.Body.JSON, vars)
-        spec.JSONBody = &expandedJSON
-    }
+	// a body given on the command line replaces the saved one entirely
+	saved := callDef.Body
+	if hasBodyFlag(cmd) {
+		saved = nil
+	}
 
-    if rawBody := getString(cmd, "data"); rawBody != "" {
-        spec.RawBody = &rawBody
-    } else if callDef.Body != nil && callDef.Body.Raw != nil {
-        expandedRaw := template.Expand(*callDef.Body.Raw, vars)
-        spec.RawBody = &expandedRaw
-    }
+	if jsonBody := getString(cmd, "json"); jsonBody != "" {
+		spec.JSONBody = &jsonBody
+	} else if saved != nil && saved.JSON != nil {
+		expandedJSON := template.Expand(*saved.JSON, vars)
+		spec.JSONBody = &expandedJSON
+	}
 
-    if formMap := getStringToString(cmd, "form"); len(formMap) > 0 {
-        spec.FormFields = formMap
-    } else if callDef.Body != nil && len(callDef.Body.Form) > 0 {
-        expandedForm := make(map[string]string)
-        for k, v := range callDef.Body.Form {
-            expandedForm[k] = template.Expand(v, vars)
-        }
-        spec.FormFields = expandedForm
-    }
+	if rawBody := getString(cmd, "data"); rawBody != "" {
+		spec.RawBody = &rawBody
+	} else if saved != nil && saved.Raw != nil {
+		expandedRaw := template.Expand(*saved.Raw, vars)
+		spec.RawBody = &expandedRaw
+	}
 
-    req, err := httpx.BuildRequest(pCtx.Project, spec)
-    if err != nil {
-        return err
-    }
+	if binBody := getString(cmd, "data-binary"); binBody != "" {
+		spec.BinaryBody = &binBody
+	} else if saved != nil && saved.Binary != nil {
+		// only the path is expanded, never the content
+		binBody := *saved.Binary
+		if strings.HasPrefix(binBody, "@") {
+			binBody = template.Expand(binBody, vars)
+		}
+		spec.BinaryBody = &binBody
+	}
 
-    if getBool(cmd, "as-curl") {
-        curlCmd, _ := httpx.AsCurl(req)
-        fmt.Fprintln(cmd.OutOrStdout(), curlCmd)
-        return nil
-    }
+	form, err := formFlag(cmd)
+	if err != nil {
+		return nil, err
+	}
+	if len(form) > 0 {
+		spec.Form = form
+	} else if saved != nil {
+		spec.Form = saved.Form // expanded by BuildRequest
+	}
 
-    ctx, cancel := context.WithTimeout(context.Background(), time.Duration(getInt(cmd, "timeout"))*time.Second)
-    defer cancel()
+	if fields := getStringArray(cmd, "form-urlencoded"); len(fields) > 0 {
+		spec.URLEncoded = fields
+	} else if saved != nil {
+		spec.URLEncoded = saved.URLEncoded // expanded by BuildRequest
+	}
 
-    execOpts := httpx.ExecOpts{
-        Timeout:      time.Duration(getInt(cmd, "timeout")) * time.Second,
-        Retries:      getInt(cmd, "retries"),
-        Backoff:      200 * time.Millisecond,
-        MaxRedirects: 10,
-    }
-    resp, err := httpx.Execute(ctx, nil, req, execOpts)
-    if err != nil {
-        return fmt.Errorf("request failed: %w", err)
-    }
-    defer resp.Body.Close()
+	var savedGQL *project.GraphQLSpec
+	if saved != nil {
+		savedGQL = saved.GraphQL
+	}
+	gql, err := graphQLFromFlags(cmd, savedGQL)
+	if err != nil {
+		return nil, err
+	}
+	spec.GraphQL = gql
 
-    renderOpts := output.RenderOpts{
-        ShowHeaders: getBool(cmd, "include"),
-        RawOutput:   getBool(cmd, "raw"),
-        JQExpr:      "",
-    }
-    return output.Render(resp, cmd.OutOrStdout(), renderOpts)
+	return httpx.BuildRequest(pCtx.Project, spec)
 }
 
+// savedHeaders puts the headers saved with a call before the --header
+// flags, leaving out saved ones a flag sets again.
+func savedHeaders(saved, flags []string) []string {
+	set := map[string]bool{}
+	for _, h := range flags {
+		k, _, _ := strings.Cut(h, ":")
+		set[http.CanonicalHeaderKey(strings.TrimSpace(k))] = true
+	}
+	var out []string
+	for _, h := range saved {
+		k, _, _ := strings.Cut(h, ":")
+		if !set[http.CanonicalHeaderKey(strings.TrimSpace(k))] {
+			out = append(out, h)
+		}
+	}
+	return append(out, flags...)
+}
 
+// savedQuery turns the query params saved with a call into k=v pairs,
+// sorted, followed by the --query flags; a flag replaces a saved param of
+// the same name.
+func savedQuery(saved map[string]string, flags []string) []string {
+	set := map[string]bool{}
+	for _, q := range flags {
+		k, _, _ := strings.Cut(q, "=")
+		set[k] = true
+	}
+	var out []string
+	for k, v := range saved {
+		if !set[k] {
+			out = append(out, k+"="+v)
+		}
+	}
+	sort.Strings(out)
+	return append(out, flags...)
+}
@@ -0,0 +1,251 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/suprbdev/reqo/internal/dataset"
+	"github.com/suprbdev/reqo/internal/output"
+)
+
+// addDataFileFlags registers the flags of data-driven `call run`.
+func addDataFileFlags(cmd *cobra.Command) {
+	cmd.Flags().String("data-file", "", "run the call once per row of a .csv, .json or .jsonl file; columns become template vars")
+	cmd.Flags().Int("parallel", 1, "number of --data-file rows run at the same time")
+	cmd.Flags().StringArray("capture", nil, "with --data-file, record name=<jq expression> from each JSON response (repeatable)")
+}
+
+// rowResult is the outcome of one data-file row, written as a JSONL
+// record with -o jsonl.
+type rowResult struct {
+	Row        int                    `json:"row"` // 1-based, header excluded
+	Status     int                    `json:"status,omitempty"`
+	DurationMS int64                  `json:"duration_ms"`
+	Captures   map[string]interface{} `json:"captures,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+}
+
+func (r rowResult) failed() bool { return r.Error != "" || r.Status >= 400 }
+
+// runDataFile runs a saved call once per row of --data-file and prints a
+// line (or a JSONL record) per row in row order, then a summary of the
+// statuses.
+func runDataFile(cmd *cobra.Command, pCtx *projContext, alias string) error {
+	if _, ok := pCtx.Project.Calls[alias]; !ok {
+		return fmt.Errorf("call %q not defined in project %s", alias, pCtx.Project.Name)
+	}
+	for _, name := range []string{"as-curl", "output-file", "remote-name", "sse"} {
+		if cmd.Flags().Changed(name) {
+			return fmt.Errorf("--data-file cannot be combined with --%s", name)
+		}
+	}
+	jsonl := false
+	switch f := getString(cmd, "output"); f {
+	case "":
+	case "jsonl":
+		jsonl = true
+	default:
+		return fmt.Errorf("--data-file supports -o jsonl only, not %q", f)
+	}
+	captures, err := parseCaptures(getStringArray(cmd, "capture"))
+	if err != nil {
+		return err
+	}
+	parallel := getInt(cmd, "parallel")
+	if parallel < 1 {
+		return fmt.Errorf("--parallel must be at least 1")
+	}
+	rows, err := dataset.Load(getString(cmd, "data-file"))
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return fmt.Errorf("data file %s has no rows", getString(cmd, "data-file"))
+	}
+	cmd.SilenceUsage = true // from here on a failing row is reported, the flags were fine
+
+	out, summary := cmd.OutOrStdout(), cmd.OutOrStdout()
+	if jsonl {
+		summary = cmd.ErrOrStderr() // keep standard output parseable
+	}
+	color := output.ColorEnabled(summary, getBool(cmd, "no-color"))
+	base := parseVars(cmd)
+
+	start := time.Now()
+	results := make([]rowResult, len(rows))
+	// print in row order as soon as the rows before have finished
+	finished := make([]bool, len(rows))
+	next := 0
+	runParallel(parallel, len(rows), func(i int) {
+		vars := make(map[string]string, len(base)+len(rows[i]))
+		for k, v := range base {
+			vars[k] = v
+		}
+		for k, v := range rows[i] {
+			vars[k] = v
+		}
+		results[i] = runRow(cmd, pCtx, alias, i+1, vars, captures)
+	}, func(i int) {
+		finished[i] = true
+		for ; next < len(rows) && finished[next]; next++ {
+			writeRowResult(out, results[next], jsonl, color)
+		}
+	})
+
+	failed := writeRowSummary(summary, results, time.Since(start))
+	if failed > 0 {
+		return fmt.Errorf("%d of %s failed", failed, plural(len(rows), "row"))
+	}
+	return nil
+}
+
+// parseCaptures parses --capture name=expr flags.
+func parseCaptures(flags []string) (map[string]string, error) {
+	captures := map[string]string{}
+	for _, f := range flags {
+		name, expr, ok := strings.Cut(f, "=")
+		if !ok || name == "" || expr == "" {
+			return nil, fmt.Errorf("invalid --capture %q (want name=<jq expression>)", f)
+		}
+		captures[name] = expr
+	}
+	return captures, nil
+}
+
+// runRow sends the call for one row and evaluates the captures on its
+// JSON response.
+func runRow(cmd *cobra.Command, pCtx *projContext, alias string, n int, vars, captures map[string]string) rowResult {
+	res := rowResult{Row: n}
+	run := runLoggedCall(cmd, pCtx, alias, vars)
+	res.Status, res.DurationMS = run.Status, run.Duration.Milliseconds()
+	if run.Err != nil {
+		res.Error = run.Err.Error()
+		return res
+	}
+	if len(captures) == 0 {
+		return res
+	}
+	var doc interface{}
+	if err := output.DecodeJSON(run.Body, &doc); err != nil {
+		res.Error = "capture: response is not JSON"
+		return res
+	}
+	res.Captures = map[string]interface{}{}
+	for name, expr := range captures {
+		vals, err := output.RunJQ(expr, doc)
+		if err != nil {
+			res.Error = fmt.Sprintf("capture %s: %v", name, err)
+			return res
+		}
+		var v interface{}
+		if len(vals) > 0 {
+			v = vals[0]
+		}
+		res.Captures[name] = v
+	}
+	return res
+}
+
+func writeRowResult(out io.Writer, r rowResult, jsonl, color bool) {
+	if jsonl {
+		data, _ := json.Marshal(r)
+		fmt.Fprintln(out, string(data))
+		return
+	}
+	text := fmt.Sprintf("row %d", r.Row)
+	if r.Error != "" {
+		text += " – " + r.Error
+	} else {
+		text += fmt.Sprintf(" → %d (%dms)", r.Status, r.DurationMS)
+		if len(r.Captures) > 0 {
+			data, _ := json.Marshal(r.Captures)
+			text += " " + string(data)
+		}
+	}
+	output.WriteCheck(out, 0, !r.failed(), text, color)
+}
+
+// writeRowSummary prints how many rows got each status and returns the
+// number of failed rows (request errors and 4xx/5xx statuses).
+func writeRowSummary(out io.Writer, results []rowResult, took time.Duration) int {
+	statuses := make([]int, len(results))
+	failed := 0
+	for i, r := range results {
+		statuses[i] = r.Status // 0 when no response arrived
+		if r.failed() {
+			failed++
+		}
+	}
+	writeStatusSummary(out, "row", statuses, failed, took)
+	return failed
+}
+
+// runParallel calls work for 0…n-1 with at most parallel calls at a time
+// and done, on the calling goroutine, as each one finishes.
+func runParallel(parallel, n int, work func(i int), done func(i int)) {
+	finished := make(chan int)
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(parallel, n); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				work(i)
+				finished <- i
+			}
+		}()
+	}
+	go func() {
+		for i := 0; i < n; i++ {
+			jobs <- i
+		}
+		close(jobs)
+		wg.Wait()
+		close(finished)
+	}()
+	for i := range finished {
+		done(i)
+	}
+}
+
+// writeStatusSummary prints a count per status, e.g. "3 rows in 1.2s:
+// 2 × 200 OK, 1 × error (1 failed)"; status 0 stands for a request error.
+func writeStatusSummary(out io.Writer, noun string, statuses []int, failed int, took time.Duration) {
+	counts := map[int]int{}
+	for _, s := range statuses {
+		counts[s]++
+	}
+	codes := make([]int, 0, len(counts))
+	for c := range counts {
+		if c != 0 {
+			codes = append(codes, c)
+		}
+	}
+	sort.Ints(codes)
+	var parts []string
+	for _, c := range codes {
+		parts = append(parts, fmt.Sprintf("%d × %s", counts[c], statusLabel(c)))
+	}
+	if counts[0] > 0 {
+		parts = append(parts, fmt.Sprintf("%d × error", counts[0]))
+	}
+	fmt.Fprintf(out, "\n%s in %s: %s (%d failed)\n",
+		plural(len(statuses), noun), took.Round(time.Millisecond), strings.Join(parts, ", "), failed)
+}
+
+// statusLabel is a status code with its reason phrase, e.g. "404 Not Found".
+func statusLabel(code int) string {
+	if text := http.StatusText(code); text != "" {
+		return strconv.Itoa(code) + " " + text
+	}
+	return strconv.Itoa(code)
+}
@@ -0,0 +1,271 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/suprbdev/reqo/internal/assert"
+	"github.com/suprbdev/reqo/internal/output"
+	"github.com/suprbdev/reqo/internal/project"
+	"github.com/suprbdev/reqo/internal/template"
+)
+
+// newFlowCmd lists and runs the flows of a project.
+func newFlowCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "flow",
+		Short: "Run saved calls in sequence, passing captured values along",
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the flows of the project",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			p, err := resolveProject(cmd)
+			if err != nil {
+				return err
+			}
+			if len(p.Project.Flows) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "No flows found.")
+				return nil
+			}
+			names := make([]string, 0, len(p.Project.Flows))
+			for name := range p.Project.Flows {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			fmt.Fprintln(cmd.OutOrStdout(), "Flows:")
+			for _, name := range names {
+				f := p.Project.Flows[name]
+				calls := make([]string, len(f.Steps))
+				for i, s := range f.Steps {
+					calls[i] = s.Label()
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "  %s: %s", name, strings.Join(calls, " → "))
+				if f.Description != "" {
+					fmt.Fprintf(cmd.OutOrStdout(), " (%s)", f.Description)
+				}
+				fmt.Fprintln(cmd.OutOrStdout())
+			}
+			return nil
+		},
+	}
+	cmd.AddCommand(listCmd)
+
+	runCmd := &cobra.Command{
+		Use:   "run <name>",
+		Short: "Run the steps of a flow, stopping at the first failure",
+		Long: `Run the steps listed under "flows:" in project.yaml one after another.
+Each step sends a saved call with its own var overrides, checks the call's
+and the step's assertions and captures values from the JSON response with
+jq; captured values are template vars of the later steps. A step whose
+skip_if expression (jq on the current vars) is true is skipped. The flow
+stops at the first failed step and ends with a summary of every step.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runFlow,
+	}
+	runCmd.Flags().String("env", "", "environment to use")
+	runCmd.Flags().StringArray("var", nil, "variables for template expansion (key=value)")
+	runCmd.Flags().StringArray("header", nil, "extra header (Key: Value)")
+	runCmd.Flags().Int("timeout", 30, "request timeout in seconds (per step)")
+	runCmd.Flags().BoolP("insecure", "k", false, "skip TLS certificate verification")
+	cmd.AddCommand(runCmd)
+	return cmd
+}
+
+// stepResult is the outcome of one flow step for the summary.
+type stepResult struct {
+	Label   string
+	Skipped bool
+	Run     *callRun // nil when skipped or not run
+}
+
+func runFlow(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	p, err := resolveProject(cmd)
+	if err != nil {
+		return err
+	}
+	flow, ok := p.Project.Flows[name]
+	if !ok {
+		return fmt.Errorf("flow %q not defined in project %s", name, p.Project.Name)
+	}
+	if len(flow.Steps) == 0 {
+		return fmt.Errorf("flow %q has no steps", name)
+	}
+	for i, step := range flow.Steps {
+		if _, ok := p.Project.Calls[step.Call]; !ok {
+			return fmt.Errorf("step %d of flow %s: call %q not defined in project %s", i+1, name, step.Call, p.Project.Name)
+		}
+	}
+	cmd.SilenceUsage = true // the flow is valid; a failing step is not a usage error
+
+	out := cmd.OutOrStdout()
+	color := output.ColorEnabled(out, getBool(cmd, "no-color"))
+	vars := parseVars(cmd)
+	results := make([]stepResult, 0, len(flow.Steps))
+	failedAt := -1
+	for i, step := range flow.Steps {
+		res := stepResult{Label: step.Label()}
+		if step.SkipIf != "" {
+			skip, err := truthy(step.SkipIf, vars)
+			if err != nil {
+				res.Run = &callRun{Alias: res.Label, Err: fmt.Errorf("skip_if: %w", err)}
+			} else if skip {
+				res.Skipped = true
+				output.WriteSkipped(out, 0, res.Label+" – skipped ("+step.SkipIf+")", color)
+				results = append(results, res)
+				continue
+			}
+		}
+		if res.Run == nil {
+			res.Run = runStep(cmd, p, step, vars)
+		}
+		writeCallRun(out, res.Run, color)
+		results = append(results, res)
+		if res.Run.failed() {
+			failedAt = i
+			break
+		}
+	}
+
+	writeFlowSummary(out, name, flow, results, color)
+	if failedAt >= 0 {
+		return fmt.Errorf("flow %s failed at step %d of %d (%s)", name, failedAt+1, len(flow.Steps), flow.Steps[failedAt].Label())
+	}
+	return nil
+}
+
+// runStep sends the call of a step and checks its assertions. Captured
+// values are added to vars when every check passes.
+func runStep(cmd *cobra.Command, p *projContext, step project.FlowStep, vars map[string]string) *callRun {
+	stepVars := make(map[string]string, len(vars)+len(step.Vars))
+	for k, v := range vars {
+		stepVars[k] = v
+	}
+	for k, v := range step.Vars {
+		stepVars[k] = template.Expand(v, vars)
+	}
+
+	run := runCall(cmd, p, step.Call, stepVars)
+	run.Alias = step.Label()
+	if run.Err != nil {
+		return run
+	}
+	checks := append(append([]project.Assertion{}, p.Project.Calls[step.Call].Assert...), step.Assert...)
+	run.Results = assert.Check(assert.Response{
+		StatusCode: run.Status,
+		Header:     run.Header,
+		Body:       run.Body,
+		Duration:   run.Duration,
+		Dir:        p.Dir,
+	}, assert.Expand(checks, stepVars))
+
+	if len(step.Capture) == 0 {
+		return run
+	}
+	var doc interface{}
+	bodyErr := output.DecodeJSON(run.Body, &doc)
+	names := make([]string, 0, len(step.Capture))
+	for k := range step.Capture {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	captured := map[string]string{}
+	for _, k := range names {
+		expr := step.Capture[k]
+		r := assert.Result{Name: "capture " + k + " = " + expr, Passed: true}
+		var err error
+		if bodyErr != nil {
+			err = fmt.Errorf("response is not JSON")
+		} else {
+			captured[k], err = capture(expr, doc)
+		}
+		if err != nil {
+			r.Passed, r.Message = false, err.Error()
+		}
+		run.Results = append(run.Results, r)
+	}
+	if !run.failed() {
+		for k, v := range captured {
+			vars[k] = v
+		}
+	}
+	return run
+}
+
+// capture evaluates a jq expression on a response; strings are taken as
+// they are and other values as JSON.
+func capture(expr string, doc interface{}) (string, error) {
+	vals, err := output.RunJQ(expr, doc)
+	if err != nil {
+		return "", err
+	}
+	if len(vals) == 0 || vals[0] == nil {
+		return "", fmt.Errorf("no value")
+	}
+	if s, ok := vals[0].(string); ok {
+		return s, nil
+	}
+	data, err := json.Marshal(vals[0])
+	return string(data), err
+}
+
+// truthy evaluates a skip_if jq expression against the vars; any result
+// other than false or null counts as true.
+func truthy(expr string, vars map[string]string) (bool, error) {
+	in := make(map[string]interface{}, len(vars))
+	for k, v := range vars {
+		in[k] = v
+	}
+	vals, err := output.RunJQ(expr, in)
+	if err != nil {
+		return false, err
+	}
+	return len(vals) > 0 && vals[0] != nil && vals[0] != false, nil
+}
+
+// writeFlowSummary prints one line per step, including those not run.
+func writeFlowSummary(out io.Writer, name string, flow project.Flow, results []stepResult, color bool) {
+	width := 0
+	for _, s := range flow.Steps {
+		width = max(width, len(s.Label()))
+	}
+	fmt.Fprintf(out, "\nFlow %s:\n", name)
+	var passed, failed, skipped int
+	for i, s := range flow.Steps {
+		label := fmt.Sprintf("%d. %-*s", i+1, width, s.Label())
+		if i >= len(results) {
+			output.WriteSkipped(out, 1, label+"  not run", color)
+			continue
+		}
+		r := results[i]
+		switch {
+		case r.Skipped:
+			skipped++
+			output.WriteSkipped(out, 1, label+"  skipped", color)
+		case r.Run.Err != nil:
+			failed++
+			output.WriteCheck(out, 1, false, label+"  "+r.Run.Err.Error(), color)
+		default:
+			ok := !r.Run.failed()
+			if ok {
+				passed++
+			} else {
+				failed++
+			}
+			text := fmt.Sprintf("%s  %d  %s", label, r.Run.Status, r.Run.Duration.Round(time.Millisecond))
+			if n := assert.Failed(r.Run.Results); n > 0 {
+				text += "  " + plural(n, "failed check")
+			}
+			output.WriteCheck(out, 1, ok, text, color)
+		}
+	}
+	fmt.Fprintf(out, "%s: %d passed, %d failed, %d skipped, %d not run\n",
+		plural(len(flow.Steps), "step"), passed, failed, skipped, len(flow.Steps)-len(results))
+}
@@ -0,0 +1,125 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	httpx "github.com/suprbdev/reqo/internal/http"
+)
+
+func newGraphQLCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "graphql",
+		Short: "GraphQL helpers",
+	}
+
+	introspectCmd := &cobra.Command{
+		Use:   "introspect [path|alias]",
+		Short: "Fetch the schema of a GraphQL endpoint and save it as JSON",
+		Long: `Send the standard introspection query to a GraphQL endpoint and save the
+result. The endpoint is a path resolved against the environment base URL
+(default "@", the base URL itself) or a saved call, whose path and header set
+are reused.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: runIntrospect,
+	}
+	introspectCmd.Flags().String("out", "schema.json", "file to write the schema to (- for stdout)")
+	introspectCmd.Flags().String("env", "", "environment to use")
+	introspectCmd.Flags().StringArray("header", nil, "extra header (Key: Value)")
+	introspectCmd.Flags().String("use-headers", "", "header set to apply")
+	introspectCmd.Flags().StringArray("var", nil, "variables for template expansion (key=value)")
+	introspectCmd.Flags().Int("timeout", 30, "request timeout in seconds")
+	introspectCmd.Flags().BoolP("insecure", "k", false, "skip TLS certificate verification")
+	cmd.AddCommand(introspectCmd)
+
+	return cmd
+}
+
+func runIntrospect(cmd *cobra.Command, args []string) error {
+	pCtx, err := resolveProject(cmd)
+	if err != nil {
+		return err
+	}
+	path, headerSet := "@", getString(cmd, "use-headers")
+	if len(args) == 1 {
+		path = args[0]
+		if call, ok := pCtx.Project.Calls[path]; ok {
+			path = call.Path
+			if headerSet == "" {
+				headerSet = call.UseHeaderSet
+			}
+		}
+	}
+
+	req, err := httpx.BuildRequest(pCtx.Project, httpx.RequestSpec{
+		Path:         path,
+		Headers:      getStringArray(cmd, "header"),
+		UseHeaderSet: headerSet,
+		GraphQL:      &httpx.GraphQLBody{Query: httpx.IntrospectionQuery, OperationName: "IntrospectionQuery"},
+		Vars:         parseVars(cmd),
+		EnvName:      envFlag(cmd),
+	})
+	if err != nil {
+		return err
+	}
+
+	timeout := time.Duration(getInt(cmd, "timeout")) * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	resp, err := httpx.Execute(ctx, nil, req, httpx.ExecOpts{
+		Timeout:      timeout,
+		Backoff:      200 * time.Millisecond,
+		MaxRedirects: 10,
+		Insecure:     getBool(cmd, "insecure"),
+	})
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	var result struct {
+		Data struct {
+			Schema *struct {
+				Types []json.RawMessage `json:"types"`
+			} `json:"__schema"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("introspection failed (%s): response is not JSON", resp.Status)
+	}
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("introspection failed: %s", result.Errors[0].Message)
+	}
+	if result.Data.Schema == nil {
+		return fmt.Errorf("introspection failed (%s): no __schema in response", resp.Status)
+	}
+
+	var pretty bytes.Buffer
+	if err := json.Indent(&pretty, body, "", "  "); err != nil {
+		return err
+	}
+	pretty.WriteByte('\n')
+	out := getString(cmd, "out")
+	if out == "-" {
+		_, err = cmd.OutOrStdout().Write(pretty.Bytes())
+		return err
+	}
+	if err := os.WriteFile(out, pretty.Bytes(), 0o644); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Saved schema (%d types) to %s\n", len(result.Data.Schema.Types), out)
+	return nil
+}
@@ -0,0 +1,400 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	grpcx "github.com/suprbdev/reqo/internal/grpc"
+	httpx "github.com/suprbdev/reqo/internal/http"
+	"github.com/suprbdev/reqo/internal/output"
+	"github.com/suprbdev/reqo/internal/project"
+	"github.com/suprbdev/reqo/internal/template"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func newGRPCCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "grpc <package.Service/Method|alias>",
+		Short: "Call a gRPC or gRPC-Web method with a JSON request",
+		Long: `Invoke a unary or server-streaming gRPC method. The request is given as
+JSON and each response message is printed as JSON.
+
+Descriptors come from server reflection unless --proto or --protoset is
+given (gRPC-Web always needs one of them). The target is --addr, else the
+environment's grpc_address, else its base_url; https and host:port use TLS,
+http is plaintext. Environment headers, header sets and --header values are
+sent as metadata.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runGRPC,
+	}
+	pf := cmd.PersistentFlags()
+	pf.StringArray("proto", nil, ".proto file to load descriptors from (repeatable)")
+	pf.StringArrayP("import-path", "I", nil, "import path for --proto (repeatable)")
+	pf.String("protoset", "", "FileDescriptorSet file to load descriptors from")
+	pf.Bool("web", false, "use gRPC-Web over HTTP instead of native gRPC")
+	pf.Bool("plaintext", false, "connect without TLS")
+	pf.String("addr", "", "target address (host:port or http(s)://host[:port])")
+	pf.StringArray("header", nil, "extra metadata (Key: Value)")
+	pf.String("use-headers", "", "header set to send as metadata")
+	pf.String("env", "", "environment to use")
+	pf.StringArray("var", nil, "variables for template expansion (key=value)")
+	pf.Int("timeout", 30, "call timeout in seconds")
+	pf.BoolP("insecure", "k", false, "skip TLS certificate verification")
+
+	cmd.Flags().String("json", "", "request message as JSON or @file")
+	cmd.Flags().String("jq", "", "jq expression applied to each response message")
+	cmd.Flags().StringP("output", "o", "", "output format ("+strings.Join(output.Formats, "|")+")")
+	cmd.Flags().BoolP("include", "i", false, "show response headers and trailers")
+	cmd.Flags().String("save", "", "save as a gRPC call under this alias instead of calling")
+	cmd.Flags().String("desc", "", "description for --save")
+
+	listCmd := &cobra.Command{
+		Use:   "list [service]",
+		Short: "List services, or the methods of a service",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  runGRPCList,
+	}
+	cmd.AddCommand(listCmd)
+
+	return cmd
+}
+
+// grpcTarget is a resolved gRPC endpoint: descriptor source settings, the
+// base request carrying address and metadata, and the native target.
+type grpcTarget struct {
+	spec project.GRPCSpec
+	base *http.Request
+	addr string // --addr or the environment's grpc_address/base_url
+}
+
+func runGRPC(cmd *cobra.Command, args []string) error {
+	pCtx, err := resolveProject(cmd)
+	if err != nil {
+		return err
+	}
+	if alias := getString(cmd, "save"); alias != "" {
+		return saveGRPCCall(cmd, pCtx, alias, args[0])
+	}
+
+	method, headerSet, input := args[0], getString(cmd, "use-headers"), getString(cmd, "json")
+	spec := grpcSpecFromFlags(cmd, nil)
+	if call, ok := pCtx.Project.Calls[args[0]]; ok {
+		if call.Type != project.CallGRPC {
+			return fmt.Errorf("call %q is not a gRPC call", args[0])
+		}
+		method = call.Path
+		spec = grpcSpecFromFlags(cmd, call.GRPC)
+		if headerSet == "" {
+			headerSet = call.UseHeaderSet
+		}
+		if input == "" && call.Body != nil && call.Body.JSON != nil {
+			input = *call.Body.JSON
+		}
+	}
+	vars := parseVars(cmd)
+	if input, err = httpx.ReadPossiblyFile(input); err != nil {
+		return err
+	}
+	input = template.Expand(input, vars)
+
+	t, err := resolveGRPCTarget(cmd, pCtx, spec, headerSet, vars)
+	if err != nil {
+		return err
+	}
+
+	timeout := time.Duration(getInt(cmd, "timeout")) * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	out := cmd.OutOrStdout()
+	renderOpts := output.RenderOpts{
+		JQExpr: getString(cmd, "jq"),
+		Format: getString(cmd, "output"),
+		Color:  output.ColorEnabled(out, getBool(cmd, "no-color")),
+	}
+	if !output.ValidFormat(renderOpts.Format) {
+		return fmt.Errorf("unknown output format %q (supported: %s)", renderOpts.Format, strings.Join(output.Formats, ", "))
+	}
+	// with -i the messages are printed between headers and trailers
+	var body bytes.Buffer
+	dst := out
+	if getBool(cmd, "include") {
+		dst = &body
+	}
+	emit := func(msg []byte) error { return output.RenderJSON(msg, dst, renderOpts) }
+
+	var res grpcx.Result
+	if t.spec.Web {
+		res, err = callGRPCWeb(ctx, cmd, t, method, input, emit)
+	} else {
+		res, err = callGRPC(ctx, cmd, t, method, input, emit)
+	}
+	if res.Header != nil || body.Len() > 0 {
+		writeGRPCMetadata(out, res.Header, &body, res.Trailer, getBool(cmd, "include"))
+	}
+	return grpcStatusError(err)
+}
+
+func callGRPC(ctx context.Context, cmd *cobra.Command, t *grpcTarget, method, input string, emit func([]byte) error) (grpcx.Result, error) {
+	conn, err := dialGRPC(cmd, t)
+	if err != nil {
+		return grpcx.Result{}, err
+	}
+	defer conn.Close()
+	meta := grpcMetadata(t.base.Header)
+	src, closeSrc, err := grpcSource(metadata.NewOutgoingContext(ctx, meta), t.spec, conn)
+	if err != nil {
+		return grpcx.Result{}, err
+	}
+	defer closeSrc()
+	md, err := grpcx.FindMethod(src, method)
+	if err != nil {
+		return grpcx.Result{}, err
+	}
+	return grpcx.Invoke(ctx, conn, md, []byte(input), meta, emit)
+}
+
+func callGRPCWeb(ctx context.Context, cmd *cobra.Command, t *grpcTarget, method, input string, emit func([]byte) error) (grpcx.Result, error) {
+	src, err := fileGRPCSource(t.spec)
+	if err != nil {
+		return grpcx.Result{}, err
+	}
+	md, err := grpcx.FindMethod(src, method)
+	if err != nil {
+		return grpcx.Result{}, err
+	}
+	return grpcx.InvokeWeb(ctx, t.base, md, []byte(input), httpx.ExecOpts{
+		Timeout:      time.Duration(getInt(cmd, "timeout")) * time.Second,
+		MaxRedirects: 10,
+		Insecure:     getBool(cmd, "insecure"),
+	}, emit)
+}
+
+func runGRPCList(cmd *cobra.Command, args []string) error {
+	pCtx, err := resolveProject(cmd)
+	if err != nil {
+		return err
+	}
+	spec := grpcSpecFromFlags(cmd, nil)
+	t, err := resolveGRPCTarget(cmd, pCtx, spec, getString(cmd, "use-headers"), parseVars(cmd))
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(getInt(cmd, "timeout"))*time.Second)
+	defer cancel()
+
+	var src grpcx.Source
+	if spec.Web || len(spec.Protos) > 0 || spec.Protoset != "" {
+		if src, err = fileGRPCSource(spec); err != nil {
+			return err
+		}
+	} else {
+		conn, err := dialGRPC(cmd, t)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		var closeSrc func()
+		src, closeSrc, err = grpcSource(metadata.NewOutgoingContext(ctx, grpcMetadata(t.base.Header)), spec, conn)
+		if err != nil {
+			return err
+		}
+		defer closeSrc()
+	}
+
+	if len(args) == 0 {
+		names, err := src.ListServices()
+		if err != nil {
+			return err
+		}
+		for _, n := range names {
+			fmt.Fprintln(cmd.OutOrStdout(), n)
+		}
+		return nil
+	}
+	sd, err := src.FindService(args[0])
+	if err != nil {
+		return err
+	}
+	for i := 0; i < sd.Methods().Len(); i++ {
+		m := sd.Methods().Get(i)
+		kind := ""
+		switch {
+		case m.IsStreamingClient() && m.IsStreamingServer():
+			kind = " (bidi streaming)"
+		case m.IsStreamingClient():
+			kind = " (client streaming)"
+		case m.IsStreamingServer():
+			kind = " (server streaming)"
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "%s/%s(%s) returns (%s)%s\n",
+			sd.FullName(), m.Name(), m.Input().FullName(), m.Output().FullName(), kind)
+	}
+	return nil
+}
+
+func saveGRPCCall(cmd *cobra.Command, pCtx *projContext, alias, method string) error {
+	call := project.Call{
+		Type:         project.CallGRPC,
+		Path:         method,
+		UseHeaderSet: getString(cmd, "use-headers"),
+		Description:  getString(cmd, "desc"),
+	}
+	if js := getString(cmd, "json"); js != "" {
+		call.Body = &project.BodySpec{JSON: &js}
+	}
+	if spec := grpcSpecFromFlags(cmd, nil); len(spec.Protos) > 0 || spec.Protoset != "" || spec.Web {
+		call.GRPC = &spec
+	}
+	if pCtx.Project.Calls == nil {
+		pCtx.Project.Calls = map[string]project.Call{}
+	}
+	pCtx.Project.Calls[alias] = call
+	if err := project.Save(pCtx.Dir, pCtx.Project); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Saved gRPC call %s\n", alias)
+	return nil
+}
+
+// grpcSpecFromFlags overlays descriptor flags on a saved spec.
+func grpcSpecFromFlags(cmd *cobra.Command, saved *project.GRPCSpec) project.GRPCSpec {
+	var spec project.GRPCSpec
+	if saved != nil {
+		spec = *saved
+	}
+	if p := getStringArray(cmd, "proto"); len(p) > 0 {
+		spec.Protos = p
+	}
+	if ip := getStringArray(cmd, "import-path"); len(ip) > 0 {
+		spec.ImportPaths = ip
+	}
+	if ps := getString(cmd, "protoset"); ps != "" {
+		spec.Protoset = ps
+	}
+	if getBool(cmd, "web") {
+		spec.Web = true
+	}
+	return spec
+}
+
+// resolveGRPCTarget builds the base request (environment, header set,
+// --header and template expansion as for HTTP) and picks the address.
+func resolveGRPCTarget(cmd *cobra.Command, pCtx *projContext, spec project.GRPCSpec, headerSet string, vars map[string]string) (*grpcTarget, error) {
+	envName := envFlag(cmd)
+	if envName == "" {
+		envName = pCtx.Project.DefaultEnv
+	}
+	path := "@"
+	addr := getString(cmd, "addr")
+	if addr == "" {
+		addr = template.Expand(pCtx.Project.Environments[envName].GRPCAddress, vars)
+	}
+	if addr != "" && spec.Web {
+		path = addr // gRPC-Web is plain HTTP: the address is the endpoint URL
+	}
+	base, err := httpx.BuildRequest(pCtx.Project, httpx.RequestSpec{
+		Method:       http.MethodPost,
+		Path:         path,
+		Headers:      getStringArray(cmd, "header"),
+		UseHeaderSet: headerSet,
+		Vars:         vars,
+		EnvName:      envName,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if addr == "" {
+		addr = (&url.URL{Scheme: base.URL.Scheme, Host: base.URL.Host}).String()
+	}
+	return &grpcTarget{spec: spec, base: base, addr: addr}, nil
+}
+
+func dialGRPC(cmd *cobra.Command, t *grpcTarget) (*grpc.ClientConn, error) {
+	target, plaintext, err := grpcx.Target(t.addr)
+	if err != nil {
+		return nil, err
+	}
+	return grpcx.Dial(target, plaintext || getBool(cmd, "plaintext"), getBool(cmd, "insecure"))
+}
+
+func grpcSource(ctx context.Context, spec project.GRPCSpec, conn *grpc.ClientConn) (grpcx.Source, func(), error) {
+	if len(spec.Protos) > 0 || spec.Protoset != "" {
+		src, err := fileGRPCSource(spec)
+		return src, func() {}, err
+	}
+	src, closeSrc := grpcx.ReflectionSource(ctx, conn)
+	return src, closeSrc, nil
+}
+
+func fileGRPCSource(spec project.GRPCSpec) (grpcx.Source, error) {
+	switch {
+	case spec.Protoset != "":
+		return grpcx.DescriptorSetSource(spec.Protoset)
+	case len(spec.Protos) > 0:
+		return grpcx.ProtoSource(spec.ImportPaths, spec.Protos...)
+	default:
+		return nil, fmt.Errorf("gRPC-Web needs descriptors: use --proto or --protoset")
+	}
+}
+
+// grpcMetadata turns request headers into outgoing metadata, dropping the
+// ones that belong to the HTTP/2 transport.
+func grpcMetadata(h http.Header) metadata.MD {
+	md := metadata.MD{}
+	for k, v := range h {
+		switch strings.ToLower(k) {
+		case "content-type", "content-length", "user-agent", "connection", "te", "host", "transfer-encoding":
+			continue
+		}
+		md.Append(k, v...)
+	}
+	return md
+}
+
+func grpcStatusError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if st, ok := status.FromError(err); ok {
+		return fmt.Errorf("grpc: %s: %s", st.Code(), st.Message())
+	}
+	return err
+}
+
+// writeGRPCMetadata prints header metadata, the buffered messages and the
+// trailers when include is set; otherwise body is empty and nothing is added.
+func writeGRPCMetadata(out io.Writer, header metadata.MD, body *bytes.Buffer, trailer metadata.MD, include bool) {
+	if include {
+		writeMD(out, header)
+		fmt.Fprintln(out)
+	}
+	out.Write(body.Bytes())
+	if include && len(trailer) > 0 {
+		fmt.Fprintln(out)
+		writeMD(out, trailer)
+	}
+}
+
+func writeMD(out io.Writer, md metadata.MD) {
+	keys := make([]string, 0, len(md))
+	for k := range md {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range md[k] {
+			fmt.Fprintf(out, "%s: %s\n", k, v)
+		}
+	}
+}
@@ -0,0 +1,462 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+	"github.com/suprbdev/reqo/internal/history"
+	httpx "github.com/suprbdev/reqo/internal/http"
+	"github.com/suprbdev/reqo/internal/output"
+	"github.com/suprbdev/reqo/internal/project"
+)
+
+// newHistoryCmd lists, shows, replays and clears logged requests.
+func newHistoryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Browse and replay the requests sent with req and call run",
+		Long: `Every request sent with 'reqo req', 'reqo call run' or 'reqo call
+run-many' is logged to .reqo/history.jsonl, or to ~/.reqo/history.jsonl when the project sets
+"history: {global: true}". An entry holds the request with credentials
+redacted, the environment, the status, the duration and the size of the
+response, and the start of the response body when "history:
+{response_body: <bytes>}" is set. Use "history: {off: true}" or
+--no-history to keep requests out of it.`,
+	}
+	cmd.PersistentFlags().Bool("global", false, "use ~/.reqo/history.jsonl instead of the project's history")
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List logged requests, oldest first",
+		Args:  cobra.NoArgs,
+		RunE:  runHistoryList,
+	}
+	listCmd.Flags().IntP("limit", "n", 20, "show at most this many of the newest matching entries (0 for all)")
+	addHistoryFilterFlags(listCmd)
+	cmd.AddCommand(listCmd)
+
+	showCmd := &cobra.Command{
+		Use:   "show <id>",
+		Short: "Show a logged request and its response",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			e, err := findHistoryEntry(cmd, args[0])
+			if err != nil {
+				return err
+			}
+			writeHistoryEntry(cmd.OutOrStdout(), e, output.ColorEnabled(cmd.OutOrStdout(), getBool(cmd, "no-color")))
+			return nil
+		},
+	}
+	cmd.AddCommand(showCmd)
+
+	replayCmd := &cobra.Command{
+		Use:   "replay <id>",
+		Short: "Send a logged request again",
+		Long: `Send a logged request again with the same method, URL, headers and
+body. Redacted headers are filled in from the environment and header set
+the request was built with; --header overrides any header and --query any
+query parameter. A request whose redacted headers or query parameters are
+not all filled in this way is refused rather than sent with the mask, and
+a body with redacted fields is not sent again: give the body to send with
+--data. The replay is logged as a new entry.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runHistoryReplay,
+	}
+	replayCmd.Flags().StringArray("header", nil, "extra header (Key: Value)")
+	replayCmd.Flags().StringArray("query", nil, "query param to set (k=v)")
+	replayCmd.Flags().String("data", "", "body to send instead of the logged one, or @file")
+	replayCmd.Flags().BoolP("include", "i", false, "show response headers")
+	replayCmd.Flags().Bool("raw", false, "output raw body")
+	replayCmd.Flags().StringP("output", "o", "", "output format for JSON responses ("+strings.Join(output.Formats, "|")+")")
+	replayCmd.Flags().String("jq", "", "jq expression applied to the JSON response")
+	replayCmd.Flags().String("output-file", "", "stream the response body to a file (- for stdout)")
+	replayCmd.Flags().Bool("timing", false, "print status, timings and body size to stderr")
+	replayCmd.Flags().Int("timeout", 30, "request timeout in seconds")
+	replayCmd.Flags().Int("retries", 0, "number of retries on failure")
+	replayCmd.Flags().BoolP("insecure", "k", false, "skip TLS certificate verification")
+	replayCmd.Flags().Bool("no-history", false, "do not log the replay to the history")
+	cmd.AddCommand(replayCmd)
+
+	clearCmd := &cobra.Command{
+		Use:   "clear",
+		Short: "Remove logged requests, all of them unless filtered",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			path, err := historyFile(cmd)
+			if err != nil {
+				return err
+			}
+			f, err := historyFilter(cmd)
+			if err != nil {
+				return err
+			}
+			n, err := history.Remove(path, f.Match)
+			if err != nil {
+				return err
+			}
+			noun := "entries"
+			if n == 1 {
+				noun = "entry"
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Removed %d %s from %s\n", n, noun, path)
+			return nil
+		},
+	}
+	addHistoryFilterFlags(clearCmd)
+	clearCmd.Flags().Duration("older-than", 0, "only entries older than this, e.g. 168h")
+	cmd.AddCommand(clearCmd)
+	return cmd
+}
+
+// addHistoryFilterFlags registers the flags read by historyFilter.
+func addHistoryFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().String("method", "", "only this HTTP method")
+	cmd.Flags().String("status", "", "only this status, e.g. 404 or 4xx")
+	cmd.Flags().String("call", "", "only requests of this saved call")
+	cmd.Flags().String("env", "", "only requests sent to this environment")
+	cmd.Flags().String("grep", "", "only URLs containing this text")
+	cmd.Flags().Bool("failed", false, "only requests without a response or with a 4xx/5xx status")
+	cmd.Flags().Duration("since", 0, "only entries newer than this, e.g. 1h")
+}
+
+func historyFilter(cmd *cobra.Command) (history.Filter, error) {
+	f := history.Filter{
+		Method:   getString(cmd, "method"),
+		Status:   getString(cmd, "status"),
+		Call:     getString(cmd, "call"),
+		Env:      getString(cmd, "env"),
+		Contains: getString(cmd, "grep"),
+		Failed:   getBool(cmd, "failed"),
+	}
+	if d, _ := cmd.Flags().GetDuration("since"); d > 0 {
+		f.Since = time.Now().Add(-d)
+	}
+	if d, _ := cmd.Flags().GetDuration("older-than"); d > 0 {
+		f.Before = time.Now().Add(-d)
+	}
+	return f, f.Validate()
+}
+
+// historyFile is the history the history subcommands work on.
+func historyFile(cmd *cobra.Command) (string, error) {
+	if getBool(cmd, "global") {
+		return history.GlobalPath()
+	}
+	p, err := resolveProject(cmd)
+	if err != nil {
+		return "", err
+	}
+	return historyPath(p.Project.History, p.Dir)
+}
+
+// historyPath is where requests of a project with settings s are logged.
+func historyPath(s project.HistorySettings, projectDir string) (string, error) {
+	if s.Global {
+		return history.GlobalPath()
+	}
+	return history.Path(projectDir), nil
+}
+
+func findHistoryEntry(cmd *cobra.Command, arg string) (*history.Entry, error) {
+	var id int
+	if _, err := fmt.Sscan(arg, &id); err != nil || id < 1 {
+		return nil, fmt.Errorf("invalid history id %q", arg)
+	}
+	path, err := historyFile(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return history.Find(path, id)
+}
+
+func runHistoryList(cmd *cobra.Command, _ []string) error {
+	path, err := historyFile(cmd)
+	if err != nil {
+		return err
+	}
+	f, err := historyFilter(cmd)
+	if err != nil {
+		return err
+	}
+	entries, err := history.Load(path)
+	if err != nil {
+		return err
+	}
+	var matched []history.Entry
+	for _, e := range entries {
+		if f.Match(e) {
+			matched = append(matched, e)
+		}
+	}
+	if len(matched) == 0 {
+		fmt.Fprintln(cmd.OutOrStdout(), "No requests found.")
+		return nil
+	}
+	if limit := getInt(cmd, "limit"); limit > 0 && len(matched) > limit {
+		matched = matched[len(matched)-limit:]
+	}
+	out := cmd.OutOrStdout()
+	width := len(fmt.Sprint(matched[len(matched)-1].ID))
+	for _, e := range matched {
+		status := fmt.Sprint(e.Status)
+		if e.Error != "" {
+			status = "ERR"
+		}
+		line := fmt.Sprintf("%*d  %s  %-6s %s  %6s  %s", width, e.ID, e.Time.Local().Format("2006-01-02 15:04:05"),
+			e.Request.Method, status, (time.Duration(e.DurationMS) * time.Millisecond).String(), e.Request.URL)
+		if e.Call != "" {
+			line += "  (" + e.Call + ")"
+		}
+		fmt.Fprintln(out, line)
+	}
+	return nil
+}
+
+// writeHistoryEntry prints an entry like a request and response dump.
+func writeHistoryEntry(out io.Writer, e *history.Entry, color bool) {
+	fmt.Fprintf(out, "#%d  %s", e.ID, e.Time.Local().Format(time.RFC3339))
+	for _, kv := range [][2]string{{"project", e.Project}, {"env", e.Env}, {"call", e.Call}} {
+		if kv[1] != "" {
+			fmt.Fprintf(out, "  %s %s", kv[0], kv[1])
+		}
+	}
+	fmt.Fprintf(out, "\n\n%s %s\n", e.Request.Method, e.Request.URL)
+	for _, k := range sortedKeys(e.Request.Header) {
+		for _, v := range e.Request.Header[k] {
+			fmt.Fprintf(out, "%s: %s\n", k, v)
+		}
+	}
+	switch {
+	case e.Request.Body != "":
+		fmt.Fprintf(out, "\n%s\n", e.Request.Body)
+	case e.Request.BodyFile != "":
+		fmt.Fprintf(out, "\n(body streamed from %s)\n", e.Request.BodyFile)
+	case e.Request.BodyOmitted != "":
+		fmt.Fprintf(out, "\n(body not recorded: %s)\n", e.Request.BodyOmitted)
+	}
+	fmt.Fprintln(out)
+	if e.Error != "" {
+		output.WriteCheck(out, 0, false, e.Error, color)
+		return
+	}
+	fmt.Fprintf(out, "→ %s in %s, %s\n", statusLabel(e.Status), time.Duration(e.DurationMS)*time.Millisecond, output.HumanBytes(e.Size))
+	if e.Response != "" {
+		fmt.Fprintf(out, "\n%s\n", strings.TrimRight(e.Response, "\n"))
+		if e.ResponseTruncated {
+			fmt.Fprintf(out, "… (%s not recorded)\n", output.HumanBytes(e.Size-int64(len(e.Response))))
+		}
+	}
+}
+
+func sortedKeys(h http.Header) []string {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func runHistoryReplay(cmd *cobra.Command, args []string) error {
+	e, err := findHistoryEntry(cmd, args[0])
+	if err != nil {
+		return err
+	}
+	p, err := resolveProject(cmd)
+	if err != nil {
+		return err
+	}
+	req, err := e.Request.HTTPRequest()
+	if err != nil {
+		return fmt.Errorf("cannot replay %d: %w", e.ID, err)
+	}
+	fillRedacted(req, p.Project, e)
+	if data := getString(cmd, "data"); data != "" {
+		body, err := httpx.ReadPossiblyFile(data)
+		if err != nil {
+			return err
+		}
+		req.Body = io.NopCloser(strings.NewReader(body))
+		req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(strings.NewReader(body)), nil }
+		req.ContentLength = int64(len(body))
+	} else if len(e.Request.BodyRedacted) > 0 {
+		return fmt.Errorf("cannot replay %d: the body was logged with %s redacted; pass the body with --data",
+			e.ID, strings.Join(e.Request.BodyRedacted, ", "))
+	}
+	for _, h := range getStringArray(cmd, "header") {
+		k, v, ok := strings.Cut(h, ":")
+		if !ok {
+			return fmt.Errorf("invalid header %q – must be \"Key: Value\"", h)
+		}
+		req.Header.Set(strings.TrimSpace(k), strings.TrimSpace(v))
+	}
+	if params := getStringArray(cmd, "query"); len(params) > 0 {
+		q := req.URL.Query()
+		for _, param := range params {
+			k, v, ok := strings.Cut(param, "=")
+			if !ok {
+				return fmt.Errorf("invalid query param %q – must be k=v", param)
+			}
+			q.Set(k, v)
+		}
+		req.URL.RawQuery = q.Encode()
+	}
+	// a masked credential is never sent as the literal REDACTED
+	var still []string
+	for _, name := range e.Request.Redacted() {
+		vals := req.Header.Values(name)
+		if k, isQuery := strings.CutPrefix(name, "?"); isQuery {
+			vals = req.URL.Query()[k]
+		}
+		for _, v := range vals {
+			if strings.Contains(v, httpx.RedactedValue) {
+				still = append(still, name)
+				break
+			}
+		}
+	}
+	if len(still) > 0 {
+		return fmt.Errorf("cannot replay %d: %s still redacted; pass the real values with --header or --query",
+			e.ID, strings.Join(still, ", "))
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "Replaying #%d: %s %s\n", e.ID, req.Method, e.Request.URL)
+	return sendRequest(cmd, p, req, e.Call)
+}
+
+// fillRedacted puts the current values of redacted headers back into req,
+// taken from the environment and header set the entry was sent with.
+func fillRedacted(req *http.Request, p *project.Project, e *history.Entry) {
+	spec := httpx.RequestSpec{Method: req.Method, Path: "/", EnvName: e.Env}
+	if c, ok := p.Calls[e.Call]; ok {
+		spec.UseHeaderSet = c.UseHeaderSet
+		spec.Headers = c.Headers
+	}
+	fresh, err := httpx.BuildRequest(p, spec)
+	if err != nil {
+		return
+	}
+	for k, vals := range req.Header {
+		if len(vals) == 1 && strings.Contains(vals[0], httpx.RedactedValue) && fresh.Header.Get(k) != "" {
+			req.Header[k] = fresh.Header.Values(k)
+		}
+	}
+}
+
+// historyRecorder collects the history entry of a request sent by
+// sendRequest. A nil recorder logs nothing.
+type historyRecorder struct {
+	path    string
+	entry   history.Entry
+	sent    bool // Execute was called
+	start   time.Time
+	resp    *http.Response
+	size    *httpx.BodySize
+	decoded bool
+	body    *headBuffer
+}
+
+// startHistory records req before it is sent. It returns nil when the
+// project keeps no history or --no-history is set.
+func startHistory(cmd *cobra.Command, pCtx *projContext, req *http.Request, alias string) *historyRecorder {
+	s := pCtx.Project.History
+	if s.Off || getBool(cmd, "no-history") {
+		return nil
+	}
+	path, err := historyPath(s, pCtx.Dir)
+	if err != nil {
+		return nil
+	}
+	env := envFlag(cmd)
+	if env == "" {
+		env = pCtx.Project.DefaultEnv
+	}
+	h := &historyRecorder{
+		path: path,
+		entry: history.Entry{
+			Time:    time.Now(),
+			Project: pCtx.Project.Name,
+			Env:     env,
+			Call:    alias,
+			Request: history.NewRequest(req),
+		},
+		decoded: !getBool(cmd, "no-decompress"),
+	}
+	if s.ResponseBody > 0 {
+		h.body = &headBuffer{limit: s.ResponseBody}
+	}
+	return h
+}
+
+// sending marks the start of the request.
+func (h *historyRecorder) sending() {
+	if h != nil {
+		h.sent, h.start = true, time.Now()
+	}
+}
+
+// capture keeps resp for the entry and, when response bodies are logged,
+// copies the start of its body as it is read.
+func (h *historyRecorder) capture(resp *http.Response, size *httpx.BodySize) {
+	if h == nil {
+		return
+	}
+	h.resp, h.size = resp, size
+	if h.body != nil {
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.TeeReader(resp.Body, h.body), resp.Body}
+	}
+}
+
+// finish completes the entry once the response has been handled and
+// appends it to the history; a failure to write it is only reported.
+func (h *historyRecorder) finish(cmd *cobra.Command, err error) {
+	if h == nil {
+		return
+	}
+	if !h.sent {
+		return // failed before sending
+	}
+	e := &h.entry
+	e.DurationMS = time.Since(h.start).Milliseconds()
+	if h.resp == nil {
+		e.Error = err.Error()
+	} else {
+		e.Status = h.resp.StatusCode
+		e.Size = h.size.Encoded()
+		if n := h.size.Decoded(); h.decoded && n >= 0 {
+			e.Size = n
+		}
+	}
+	if h.body != nil && utf8.Valid(h.body.data) {
+		e.Response = string(h.body.data)
+		e.ResponseTruncated = h.body.truncated
+	}
+	if err := history.Append(h.path, e); err != nil {
+		fmt.Fprintf(cmd.ErrOrStderr(), "reqo: request not logged to the history: %v\n", err)
+	}
+}
+
+// headBuffer keeps the first limit bytes written to it.
+type headBuffer struct {
+	limit     int
+	data      []byte
+	truncated bool
+}
+
+func (b *headBuffer) Write(p []byte) (int, error) {
+	n := min(len(p), b.limit-len(b.data))
+	b.data = append(b.data, p[:n]...)
+	if n < len(p) {
+		b.truncated = true
+	}
+	return len(p), nil
+}
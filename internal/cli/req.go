@@ -1,16 +1,26 @@
 package cli
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"net/url"
 	"os"
+	"path"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/suprbdev/reqo/internal/assert"
+	"github.com/suprbdev/reqo/internal/contract"
 	httpx "github.com/suprbdev/reqo/internal/http"
 	"github.com/suprbdev/reqo/internal/output"
 	"github.com/suprbdev/reqo/internal/project"
+	"github.com/suprbdev/reqo/internal/template"
 )
 
 func newReqCmd() *cobra.Command {
@@ -21,18 +31,51 @@ func newReqCmd() *cobra.Command {
 otherwise GET is assumed and the first argument is treated as the path.`,
 		RunE: runReq,
 	}
-	cmd.Flags().StringArray("header", nil, "extra header")
+	addRequestFlags(cmd)
+	return cmd
+}
+
+// addRequestFlags registers the flags shared by every command that builds
+// and sends a request (`req`, `call run` and the `call <alias>` shorthand).
+func addRequestFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArray("header", nil, "extra header (Key: Value)")
 	cmd.Flags().StringArray("query", nil, "extra query param (k=v)")
 	cmd.Flags().String("env", "", "environment to use")
+	cmd.Flags().Bool("as-curl", false, "print equivalent curl command and exit")
+	cmd.Flags().Bool("redact", false, "mask credentials in --as-curl output")
 	cmd.Flags().BoolP("include", "i", false, "show response headers")
 	cmd.Flags().Bool("raw", false, "output raw body")
-	cmd.Flags().Int("timeout", 30, "seconds")
-	cmd.Flags().Int("retries", 0, "retry count")
-	cmd.Flags().StringToString("form", nil, "multipart form fields")
+	cmd.Flags().StringP("output", "o", "", "output format for JSON responses ("+strings.Join(output.Formats, "|")+")")
+	cmd.Flags().String("jq", "", "jq expression applied to the JSON response")
+	cmd.Flags().String("output-file", "", "stream the response body to a file (- for stdout)")
+	cmd.Flags().BoolP("remote-name", "O", false, "save the body under the server-provided file name")
+	cmd.Flags().BoolP("continue", "C", false, "resume a partial --output-file/-O download with a Range request")
+	cmd.Flags().String("sha256", "", "verify the saved file against this SHA-256 hex digest")
+	cmd.Flags().Bool("sse", false, "stream the response as Server-Sent Events (no overall timeout unless --timeout is set)")
+	cmd.Flags().Int("max-events", 0, "stop an event stream after this many events")
+	cmd.Flags().Bool("reconnect", false, "reconnect a closed event stream, sending Last-Event-ID")
+	cmd.Flags().String("last-event-id", "", "Last-Event-ID to resume an event stream from")
+	cmd.Flags().String("compress", "", "compress the request body ("+strings.Join(httpx.RequestEncodings, "|")+") and set Content-Encoding")
+	cmd.Flags().Bool("compressed", false, "send Accept-Encoding: "+httpx.AcceptEncodings+" and decode the response")
+	cmd.Flags().Bool("no-decompress", false, "print or save the response body exactly as encoded by the server")
+	cmd.Flags().Bool("timing", false, "print status, timings and body size to stderr")
+	cmd.Flags().String("schema", "", "validate the JSON response against this JSON Schema (draft 2020-12) file")
+	cmd.Flags().String("contract", "", "check the request and response against this OpenAPI 3 file (overrides the project's contract)")
+	cmd.Flags().Bool("no-contract", false, "skip the project's OpenAPI contract")
+	cmd.Flags().Bool("no-history", false, "do not log this request to the history")
+	cmd.Flags().Int("timeout", 30, "request timeout in seconds")
+	cmd.Flags().Int("retries", 0, "number of retries on failure")
+	cmd.Flags().BoolP("insecure", "k", false, "skip TLS certificate verification")
+	cmd.Flags().StringArray("var", nil, "variables for template expansion (key=value)")
+	// data flags
 	cmd.Flags().String("json", "", "JSON body or @file")
 	cmd.Flags().String("data", "", "raw body or @file")
-	cmd.Flags().StringArray("var", nil, "template variables (key=value)")
-	return cmd
+	cmd.Flags().String("data-binary", "", "binary body streamed from @file (or @- for stdin) without template expansion")
+	cmd.Flags().StringArray("form", nil, "multipart part name=value or name=@file[;type=...][;filename=...] (repeatable, order kept; one part per flag, commas belong to the value)")
+	cmd.Flags().StringArray("form-urlencoded", nil, "URL-encoded form field k=v (repeatable)")
+	cmd.Flags().String("graphql", "", "GraphQL query or @file.graphql (sent as a JSON POST)")
+	cmd.Flags().String("variables", "", "GraphQL variables as JSON or @file")
+	cmd.Flags().String("operation-name", "", "GraphQL operation to execute")
 }
 
 func runReq(cmd *cobra.Command, args []string) error {
@@ -44,31 +87,20 @@ func runReq(cmd *cobra.Command, args []string) error {
 		method = strings.ToUpper(args[0])
 		path = args[1]
 	} else {
-		method = "GET"
+		// BuildRequest picks GET, or POST for a GraphQL query
 		path = args[0]
 	}
 
-	// Gather vars
-	vars := map[string]string{}
-	for _, v := range getStringArray(cmd, "var") {
-		kv := strings.SplitN(v, "=", 2)
-		if len(kv) == 2 {
-			vars[kv[0]] = kv[1]
-		}
-	}
+	vars := parseVars(cmd)
 
 	pCtx, err := resolveProject(cmd)
 	if err != nil {
 		return err
 	}
 
-    // derive environment name: flag > REQO_ENV > project default (handled in BuildRequest)
-    envName := getString(cmd, "env")
-    if envName == "" {
-        envName = os.Getenv("REQO_ENV")
-    }
+	envName := envFlag(cmd)
 
-    spec := httpx.RequestSpec{
+	spec := httpx.RequestSpec{
 		Method:      method,
 		Path:        path,
 		QueryParams: getStringArray(cmd, "query"),
@@ -82,8 +114,15 @@ func runReq(cmd *cobra.Command, args []string) error {
 	if rawBody := getString(cmd, "data"); rawBody != "" {
 		spec.RawBody = &rawBody
 	}
-	if fm := getStringToString(cmd, "form"); len(fm) > 0 {
-		spec.FormFields = fm
+	if binBody := getString(cmd, "data-binary"); binBody != "" {
+		spec.BinaryBody = &binBody
+	}
+	if spec.Form, err = formFlag(cmd); err != nil {
+		return err
+	}
+	spec.URLEncoded = getStringArray(cmd, "form-urlencoded")
+	if spec.GraphQL, err = graphQLFromFlags(cmd, nil); err != nil {
+		return err
 	}
 
 	req, err := httpx.BuildRequest(pCtx.Project, spec)
@@ -91,26 +130,279 @@ func runReq(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(getInt(cmd, "timeout"))*time.Second)
-	defer cancel()
+	return sendRequest(cmd, pCtx, req, "")
+}
+
+// sendRequest executes a built request according to the flags registered by
+// addRequestFlags and renders the response (or the curl equivalent). The
+// request is logged to the history under alias, the saved call it was built
+// from, if any.
+func sendRequest(cmd *cobra.Command, pCtx *projContext, req *http.Request, alias string) (err error) {
+	if f := getString(cmd, "output"); !output.ValidFormat(f) {
+		return fmt.Errorf("unknown output format %q (supported: %s)", f, strings.Join(output.Formats, ", "))
+	}
+	if getBool(cmd, "as-curl") {
+		curlCmd, _ := httpx.AsCurlOpts(req, httpx.CurlOpts{
+			Compressed: getBool(cmd, "compressed"),
+			Insecure:   getBool(cmd, "insecure"),
+			Redact:     getBool(cmd, "redact"),
+		})
+		fmt.Fprintln(cmd.OutOrStdout(), curlCmd)
+		return nil
+	}
+
+	outFile := getString(cmd, "output-file")
+	var offset int64
+	if getBool(cmd, "continue") {
+		if outFile == "" && getBool(cmd, "remote-name") {
+			// Content-Disposition is unknown until the response arrives
+			outFile = path.Base(req.URL.Path)
+		}
+		if outFile == "" || outFile == "-" || outFile == "/" || outFile == "." {
+			return fmt.Errorf("--continue requires --output-file <path> or -O")
+		}
+		if fi, err := os.Stat(outFile); err == nil && fi.Size() > 0 {
+			offset = fi.Size()
+			req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		}
+	}
+	if getString(cmd, "sha256") != "" && (outFile == "-" || outFile == "" && !getBool(cmd, "remote-name")) {
+		return fmt.Errorf("--sha256 requires --output-file <path> or -O")
+	}
+
+	var schema *assert.Schema
+	if path := getString(cmd, "schema"); path != "" {
+		var err error
+		if schema, err = assert.LoadSchema(path); err != nil {
+			return err
+		}
+	}
+	op, err := matchContract(cmd, pCtx, req)
+	if err != nil {
+		return err
+	}
+	if op != nil {
+		if err := checkContractRequest(cmd, op, req); err != nil {
+			return err
+		}
+	}
+	hist := startHistory(cmd, pCtx, req, alias)
+	defer func() { hist.finish(cmd, err) }()
+	if enc := getString(cmd, "compress"); enc != "" {
+		if err := httpx.CompressRequest(req, enc); err != nil {
+			return err
+		}
+	}
+	if req.Header.Get("Accept-Encoding") == "" {
+		if getBool(cmd, "compressed") {
+			req.Header.Set("Accept-Encoding", httpx.AcceptEncodings)
+		} else if req.Header.Get("Range") == "" {
+			// what Go's transport would send; set explicitly so the body is
+			// decoded (and measured) by DecodeResponse instead
+			req.Header.Set("Accept-Encoding", "gzip")
+		}
+	}
+
+	// The timeout bounds the whole exchange, except that an event stream,
+	// asked for with --sse or recognised by its Content-Type, runs until it
+	// ends unless --timeout is given. It is a timer on ctx rather than a
+	// client timeout so it can be stopped once the response is seen.
+	sse := getBool(cmd, "sse")
+	timeout := time.Duration(getInt(cmd, "timeout")) * time.Second
+	timedOut := fmt.Errorf("timed out after %s", timeout)
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+	deadline := time.AfterFunc(timeout, func() { cancel(timedOut) })
+	defer deadline.Stop()
+	if sse && !cmd.Flags().Changed("timeout") {
+		deadline.Stop()
+	}
 
 	execOpts := httpx.ExecOpts{
-		Timeout:      time.Duration(getInt(cmd, "timeout")) * time.Second,
 		Retries:      getInt(cmd, "retries"),
 		Backoff:      200 * time.Millisecond,
 		MaxRedirects: 10,
+		Insecure:     getBool(cmd, "insecure"),
+	}
+	if sse {
+		if req.Header.Get("Accept") == "" {
+			req.Header.Set("Accept", "text/event-stream")
+		}
+		if id := getString(cmd, "last-event-id"); id != "" {
+			req.Header.Set("Last-Event-ID", id)
+		}
 	}
+	start := time.Now()
+	hist.sending()
 	resp, err := httpx.Execute(ctx, nil, req, execOpts)
 	if err != nil {
+		if context.Cause(ctx) == timedOut {
+			return fmt.Errorf("request failed: %w", timedOut)
+		}
 		return fmt.Errorf("request failed: %w", err)
 	}
+	ttfb := time.Since(start)
 	defer resp.Body.Close()
+	size := httpx.DecodeResponse(resp, !getBool(cmd, "no-decompress"))
+	hist.capture(resp, size)
+
+	if output.IsSSE(resp) && !cmd.Flags().Changed("timeout") {
+		deadline.Stop()
+	}
+	if output.IsSSE(resp) && !getBool(cmd, "raw") && outFile == "" && !getBool(cmd, "remote-name") {
+		return streamEvents(ctx, cmd, req, resp, execOpts)
+	}
+	var body bytes.Buffer
+	if schema != nil || op != nil {
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.TeeReader(resp.Body, &body), resp.Body}
+	}
+	if getBool(cmd, "timing") {
+		defer func() {
+			total := time.Since(start)
+			resp.Body.Close()
+			output.WriteTiming(cmd.ErrOrStderr(), output.Timing{
+				StatusCode: resp.StatusCode,
+				TTFB:       ttfb,
+				Total:      total,
+				Encoding:   size.Encoding,
+				Encoded:    size.Encoded(),
+				Decoded:    size.Decoded(),
+			}, output.ColorEnabled(cmd.ErrOrStderr(), getBool(cmd, "no-color")))
+		}()
+	}
 
 	renderOpts := output.RenderOpts{
 		ShowHeaders: getBool(cmd, "include"),
 		RawOutput:   getBool(cmd, "raw"),
+		JQExpr:      getString(cmd, "jq"),
+		Format:      getString(cmd, "output"),
+		Color:       output.ColorEnabled(cmd.OutOrStdout(), getBool(cmd, "no-color")),
+		OutputFile:  outFile,
+		GraphQL:     httpx.IsGraphQL(req),
+		Download: output.DownloadOpts{
+			Offset: offset,
+			SHA256: getString(cmd, "sha256"),
+		},
+	}
+	if getBool(cmd, "remote-name") && renderOpts.OutputFile == "" {
+		if renderOpts.OutputFile, err = output.RemoteName(resp); err != nil {
+			return err
+		}
+	}
+	saving := renderOpts.OutputFile != "" && renderOpts.OutputFile != "-"
+	if saving && output.IsTerminal(cmd.ErrOrStderr()) {
+		renderOpts.Download.Progress = cmd.ErrOrStderr()
+	}
+	if err := output.Render(resp, cmd.OutOrStdout(), renderOpts); err != nil {
+		if context.Cause(ctx) == timedOut {
+			return fmt.Errorf("response cut off: %w", timedOut)
+		}
+		return err
+	}
+	if saving {
+		if fi, err := os.Stat(renderOpts.OutputFile); err == nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Saved %s to %s\n", output.HumanBytes(fi.Size()), renderOpts.OutputFile)
+		}
+	}
+	if op != nil {
+		if err := checkContractResponse(cmd, op, req, resp, body.Bytes()); err != nil {
+			return err
+		}
+	}
+	if schema != nil {
+		return checkSchema(cmd, schema, body.Bytes())
+	}
+	return nil
+}
+
+// matchContract finds the contract operation for req when --contract or the
+// project's contract setting names an OpenAPI file; it returns nil without
+// one. A request the project's contract does not declare is only warned
+// about, so paths outside the document can still be called; with
+// --contract it fails.
+func matchContract(cmd *cobra.Command, pCtx *projContext, req *http.Request) (*contract.Operation, error) {
+	file := getString(cmd, "contract")
+	if file == "" && !getBool(cmd, "no-contract") && pCtx.Project.Contract != "" {
+		file = pCtx.Project.Contract
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(pCtx.Dir, file)
+		}
+	}
+	if file == "" {
+		return nil, nil
+	}
+	c, err := contract.Load(file)
+	if err != nil {
+		return nil, err
+	}
+	// the request may sit under the environment's base path
+	var basePath string
+	envName := envFlag(cmd)
+	if envName == "" {
+		envName = pCtx.Project.DefaultEnv
+	}
+	if env, ok := pCtx.Project.Environments[envName]; ok {
+		if u, err := url.Parse(template.Expand(env.BaseURL, parseVars(cmd))); err == nil {
+			basePath = u.Path
+		}
+	}
+	op, err := c.Match(req, basePath)
+	var noOp *contract.NoOperationError
+	if errors.As(err, &noOp) && !cmd.Flags().Changed("contract") {
+		fmt.Fprintf(cmd.ErrOrStderr(), "reqo: warning: %v; not checked\n", err)
+		return nil, nil
+	}
+	return op, err
+}
+
+// checkContractRequest validates a request before it is sent, listing every
+// violation on stderr.
+func checkContractRequest(cmd *cobra.Command, op *contract.Operation, req *http.Request) error {
+	violations, err := op.CheckRequest(req)
+	if err != nil {
+		return err
+	}
+	return contractViolations(cmd, violations, "request does not match %s", op)
+}
+
+// checkContractResponse validates the status, headers and body of a response.
+func checkContractResponse(cmd *cobra.Command, op *contract.Operation, req *http.Request, resp *http.Response, body []byte) error {
+	violations, err := op.CheckResponse(req, resp.StatusCode, resp.Header, body)
+	if err != nil {
+		return err
+	}
+	return contractViolations(cmd, violations, "response does not match %s", op)
+}
+
+func contractViolations(cmd *cobra.Command, violations []contract.Violation, format string, op *contract.Operation) error {
+	if len(violations) == 0 {
+		return nil
+	}
+	cmd.SilenceUsage = true
+	for _, v := range violations {
+		fmt.Fprintf(cmd.ErrOrStderr(), "  %s\n", v)
+	}
+	return fmt.Errorf(format+" (%s)", op, plural(len(violations), "violation"))
+}
+
+// checkSchema validates a response body for --schema, listing every
+// violation on stderr.
+func checkSchema(cmd *cobra.Command, schema *assert.Schema, body []byte) error {
+	violations, err := schema.Validate(body)
+	if err != nil {
+		return err
 	}
-	return output.Render(resp, cmd.OutOrStdout(), renderOpts)
+	if len(violations) == 0 {
+		return nil
+	}
+	cmd.SilenceUsage = true
+	for _, v := range violations {
+		fmt.Fprintf(cmd.ErrOrStderr(), "  %s\n", v)
+	}
+	return fmt.Errorf("response does not match schema %s (%s)", schema.Path, plural(len(violations), "violation"))
 }
 
 // utility
@@ -148,6 +440,76 @@ func resolveProject(cmd *cobra.Command) (*projContext, error) {
 	return &projContext{Dir: dir, Project: p}, err
 }
 
+// graphQLFromFlags merges --graphql, --variables and --operation-name over
+// a saved GraphQL body. It returns nil when neither is present.
+func graphQLFromFlags(cmd *cobra.Command, saved *project.GraphQLSpec) (*httpx.GraphQLBody, error) {
+	var g *httpx.GraphQLBody
+	if saved != nil {
+		g = &httpx.GraphQLBody{Query: saved.Query, OperationName: saved.OperationName}
+		if saved.Variables != nil { // keep a nil map out of the interface
+			g.Variables = saved.Variables
+		}
+	}
+	if q := getString(cmd, "graphql"); q != "" {
+		if g == nil {
+			g = &httpx.GraphQLBody{}
+		}
+		g.Query = q
+	}
+	vars, op := getString(cmd, "variables"), getString(cmd, "operation-name")
+	if g == nil {
+		if vars != "" || op != "" {
+			return nil, fmt.Errorf("--variables and --operation-name need a GraphQL query (--graphql)")
+		}
+		return nil, nil
+	}
+	if vars != "" {
+		v, err := httpx.ParseGraphQLVariables(vars)
+		if err != nil {
+			return nil, err
+		}
+		g.Variables = v
+	}
+	if op != "" {
+		g.OperationName = op
+	}
+	return g, nil
+}
+
+// parseVars collects --var key=value flags for template expansion.
+func parseVars(cmd *cobra.Command) map[string]string {
+	vars := map[string]string{}
+	for _, v := range getStringArray(cmd, "var") {
+		kv := strings.SplitN(v, "=", 2)
+		if len(kv) == 2 {
+			vars[kv[0]] = kv[1]
+		}
+	}
+	return vars
+}
+
+// formFlag parses the --form parts in curl -F syntax.
+func formFlag(cmd *cobra.Command) ([]project.FormField, error) {
+	var form []project.FormField
+	for _, f := range getStringArray(cmd, "form") {
+		field, err := project.ParseFormField(f)
+		if err != nil {
+			return nil, err
+		}
+		form = append(form, field)
+	}
+	return form, nil
+}
+
+// envFlag derives the environment name: flag > REQO_ENV > project default
+// (handled in BuildRequest).
+func envFlag(cmd *cobra.Command) string {
+	if env := getString(cmd, "env"); env != "" {
+		return env
+	}
+	return os.Getenv("REQO_ENV")
+}
+
 // small flag getters (avoid repetition)
 func getString(cmd *cobra.Command, name string) string {
 	s, _ := cmd.Flags().GetString(name)
@@ -165,7 +527,3 @@ func getStringArray(cmd *cobra.Command, name string) []string {
 	sa, _ := cmd.Flags().GetStringArray(name)
 	return sa
 }
-func getStringToString(cmd *cobra.Command, name string) map[string]string {
-	m, _ := cmd.Flags().GetStringToString(name)
-	return m
-}
@@ -34,6 +34,14 @@ Run 'reqo <command> --help' for details.`,
 		newHeaderCmd(),
 		newCallCmd(),
 		newReqCmd(),
+		newWSCmd(),
+		newGraphQLCmd(),
+		newGRPCCmd(),
+		newTestCmd(),
+		newSnapshotCmd(),
+		newFlowCmd(),
+		newBenchCmd(),
+		newHistoryCmd(),
 	)
 
 	return root
@@ -0,0 +1,177 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+	"github.com/suprbdev/reqo/internal/output"
+)
+
+// newRunManyCmd runs several saved calls at the same time.
+func newRunManyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "run-many [alias...]",
+		Short: "Run several saved calls concurrently against one environment",
+		Long: `Run the saved HTTP calls named as arguments, or those carrying any of
+the --tag values, with up to --parallel of them in flight. Each response is
+printed as soon as its call finishes, every line prefixed with the alias,
+or written to <output-dir>/<alias>.<ext> with --output-dir. A summary of
+the statuses follows; the command fails when a call got no response or a
+4xx/5xx status.`,
+		RunE: runMany,
+	}
+	cmd.Flags().StringArray("tag", nil, "run the HTTP calls with this tag (repeatable)")
+	cmd.Flags().Int("parallel", 4, "number of calls run at the same time")
+	cmd.Flags().String("output-dir", "", "write each response body to <dir>/<alias>.<ext> instead of printing it")
+	cmd.Flags().String("env", "", "environment to use")
+	cmd.Flags().StringArray("var", nil, "variables for template expansion (key=value)")
+	cmd.Flags().StringArray("header", nil, "extra header (Key: Value)")
+	cmd.Flags().Int("timeout", 30, "request timeout in seconds (per call)")
+	cmd.Flags().BoolP("insecure", "k", false, "skip TLS certificate verification")
+	cmd.Flags().BoolP("include", "i", false, "show response headers")
+	cmd.Flags().Bool("raw", false, "output raw bodies")
+	cmd.Flags().String("jq", "", "jq expression applied to each JSON response")
+	cmd.Flags().Bool("no-history", false, "do not log the calls to the history")
+	return cmd
+}
+
+func runMany(cmd *cobra.Command, args []string) error {
+	tags := getStringArray(cmd, "tag")
+	if len(args) == 0 && len(tags) == 0 {
+		return fmt.Errorf("name the calls to run or select them with --tag")
+	}
+	parallel := getInt(cmd, "parallel")
+	if parallel < 1 {
+		return fmt.Errorf("--parallel must be at least 1")
+	}
+	p, err := resolveProject(cmd)
+	if err != nil {
+		return err
+	}
+	aliases, err := selectCalls(p.Project, args, tags, nil)
+	if err != nil {
+		return err
+	}
+	if len(aliases) == 0 {
+		return fmt.Errorf("no HTTP calls tagged %s", strings.Join(tags, ", "))
+	}
+	dir := getString(cmd, "output-dir")
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	cmd.SilenceUsage = true // failed calls are counted in the summary
+
+	out := cmd.OutOrStdout()
+	color := output.ColorEnabled(out, getBool(cmd, "no-color"))
+	opts := output.RenderOpts{
+		ShowHeaders: getBool(cmd, "include"),
+		RawOutput:   getBool(cmd, "raw"),
+		JQExpr:      getString(cmd, "jq"),
+		Color:       color,
+	}
+	width := 0
+	for _, alias := range aliases {
+		width = max(width, len(alias))
+	}
+	vars := parseVars(cmd)
+
+	start := time.Now()
+	runs := make([]*callRun, len(aliases))
+	runParallel(parallel, len(aliases), func(i int) {
+		runs[i] = runLoggedCall(cmd, p, aliases[i], vars)
+	}, func(i int) {
+		writeManyRun(out, fmt.Sprintf("[%-*s] ", width, aliases[i]), runs[i], dir, opts)
+	})
+
+	statuses := make([]int, len(runs))
+	failed := 0
+	for i, run := range runs {
+		statuses[i] = run.Status
+		if run.Err != nil || run.Status >= 400 {
+			failed++
+		}
+	}
+	writeStatusSummary(out, "call", statuses, failed, time.Since(start))
+	if failed > 0 {
+		return fmt.Errorf("%d of %s failed", failed, plural(len(runs), "call"))
+	}
+	return nil
+}
+
+// writeManyRun prints the outcome of one call of run-many with every line
+// prefixed, or saves its body under dir.
+func writeManyRun(out io.Writer, prefix string, run *callRun, dir string, opts output.RenderOpts) {
+	line := prefix
+	if run.Request != nil {
+		line += run.Request.Method + " " + run.Request.URL.Path
+	}
+	if run.Err != nil {
+		fmt.Fprintf(out, "%s – %v\n", line, run.Err)
+		return
+	}
+	fmt.Fprintf(out, "%s → %d (%s)\n", line, run.Status, run.Duration.Round(time.Millisecond))
+
+	var buf bytes.Buffer
+	contentType := run.Header.Get("Content-Type")
+	switch {
+	case dir != "":
+		path := filepath.Join(dir, run.Alias+bodyExt(contentType))
+		if err := os.WriteFile(path, run.Body, 0o644); err != nil {
+			fmt.Fprintf(out, "%s%v\n", prefix, err)
+			return
+		}
+		fmt.Fprintf(out, "%swrote %d bytes to %s\n", prefix, len(run.Body), path)
+		return
+	case bodyExt(contentType) == ".bin" && !utf8.Valid(run.Body):
+		fmt.Fprintf(out, "%s%d-byte binary body not printed; use --output-dir\n", prefix, len(run.Body))
+		return
+	}
+	resp := &http.Response{
+		Status:        statusLabel(run.Status),
+		StatusCode:    run.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        run.Header,
+		ContentLength: int64(len(run.Body)),
+		Body:          io.NopCloser(bytes.NewReader(run.Body)),
+		Request:       run.Request,
+	}
+	if err := output.Render(resp, &buf, opts); err != nil {
+		fmt.Fprintf(&buf, "\n%v\n", err)
+	}
+	if buf.Len() == 0 {
+		return
+	}
+	// lines can be any length, such as a minified body or a long header
+	for _, l := range bytes.Split(bytes.TrimSuffix(buf.Bytes(), []byte("\n")), []byte("\n")) {
+		fmt.Fprintln(out, prefix+string(bytes.TrimSuffix(l, []byte("\r"))))
+	}
+}
+
+// bodyExt picks a file extension for a response body from its media type.
+func bodyExt(contentType string) string {
+	mt, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mt == "application/json" || strings.HasSuffix(mt, "+json"):
+		return ".json"
+	case mt == "application/xml" || mt == "text/xml" || strings.HasSuffix(mt, "+xml"):
+		return ".xml"
+	case mt == "text/html":
+		return ".html"
+	case strings.HasPrefix(mt, "text/"):
+		return ".txt"
+	}
+	return ".bin"
+}
@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/suprbdev/reqo/internal/output"
+	"github.com/suprbdev/reqo/internal/snapshot"
+)
+
+// newSnapshotCmd records responses of saved calls and compares later runs
+// against them.
+func newSnapshotCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Record and compare responses of saved calls",
+		Long: `Snapshots are normalized responses (status, media type and body) kept
+under .reqo/snapshots/<env>/<alias>.json. Values that change on every run,
+like timestamps and ids, are left out with jq paths listed under
+"snapshot_ignore:" in the call or given with --ignore.`,
+	}
+
+	updateCmd := &cobra.Command{
+		Use:   "update <alias>",
+		Short: "Run a saved call and store its normalized response",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p, err := resolveProject(cmd)
+			if err != nil {
+				return err
+			}
+			snap, err := takeSnapshot(cmd, p, args[0])
+			if err != nil {
+				return err
+			}
+			path := snapshot.Path(p.Dir, snapshotEnv(cmd, p), args[0])
+			if err := snap.Save(path); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Saved snapshot of %s (%d) to %s\n", args[0], snap.Status, relPath(p.Dir, path))
+			return nil
+		},
+	}
+
+	checkCmd := &cobra.Command{
+		Use:   "check <alias>",
+		Short: "Run a saved call and compare its response with the snapshot",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			alias := args[0]
+			p, err := resolveProject(cmd)
+			if err != nil {
+				return err
+			}
+			path := snapshot.Path(p.Dir, snapshotEnv(cmd, p), alias)
+			want, err := snapshot.Load(path)
+			if errors.Is(err, fs.ErrNotExist) {
+				return fmt.Errorf("no snapshot of %s for environment %s; record one with 'reqo snapshot update %s'", alias, snapshotEnv(cmd, p), alias)
+			}
+			if err != nil {
+				return err
+			}
+			// the recorded snapshot may predate an ignore path
+			if err := want.Ignore(snapshotIgnore(cmd, p, alias)); err != nil {
+				return fmt.Errorf("snapshot %s: %w", relPath(p.Dir, path), err)
+			}
+			got, err := takeSnapshot(cmd, p, alias)
+			if err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			color := output.ColorEnabled(out, getBool(cmd, "no-color"))
+			changes := output.DiffJSON(want.Document(), got.Document())
+			if len(changes) == 0 {
+				output.WriteCheck(out, 0, true, alias+" matches "+relPath(p.Dir, path), color)
+				return nil
+			}
+			cmd.SilenceUsage = true
+			output.WriteCheck(out, 0, false, alias+" differs from "+relPath(p.Dir, path), color)
+			output.WriteDiff(out, changes, color)
+			return fmt.Errorf("response of %s does not match its snapshot (%s)", alias, plural(len(changes), "change"))
+		},
+	}
+
+	for _, c := range []*cobra.Command{updateCmd, checkCmd} {
+		c.Flags().StringArray("ignore", nil, "jq path left out of the snapshot, e.g. .created_at (repeatable, adds to snapshot_ignore)")
+		c.Flags().String("env", "", "environment to use")
+		c.Flags().StringArray("var", nil, "variables for template expansion (key=value)")
+		c.Flags().StringArray("header", nil, "extra header (Key: Value)")
+		c.Flags().Int("timeout", 30, "request timeout in seconds")
+		c.Flags().BoolP("insecure", "k", false, "skip TLS certificate verification")
+		cmd.AddCommand(c)
+	}
+	return cmd
+}
+
+// takeSnapshot runs a saved call and normalizes its response.
+func takeSnapshot(cmd *cobra.Command, p *projContext, alias string) (*snapshot.Snapshot, error) {
+	if _, ok := p.Project.Calls[alias]; !ok {
+		return nil, fmt.Errorf("call %q not defined in project %s", alias, p.Project.Name)
+	}
+	run := runCall(cmd, p, alias, parseVars(cmd))
+	if run.Err != nil {
+		return nil, run.Err
+	}
+	return snapshot.Normalize(run.Status, run.Header, run.Body, snapshotIgnore(cmd, p, alias))
+}
+
+// snapshotIgnore is the call's snapshot_ignore followed by the --ignore
+// paths.
+func snapshotIgnore(cmd *cobra.Command, p *projContext, alias string) []string {
+	return append(append([]string{}, p.Project.Calls[alias].SnapshotIgnore...), getStringArray(cmd, "ignore")...)
+}
+
+// snapshotEnv names the environment directory snapshots are kept in.
+func snapshotEnv(cmd *cobra.Command, p *projContext) string {
+	if env := envFlag(cmd); env != "" {
+		return env
+	}
+	if p.Project.DefaultEnv != "" {
+		return p.Project.DefaultEnv
+	}
+	return "default"
+}
+
+// relPath shows path relative to the project directory when possible.
+func relPath(dir, path string) string {
+	if rel, err := filepath.Rel(dir, path); err == nil {
+		return rel
+	}
+	return path
+}
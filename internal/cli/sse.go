@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/spf13/cobra"
+	httpx "github.com/suprbdev/reqo/internal/http"
+	"github.com/suprbdev/reqo/internal/output"
+)
+
+const (
+	// sseDefaultRetry is the reconnection delay used until the server sends
+	// a "retry:" field.
+	sseDefaultRetry = 3 * time.Second
+	// sseMaxFailures bounds consecutive reconnects that fail or deliver no
+	// events before --reconnect gives up.
+	sseMaxFailures = 5
+)
+
+// streamEvents prints a text/event-stream response as events arrive. With
+// --reconnect a closed stream is resumed by re-sending req with the
+// Last-Event-ID header, until --max-events is reached or the server answers
+// 204 No Content. A stream cut off by the timeout is an error.
+func streamEvents(ctx context.Context, cmd *cobra.Command, req *http.Request, resp *http.Response, execOpts httpx.ExecOpts) error {
+	out := cmd.OutOrStdout()
+	opts := output.SSEOpts{
+		JQExpr:    getString(cmd, "jq"),
+		MaxEvents: getInt(cmd, "max-events"),
+		Color:     output.ColorEnabled(out, getBool(cmd, "no-color")),
+	}
+	state := &output.SSEState{LastEventID: getString(cmd, "last-event-id")}
+	if getBool(cmd, "include") {
+		output.WriteHead(resp, out, opts.Color)
+	}
+
+	failures := 0
+	for {
+		before := state.Events
+		err := output.StreamSSE(resp.Body, out, opts, state)
+		resp.Body.Close()
+		if state.Done(opts) {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return streamCutOff(ctx)
+		}
+		if !getBool(cmd, "reconnect") {
+			if err != nil {
+				return fmt.Errorf("event stream: %w", err)
+			}
+			return nil
+		}
+		if state.Events > before {
+			failures = 0
+		} else {
+			failures++
+		}
+
+		for {
+			if failures >= sseMaxFailures {
+				return fmt.Errorf("event stream: giving up after %d reconnects without events", failures)
+			}
+			delay := state.Retry
+			if delay == 0 {
+				delay = sseDefaultRetry
+			}
+			select {
+			case <-ctx.Done():
+				return streamCutOff(ctx)
+			case <-time.After(delay):
+			}
+
+			r := req.Clone(ctx)
+			if req.GetBody != nil {
+				if r.Body, err = req.GetBody(); err != nil {
+					return err
+				}
+			}
+			if state.LastEventID != "" {
+				r.Header.Set("Last-Event-ID", state.LastEventID)
+			}
+			resp, err = httpx.Execute(ctx, nil, r, execOpts)
+			if ctx.Err() != nil {
+				return streamCutOff(ctx)
+			}
+			if err == nil && resp.StatusCode == http.StatusNoContent {
+				resp.Body.Close()
+				return nil // the server asked us to stop
+			}
+			if err == nil && resp.StatusCode == http.StatusOK && output.IsSSE(resp) {
+				httpx.DecodeResponse(resp, true)
+				break
+			}
+			if err == nil {
+				resp.Body.Close()
+			}
+			failures++
+		}
+	}
+}
+
+// streamCutOff reports an event stream ended by the --timeout deadline.
+func streamCutOff(ctx context.Context) error {
+	return fmt.Errorf("event stream cut off: %w", context.Cause(ctx))
+}
@@ -0,0 +1,285 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/suprbdev/reqo/internal/assert"
+	httpx "github.com/suprbdev/reqo/internal/http"
+	"github.com/suprbdev/reqo/internal/output"
+	"github.com/suprbdev/reqo/internal/project"
+	"github.com/suprbdev/reqo/internal/report"
+)
+
+// newTestCmd runs saved calls and checks their assertions.
+func newTestCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "test [alias...]",
+		Short: "Run saved calls and check their assertions",
+		Long: `Send saved calls and check the assertions listed under "assert:" in
+project.yaml. Without aliases every call with assertions is run, or with
+--tag every call carrying one of the tags. Exits non-zero when a request
+fails or an assertion does not hold. --report writes JUnit XML or TAP for
+CI, with timings, the redacted request as curl and, for failures, the start
+of the response body.`,
+		RunE: runTest,
+	}
+	cmd.Flags().StringArray("tag", nil, "run the calls with this tag (repeatable)")
+	cmd.Flags().StringArray("report", nil, "also write a report: junit=out.xml, tap or tap=out.tap (repeatable; no path means stdout)")
+	cmd.Flags().String("env", "", "environment to use")
+	cmd.Flags().StringArray("var", nil, "variables for template expansion (key=value)")
+	cmd.Flags().StringArray("header", nil, "extra header (Key: Value)")
+	cmd.Flags().Int("timeout", 30, "request timeout in seconds")
+	cmd.Flags().BoolP("insecure", "k", false, "skip TLS certificate verification")
+	return cmd
+}
+
+func runTest(cmd *cobra.Command, args []string) error {
+	p, err := resolveProject(cmd)
+	if err != nil {
+		return err
+	}
+	var targets []report.Target
+	toStdout := 0
+	for _, spec := range getStringArray(cmd, "report") {
+		t, err := report.ParseTarget(spec)
+		if err != nil {
+			return err
+		}
+		if t.Path == "" || t.Path == "-" {
+			t.Path = ""
+			toStdout++
+		}
+		targets = append(targets, t)
+	}
+	if toStdout > 1 {
+		return fmt.Errorf("only one --report can be written to standard output")
+	}
+	tags := getStringArray(cmd, "tag")
+	aliases, err := selectCalls(p.Project, args, tags, func(c project.Call) bool { return len(c.Assert) > 0 })
+	if err != nil {
+		return err
+	}
+	if len(aliases) == 0 {
+		if len(tags) > 0 {
+			return fmt.Errorf("no HTTP calls tagged %s", strings.Join(tags, ", "))
+		}
+		return fmt.Errorf("no saved calls have assertions")
+	}
+	cmd.SilenceUsage = true // failures below are test results, not usage errors
+
+	out := cmd.OutOrStdout()
+	if toStdout > 0 {
+		out = cmd.ErrOrStderr() // keep standard output parseable
+	}
+	color := output.ColorEnabled(out, getBool(cmd, "no-color"))
+	rep := report.Run{Name: p.Project.Name, Timestamp: time.Now()}
+	var failedCalls, checks, failedChecks int
+	for _, alias := range aliases {
+		run := runCallChecked(cmd, p, alias, parseVars(cmd))
+		writeCallRun(out, run, color)
+		rep.Cases = append(rep.Cases, reportCase(run))
+		checks += len(run.Results)
+		failedChecks += assert.Failed(run.Results)
+		if run.failed() {
+			failedCalls++
+		}
+	}
+	rep.Duration = time.Since(rep.Timestamp)
+	fmt.Fprintf(out, "\n%s: %d passed, %d failed (%s, %d failed)\n",
+		plural(len(aliases), "call"), len(aliases)-failedCalls, failedCalls, plural(checks, "assertion"), failedChecks)
+	for _, t := range targets {
+		if t.Path == "" {
+			err = t.Write(cmd.OutOrStdout(), rep)
+		} else {
+			err = t.WriteFile(rep)
+		}
+		if err != nil {
+			return fmt.Errorf("write %s report: %w", t.Format, err)
+		}
+	}
+	if failedCalls > 0 {
+		return fmt.Errorf("%d of %d calls failed", failedCalls, len(aliases))
+	}
+	return nil
+}
+
+// selectCalls returns the aliases named in args, or else the HTTP calls
+// carrying any of tags, or else the HTTP calls accepted by pick (all of
+// them when pick is nil), sorted.
+func selectCalls(p *project.Project, args, tags []string, pick func(project.Call) bool) ([]string, error) {
+	if len(args) > 0 && len(tags) > 0 {
+		return nil, fmt.Errorf("give either aliases or --tag, not both")
+	}
+	if len(args) > 0 {
+		for _, alias := range args {
+			if _, ok := p.Calls[alias]; !ok {
+				return nil, fmt.Errorf("call %q not defined in project %s", alias, p.Name)
+			}
+		}
+		return args, nil
+	}
+	var aliases []string
+	for alias, c := range p.Calls {
+		if c.Type != project.CallHTTP {
+			continue
+		}
+		if len(tags) > 0 {
+			for _, tag := range tags {
+				if c.HasTag(tag) {
+					aliases = append(aliases, alias)
+					break
+				}
+			}
+		} else if pick == nil || pick(c) {
+			aliases = append(aliases, alias)
+		}
+	}
+	sort.Strings(aliases)
+	return aliases, nil
+}
+
+// callRun is the outcome of sending a saved call and checking its
+// assertions.
+type callRun struct {
+	Alias    string
+	Request  *http.Request
+	Status   int
+	Header   http.Header
+	Body     []byte
+	Duration time.Duration // until the body was read
+	Results  []assert.Result
+	Err      error // the request could not be built, sent or read
+}
+
+func (r *callRun) failed() bool { return r.Err != nil || assert.Failed(r.Results) > 0 }
+
+// runCallChecked sends a saved HTTP call with the vars and the flags on
+// cmd and checks the call's assertions against the response.
+func runCallChecked(cmd *cobra.Command, p *projContext, alias string, vars map[string]string) *callRun {
+	run := runCall(cmd, p, alias, vars)
+	if run.Err != nil {
+		return run
+	}
+	run.Results = assert.Check(assert.Response{
+		StatusCode: run.Status,
+		Header:     run.Header,
+		Body:       run.Body,
+		Duration:   run.Duration,
+		Dir:        p.Dir,
+	}, assert.Expand(p.Project.Calls[alias].Assert, vars))
+	return run
+}
+
+// runCall sends a saved HTTP call with the vars and the flags on cmd and
+// reads the decoded response.
+func runCall(cmd *cobra.Command, p *projContext, alias string, vars map[string]string) *callRun {
+	return sendCall(cmd, p, alias, vars, false)
+}
+
+// runLoggedCall is runCall for the commands that log what they send to the
+// history, like call run.
+func runLoggedCall(cmd *cobra.Command, p *projContext, alias string, vars map[string]string) *callRun {
+	return sendCall(cmd, p, alias, vars, true)
+}
+
+func sendCall(cmd *cobra.Command, p *projContext, alias string, vars map[string]string, logged bool) *callRun {
+	run := &callRun{Alias: alias}
+	call := p.Project.Calls[alias]
+	if call.Type != project.CallHTTP {
+		run.Err = fmt.Errorf("%q is a %s call, not an HTTP call", alias, call.Type)
+		return run
+	}
+	req, err := buildCallRequest(cmd, p, alias, vars)
+	if err != nil {
+		run.Err = err
+		return run
+	}
+	run.Request = req
+	var hist *historyRecorder
+	if logged {
+		hist = startHistory(cmd, p, req, alias)
+		if hist != nil {
+			hist.decoded = true // the body is always decoded below
+		}
+		defer func() { hist.finish(cmd, run.Err) }()
+	}
+
+	timeout := time.Duration(getInt(cmd, "timeout")) * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	start := time.Now()
+	hist.sending()
+	resp, err := httpx.Execute(ctx, nil, req, httpx.ExecOpts{
+		Timeout:      timeout,
+		MaxRedirects: 10,
+		Insecure:     getBool(cmd, "insecure"),
+	})
+	if err != nil {
+		run.Err = fmt.Errorf("request failed: %w", err)
+		return run
+	}
+	defer resp.Body.Close()
+	hist.capture(resp, httpx.DecodeResponse(resp, true))
+	run.Status, run.Header = resp.StatusCode, resp.Header
+	run.Body, err = io.ReadAll(resp.Body)
+	run.Duration = time.Since(start)
+	if err != nil {
+		run.Err = fmt.Errorf("read response: %w", err)
+	}
+	return run
+}
+
+// writeCallRun prints a call and its assertions as pass/fail lines.
+func writeCallRun(out io.Writer, run *callRun, color bool) {
+	line := run.Alias
+	if run.Request != nil {
+		line += "  " + run.Request.Method + " " + run.Request.URL.Path
+	}
+	if run.Err != nil {
+		output.WriteCheck(out, 0, false, line+" – "+run.Err.Error(), color)
+		return
+	}
+	line += fmt.Sprintf(" → %d (%s)", run.Status, run.Duration.Round(time.Millisecond))
+	output.WriteCheck(out, 0, !run.failed(), line, color)
+	for _, r := range run.Results {
+		text := r.Name
+		if !r.Passed {
+			text += " – " + r.Message
+		}
+		output.WriteCheck(out, 1, r.Passed, text, color)
+	}
+}
+
+// reportCase converts a call run for the --report writers. The request is
+// always redacted; the response excerpt is kept only for failures.
+func reportCase(run *callRun) report.Case {
+	c := report.Case{Name: run.Alias, Duration: run.Duration, Status: run.Status}
+	if run.Request != nil {
+		c.Request, _ = httpx.AsCurlOpts(run.Request, httpx.CurlOpts{Redact: true})
+	}
+	if run.Err != nil {
+		c.Error = run.Err.Error()
+	}
+	for _, r := range run.Results {
+		c.Checks = append(c.Checks, report.Check{Name: r.Name, Passed: r.Passed, Message: r.Message})
+	}
+	if run.failed() {
+		c.Excerpt = report.Excerpt(run.Body)
+	}
+	return c
+}
+
+// plural formats a count with a noun, e.g. "1 call" or "3 calls".
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -0,0 +1,240 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/spf13/cobra"
+	httpx "github.com/suprbdev/reqo/internal/http"
+	"github.com/suprbdev/reqo/internal/output"
+	"github.com/suprbdev/reqo/internal/project"
+	"github.com/suprbdev/reqo/internal/template"
+)
+
+// wsCloseGrace is how long we wait for the server to answer our close frame.
+const wsCloseGrace = 5 * time.Second
+
+func newWSCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ws <path|alias>",
+		Short: "Open a WebSocket and exchange messages",
+		Long: `Connect to a WebSocket endpoint resolved like 'reqo req' (environment
+base URL with http→ws rewriting, header sets, template variables) or to a
+saved WebSocket call.
+
+Messages given with --send (or saved with the call) are sent on connect and
+the connection stays open for replies. Without them every line read from
+stdin is sent as a text message and the connection is closed at end of
+input. Received messages are printed one per line.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runWS,
+	}
+	cmd.Flags().StringArray("header", nil, "extra header (Key: Value)")
+	cmd.Flags().StringArray("query", nil, "extra query param (k=v)")
+	cmd.Flags().String("env", "", "environment to use")
+	cmd.Flags().StringArray("var", nil, "variables for template expansion (key=value)")
+	cmd.Flags().String("use-headers", "", "header set to apply")
+	cmd.Flags().StringArray("send", nil, "message to send after connecting (repeatable)")
+	cmd.Flags().String("jq", "", "jq expression applied to each JSON message")
+	cmd.Flags().Int("max-messages", 0, "close after receiving this many messages")
+	cmd.Flags().Int("timeout", 0, "close the connection after this many seconds (0 = never)")
+	cmd.Flags().BoolP("insecure", "k", false, "skip TLS certificate verification")
+	cmd.Flags().String("save", "", "save as a WebSocket call under this alias instead of connecting")
+	cmd.Flags().String("desc", "", "description for --save")
+	return cmd
+}
+
+func runWS(cmd *cobra.Command, args []string) error {
+	target := args[0]
+	pCtx, err := resolveProject(cmd)
+	if err != nil {
+		return err
+	}
+
+	if alias := getString(cmd, "save"); alias != "" {
+		if pCtx.Project.Calls == nil {
+			pCtx.Project.Calls = map[string]project.Call{}
+		}
+		call := project.Call{
+			Type:         project.CallWebSocket,
+			Path:         target,
+			Headers:      getStringArray(cmd, "header"),
+			UseHeaderSet: getString(cmd, "use-headers"),
+			Description:  getString(cmd, "desc"),
+			Send:         getStringArray(cmd, "send"),
+		}
+		for _, q := range getStringArray(cmd, "query") {
+			k, v, ok := strings.Cut(q, "=")
+			if !ok {
+				return fmt.Errorf("invalid query param %q – must be k=v", q)
+			}
+			if call.Query == nil {
+				call.Query = map[string]string{}
+			}
+			call.Query[k] = v
+		}
+		pCtx.Project.Calls[alias] = call
+		if err := project.Save(pCtx.Dir, pCtx.Project); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Saved WebSocket call %s\n", alias)
+		return nil
+	}
+
+	vars := parseVars(cmd)
+	var req *http.Request
+	var send []string
+	if call, ok := pCtx.Project.Calls[target]; ok {
+		if call.Type != project.CallWebSocket {
+			return fmt.Errorf("call %q is not a WebSocket call; run it with 'reqo call run %s'", target, target)
+		}
+		if getString(cmd, "use-headers") != "" {
+			return fmt.Errorf("--use-headers cannot override the header set of a saved call")
+		}
+		if req, err = buildCallRequest(cmd, pCtx, target, vars); err != nil {
+			return err
+		}
+		for _, m := range call.Send {
+			send = append(send, template.Expand(m, vars))
+		}
+	} else {
+		req, err = httpx.BuildRequest(pCtx.Project, httpx.RequestSpec{
+			Method:       http.MethodGet,
+			Path:         target,
+			QueryParams:  getStringArray(cmd, "query"),
+			Headers:      getStringArray(cmd, "header"),
+			UseHeaderSet: getString(cmd, "use-headers"),
+			Vars:         vars,
+			EnvName:      envFlag(cmd),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	send = append(send, getStringArray(cmd, "send")...)
+
+	ctx, cancel := context.Background(), context.CancelFunc(func() {})
+	if t := getInt(cmd, "timeout"); t > 0 {
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(t)*time.Second)
+	}
+	defer cancel()
+
+	conn, resp, err := httpx.DialWS(ctx, req, httpx.ExecOpts{
+		Timeout:  30 * time.Second,
+		Insecure: getBool(cmd, "insecure"),
+	})
+	if err != nil {
+		if resp != nil {
+			return fmt.Errorf("websocket handshake failed: %s", resp.Status)
+		}
+		return fmt.Errorf("websocket: %w", err)
+	}
+	defer conn.Close()
+
+	s := &wsSession{conn: conn}
+	for _, m := range send {
+		if err := s.write(websocket.TextMessage, []byte(m)); err != nil {
+			return fmt.Errorf("websocket send: %w", err)
+		}
+	}
+	if len(send) == 0 {
+		go s.forward(cmd.InOrStdin())
+	}
+
+	out := cmd.OutOrStdout()
+	done := make(chan error, 1)
+	go func() {
+		done <- s.receive(out, getString(cmd, "jq"), getInt(cmd, "max-messages"),
+			output.ColorEnabled(out, getBool(cmd, "no-color")))
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		s.close()
+		<-done
+		return nil
+	}
+}
+
+// wsSession serialises writes; gorilla/websocket allows one writer at a time.
+type wsSession struct {
+	conn    *websocket.Conn
+	mu      sync.Mutex
+	closing atomic.Bool
+}
+
+func (s *wsSession) write(kind int, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.conn.WriteMessage(kind, data)
+}
+
+// close starts the closing handshake; receive returns once the server
+// answers (or the read deadline passes).
+func (s *wsSession) close() {
+	if s.closing.Swap(true) {
+		return
+	}
+	msg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+	s.write(websocket.CloseMessage, msg)
+	s.conn.SetReadDeadline(time.Now().Add(wsCloseGrace))
+}
+
+// forward sends each line of r as a text message and closes the connection
+// at end of input.
+func (s *wsSession) forward(r io.Reader) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 16<<20)
+	for sc.Scan() {
+		if err := s.write(websocket.TextMessage, sc.Bytes()); err != nil {
+			return
+		}
+	}
+	s.close()
+}
+
+// receive prints incoming messages until the connection closes or max
+// messages have been shown.
+func (s *wsSession) receive(out io.Writer, jqExpr string, max int, color bool) error {
+	count := 0
+	for {
+		kind, data, err := s.conn.ReadMessage()
+		if err != nil {
+			if s.closing.Load() {
+				return nil // we hung up; anything after that is expected
+			}
+			var ce *websocket.CloseError
+			if errors.As(err, &ce) && (ce.Code == websocket.CloseNormalClosure || ce.Code == websocket.CloseGoingAway) {
+				return nil
+			}
+			if errors.As(err, &ce) {
+				return fmt.Errorf("websocket closed: %d %s", ce.Code, strings.TrimSpace(ce.Text))
+			}
+			return fmt.Errorf("websocket: %w", err)
+		}
+		shown := true
+		if kind == websocket.BinaryMessage && output.IsTerminal(out) {
+			fmt.Fprintf(out, "[binary message, %s]\n", output.HumanBytes(int64(len(data))))
+		} else if shown, err = output.WriteMessage(out, data, jqExpr, color); err != nil {
+			return err
+		}
+		if shown {
+			count++
+		}
+		if max > 0 && count >= max {
+			s.close()
+			return nil
+		}
+	}
+}
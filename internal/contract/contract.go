@@ -0,0 +1,252 @@
+// Package contract checks requests and responses against an OpenAPI 3
+// description of the API.
+package contract
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/suprbdev/reqo/internal/assert"
+)
+
+// Contract is a loaded OpenAPI 3 document.
+type Contract struct {
+	Path string
+	doc  *openapi3.T
+}
+
+// Load reads and validates the OpenAPI document at path (YAML or JSON);
+// relative "$ref"s are resolved against its directory.
+func Load(path string) (*Contract, error) {
+	loader := openapi3.NewLoader()
+	loader.IsExternalRefsAllowed = true
+	doc, err := loader.LoadFromFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("load contract %s: %w", path, err)
+	}
+	if err := doc.Validate(loader.Context); err != nil {
+		return nil, fmt.Errorf("invalid contract %s: %w", path, err)
+	}
+	return &Contract{Path: path, doc: doc}, nil
+}
+
+// Operation is the operation of the contract a request was matched to.
+type Operation struct {
+	Method string
+	Path   string // path template, e.g. /users/{id}
+	ID     string // operationId, if any
+
+	route  *routers.Route
+	params map[string]string
+}
+
+func (o *Operation) String() string {
+	if o.ID != "" {
+		return o.ID + " (" + o.Method + " " + o.Path + ")"
+	}
+	return o.Method + " " + o.Path
+}
+
+// NoOperationError is returned by Match when the contract declares no
+// operation for the request.
+type NoOperationError struct{ msg string }
+
+func (e *NoOperationError) Error() string { return e.msg }
+
+// Match finds the operation for req. Hosts are ignored: the request path
+// may sit under basePath (the path of the environment's base URL) or under
+// the path of any server the contract declares.
+func (c *Contract) Match(req *http.Request, basePath string) (*Operation, error) {
+	doc := *c.doc // the servers are replaced on a copy
+	doc.Servers = nil
+	for _, base := range c.basePaths(basePath) {
+		doc.Servers = append(doc.Servers, &openapi3.Server{URL: base})
+	}
+	router, err := gorillamux.NewRouter(&doc)
+	if err != nil {
+		return nil, fmt.Errorf("contract %s: %w", c.Path, err)
+	}
+	route, params, err := router.FindRoute(req)
+	switch {
+	case errors.Is(err, routers.ErrMethodNotAllowed):
+		return nil, &NoOperationError{fmt.Sprintf("contract %s has no %s operation for %s", c.Path, req.Method, req.URL.Path)}
+	case err != nil:
+		return nil, &NoOperationError{fmt.Sprintf("no operation in contract %s matches %s %s", c.Path, req.Method, req.URL.Path)}
+	}
+	return &Operation{
+		Method: route.Method,
+		Path:   route.Path,
+		ID:     route.Operation.OperationID,
+		route:  route,
+		params: params,
+	}, nil
+}
+
+// basePaths lists the path prefixes tried by Match, longest first.
+func (c *Contract) basePaths(basePath string) []string {
+	seen := map[string]bool{}
+	var out []string
+	add := func(p string) {
+		p = strings.TrimRight(p, "/")
+		if p != "" && !strings.HasPrefix(p, "/") {
+			p = "/" + p
+		}
+		if !seen[p] {
+			seen[p] = true
+			out = append(out, p)
+		}
+	}
+	add(basePath)
+	for _, s := range c.doc.Servers {
+		if p, err := s.BasePath(); err == nil {
+			add(p)
+		}
+	}
+	add("")
+	sort.SliceStable(out, func(i, j int) bool { return len(out[i]) > len(out[j]) })
+	return out
+}
+
+// Violation is one way a request or response breaks the contract.
+type Violation struct {
+	In      string // e.g. "query parameter limit", "request body at /email"
+	Message string
+}
+
+func (v Violation) String() string { return v.In + ": " + v.Message }
+
+// CheckRequest validates the parameters and body of req. The body is read
+// from req.GetBody, so req can still be sent afterwards.
+func (o *Operation) CheckRequest(req *http.Request) ([]Violation, error) {
+	in, err := o.input(req)
+	if err != nil {
+		return nil, err
+	}
+	err = openapi3filter.ValidateRequest(context.Background(), in)
+	return violations(err), nil
+}
+
+// CheckResponse validates a response to req: its status must be declared
+// (or covered by "default") and its headers and body must match.
+func (o *Operation) CheckResponse(req *http.Request, status int, header http.Header, body []byte) ([]Violation, error) {
+	in, err := o.input(req)
+	if err != nil {
+		return nil, err
+	}
+	in.Options.ExcludeRequestBody = true
+	err = openapi3filter.ValidateResponse(context.Background(), &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: in,
+		Status:                 status,
+		Header:                 header,
+		Body:                   io.NopCloser(bytes.NewReader(body)),
+		Options:                &openapi3filter.Options{IncludeResponseStatus: true, MultiError: true},
+	})
+	return violations(err), nil
+}
+
+// input prepares a validation input on a copy of req with a fresh body.
+func (o *Operation) input(req *http.Request) (*openapi3filter.RequestValidationInput, error) {
+	r := req.Clone(context.Background())
+	r.Body = http.NoBody
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		r.Body = body
+	}
+	return &openapi3filter.RequestValidationInput{
+		Request:    r,
+		PathParams: o.params,
+		Route:      o.route,
+		Options: &openapi3filter.Options{
+			MultiError:          true,
+			AuthenticationFunc:  openapi3filter.NoopAuthenticationFunc,
+			SkipSettingDefaults: true,
+		},
+	}, nil
+}
+
+// violations flattens the errors returned by the validators.
+func violations(err error) []Violation {
+	var out []Violation
+	var walk func(in string, err error)
+	walk = func(in string, err error) {
+		var (
+			reqE *openapi3filter.RequestError
+			resE *openapi3filter.ResponseError
+			se   *openapi3.SchemaError
+		)
+		switch {
+		case err == nil:
+		case isMulti(err):
+			for _, e := range err.(openapi3.MultiError) {
+				walk(in, e)
+			}
+		case errors.As(err, &reqE):
+			where := in
+			switch {
+			case reqE.Parameter != nil:
+				where = paramKind(reqE.Parameter.In) + " " + reqE.Parameter.Name
+			case reqE.RequestBody != nil:
+				where = "request body"
+			}
+			if reqE.Err == nil || !isSchemaError(reqE.Err) {
+				bare := *reqE // Error without the location prefix
+				bare.Parameter, bare.RequestBody = nil, nil
+				out = append(out, Violation{In: where, Message: bare.Error()})
+				return
+			}
+			walk(where, reqE.Err)
+		case errors.As(err, &resE):
+			where := "response"
+			if strings.HasPrefix(resE.Reason, "response body") {
+				where = "response body"
+			}
+			if resE.Err == nil || !isSchemaError(resE.Err) {
+				out = append(out, Violation{In: where, Message: resE.Error()})
+				return
+			}
+			walk(where, resE.Err)
+		case errors.As(err, &se):
+			where := in
+			if ptr := se.JSONPointer(); len(ptr) > 0 {
+				where += " at " + assert.JSONPointer(ptr)
+			}
+			out = append(out, Violation{In: where, Message: se.Reason})
+		default:
+			out = append(out, Violation{In: in, Message: err.Error()})
+		}
+	}
+	walk("request", err)
+	return out
+}
+
+// isMulti reports whether err is a list of errors, as returned in
+// MultiError mode.
+func isMulti(err error) bool {
+	_, ok := err.(openapi3.MultiError)
+	return ok
+}
+
+func isSchemaError(err error) bool {
+	var se *openapi3.SchemaError
+	return isMulti(err) || errors.As(err, &se)
+}
+
+func paramKind(in string) string {
+	if in == "header" || in == "cookie" {
+		return in
+	}
+	return in + " parameter"
+}
@@ -0,0 +1,135 @@
+package contract
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func loadUsers(t *testing.T) *Contract {
+	t.Helper()
+	c, err := Load("testdata/users.yaml")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	return c
+}
+
+func TestLoad_Errors(t *testing.T) {
+	if _, err := Load("testdata/missing.yaml"); err == nil || !strings.Contains(err.Error(), "load contract") {
+		t.Errorf("missing file: got %v", err)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	c := loadUsers(t)
+	tests := []struct {
+		method, url, base string
+		want              string
+	}{
+		{"GET", "http://localhost:8080/users", "", "listUsers (GET /users)"},
+		{"GET", "http://localhost:8080/v1/users", "", "listUsers (GET /users)"}, // server path
+		{"GET", "http://localhost:8080/api/users/5", "/api", "GET /users/{id}"}, // env base path
+		{"GET", "http://localhost:8080/users/me", "", "me (GET /users/me)"},     // literal beats template
+		{"POST", "http://localhost:8080/users", "", "createUser (POST /users)"},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(tt.method, tt.url, nil)
+		op, err := c.Match(req, tt.base)
+		if err != nil {
+			t.Errorf("%s %s: %v", tt.method, tt.url, err)
+			continue
+		}
+		if op.String() != tt.want {
+			t.Errorf("%s %s: got %s, want %s", tt.method, tt.url, op, tt.want)
+		}
+	}
+}
+
+func TestMatch_NoOperation(t *testing.T) {
+	c := loadUsers(t)
+	req, _ := http.NewRequest("GET", "http://localhost/nothing", nil)
+	if _, err := c.Match(req, ""); err == nil || !strings.Contains(err.Error(), "no operation in contract testdata/users.yaml matches GET /nothing") {
+		t.Errorf("unknown path: got %v", err)
+	}
+	req, _ = http.NewRequest("DELETE", "http://localhost/users", nil)
+	if _, err := c.Match(req, ""); err == nil || !strings.Contains(err.Error(), "has no DELETE operation for /users") {
+		t.Errorf("unknown method: got %v", err)
+	}
+}
+
+func TestCheckRequest(t *testing.T) {
+	c := loadUsers(t)
+	check := func(req *http.Request) []string {
+		t.Helper()
+		op, err := c.Match(req, "")
+		if err != nil {
+			t.Fatalf("Match: %v", err)
+		}
+		v, err := op.CheckRequest(req)
+		if err != nil {
+			t.Fatalf("CheckRequest: %v", err)
+		}
+		var out []string
+		for _, x := range v {
+			out = append(out, x.String())
+		}
+		return out
+	}
+
+	req, _ := http.NewRequest("GET", "http://localhost/users?limit=10", nil)
+	if v := check(req); len(v) != 0 {
+		t.Errorf("valid request: %v", v)
+	}
+	req, _ = http.NewRequest("GET", "http://localhost/users?limit=500", nil)
+	if v := check(req); len(v) != 1 || v[0] != "query parameter limit: number must be at most 100" {
+		t.Errorf("query: %v", v)
+	}
+	req, _ = http.NewRequest("GET", "http://localhost/users/abc", nil)
+	if v := check(req); len(v) != 1 || !strings.HasPrefix(v[0], "path parameter id: value abc") {
+		t.Errorf("path: %v", v)
+	}
+
+	req, _ = http.NewRequest("POST", "http://localhost/users", strings.NewReader(`{"name":5}`))
+	req.Header.Set("Content-Type", "application/json")
+	v := check(req)
+	want := []string{`request body at /name: value must be a string`, `request body at /email: property "email" is missing`}
+	if strings.Join(v, "\n") != strings.Join(want, "\n") {
+		t.Errorf("body:\n got %q\nwant %q", v, want)
+	}
+	// the body is left for sending
+	if b, _ := io.ReadAll(req.Body); string(b) != `{"name":5}` {
+		t.Errorf("body consumed: %q", b)
+	}
+}
+
+func TestCheckResponse(t *testing.T) {
+	c := loadUsers(t)
+	req, _ := http.NewRequest("GET", "http://localhost/users", nil)
+	op, err := c.Match(req, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	jsonHeader := http.Header{"Content-Type": {"application/json"}}
+
+	v, err := op.CheckResponse(req, 200, jsonHeader, []byte(`[{"id":1,"name":"Ada","email":"ada@example.com"}]`))
+	if err != nil || len(v) != 0 {
+		t.Errorf("valid response: %v %v", v, err)
+	}
+	v, _ = op.CheckResponse(req, 200, jsonHeader, []byte(`[{"id":"x","name":"Ada","email":"ada@example.com"}]`))
+	if len(v) != 1 || v[0].String() != "response body at /0/id: value must be an integer" {
+		t.Errorf("body: %v", v)
+	}
+	v, _ = op.CheckResponse(req, 404, http.Header{}, nil)
+	if len(v) != 1 || v[0].String() != "response: status is not supported" {
+		t.Errorf("status: %v", v)
+	}
+
+	req, _ = http.NewRequest("POST", "http://localhost/users", nil)
+	op, _ = c.Match(req, "")
+	v, _ = op.CheckResponse(req, 201, http.Header{}, nil)
+	if len(v) != 1 || v[0].String() != `response: response header "Location" missing` {
+		t.Errorf("header: %v", v)
+	}
+}
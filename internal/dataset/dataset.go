@@ -0,0 +1,135 @@
+// Package dataset reads the rows of a data-driven run from CSV, JSON or
+// JSON Lines files.
+package dataset
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Row maps column names to values, used as template vars.
+type Row map[string]string
+
+// Load reads the rows of a data file. The format follows the extension:
+// .csv has a header line naming the columns, .json holds an array of
+// objects and .jsonl one object per line. Non-string JSON values are kept
+// as JSON text, numbers as they are written.
+func Load(path string) ([]Row, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var rows []Row
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".csv":
+		rows, err = readCSV(f)
+	case ".json":
+		rows, err = readJSON(f)
+	case ".jsonl", ".ndjson":
+		rows, err = readJSONL(f)
+	default:
+		return nil, fmt.Errorf("data file %s: unknown format %q (supported: .csv, .json, .jsonl)", path, ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("data file %s: %w", path, err)
+	}
+	return rows, nil
+}
+
+func readCSV(r io.Reader) ([]Row, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	header[0] = strings.TrimPrefix(header[0], "\ufeff") // BOM from spreadsheet exports
+	var rows []Row
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		row := make(Row, len(header))
+		for i, col := range header {
+			row[col] = rec[i]
+		}
+		rows = append(rows, row)
+	}
+}
+
+func readJSON(r io.Reader) ([]Row, error) {
+	var objs []map[string]interface{}
+	if err := decode(r, &objs); err != nil {
+		return nil, fmt.Errorf("expected an array of objects: %w", err)
+	}
+	rows := make([]Row, len(objs))
+	for i, o := range objs {
+		rows[i] = toRow(o)
+	}
+	return rows, nil
+}
+
+func readJSONL(r io.Reader) ([]Row, error) {
+	var rows []Row
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for n := 1; sc.Scan(); n++ {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var o map[string]interface{}
+		if err := decode(bytes.NewReader(line), &o); err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		rows = append(rows, toRow(o))
+	}
+	return rows, sc.Err()
+}
+
+// decode reads one JSON value with numbers as json.Number, so large IDs
+// keep every digit.
+func decode(r io.Reader, v interface{}) error {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return fmt.Errorf("unexpected data after the JSON value")
+	}
+	return nil
+}
+
+func toRow(o map[string]interface{}) Row {
+	row := make(Row, len(o))
+	for k, v := range o {
+		switch v := v.(type) {
+		case string:
+			row[k] = v
+		case nil:
+			row[k] = ""
+		case json.Number:
+			row[k] = v.String()
+		default:
+			data, _ := json.Marshal(v)
+			row[k] = string(data)
+		}
+	}
+	return row
+}
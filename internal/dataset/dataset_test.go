@@ -0,0 +1,85 @@
+package dataset
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func write(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	want := []Row{
+		{"name": "Ada", "age": "36"},
+		{"name": "Alan, M.", "age": "41"},
+	}
+	tests := []struct{ name, content string }{
+		{"users.csv", "\ufeffname,age\nAda,36\n\"Alan, M.\",41\n"},
+		{"users.json", `[{"name":"Ada","age":36},{"name":"Alan, M.","age":41}]`},
+		{"users.jsonl", "{\"name\":\"Ada\",\"age\":36}\n\n{\"name\":\"Alan, M.\",\"age\":41}\n"},
+	}
+	for _, tt := range tests {
+		rows, err := Load(write(t, tt.name, tt.content))
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(rows, want) {
+			t.Errorf("%s: got %v", tt.name, rows)
+		}
+	}
+}
+
+func TestLoad_Values(t *testing.T) {
+	rows, err := Load(write(t, "v.json", `[{"tags":["a"],"active":true,"none":null}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Row{"tags": `["a"]`, "active": "true", "none": ""}); !reflect.DeepEqual(rows[0], want) {
+		t.Errorf("got %v", rows[0])
+	}
+}
+
+func TestLoad_LargeNumbers(t *testing.T) {
+	// above 2^53, where float64 would round them
+	want := Row{"id": "9007199254740993", "big": "123456789012345678901", "price": "1.50", "ref": `{"id":9007199254740993}`}
+	for name, content := range map[string]string{
+		"ids.json":  `[{"id":9007199254740993,"big":123456789012345678901,"price":1.50,"ref":{"id":9007199254740993}}]`,
+		"ids.jsonl": `{"id":9007199254740993,"big":123456789012345678901,"price":1.50,"ref":{"id":9007199254740993}}` + "\n",
+	} {
+		rows, err := Load(write(t, name, content))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !reflect.DeepEqual(rows[0], want) {
+			t.Errorf("%s: got %v", name, rows[0])
+		}
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	if _, err := Load(write(t, "x.txt", "a")); err == nil || !strings.Contains(err.Error(), "unknown format") {
+		t.Errorf("extension: %v", err)
+	}
+	if _, err := Load(write(t, "x.jsonl", "{}\nnope\n")); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("jsonl: %v", err)
+	}
+	if _, err := Load(write(t, "x.jsonl", "{} {}\n")); err == nil || !strings.Contains(err.Error(), "line 1: unexpected data") {
+		t.Errorf("jsonl trailing data: %v", err)
+	}
+	if _, err := Load(write(t, "x.csv", "a,b\n1\n")); err == nil {
+		t.Error("short CSV record should fail")
+	}
+	if _, err := Load(write(t, "x.json", `{"a":1}`)); err == nil || !strings.Contains(err.Error(), "array of objects") {
+		t.Errorf("json: %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+package grpcx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// Result carries the response metadata of a call.
+type Result struct {
+	Header  metadata.MD
+	Trailer metadata.MD
+}
+
+// NewRequest decodes JSON input into the request message of md. Empty input
+// yields an empty message.
+func NewRequest(md protoreflect.MethodDescriptor, input []byte) (proto.Message, error) {
+	msg := dynamicpb.NewMessage(md.Input())
+	if len(input) == 0 {
+		return msg, nil
+	}
+	if err := protojson.Unmarshal(input, msg); err != nil {
+		return nil, fmt.Errorf("invalid %s JSON: %w", md.Input().FullName(), err)
+	}
+	return msg, nil
+}
+
+// Invoke calls a unary or server‑streaming method, passing every response
+// message to fn as JSON. meta is sent as request metadata.
+func Invoke(ctx context.Context, conn grpc.ClientConnInterface, md protoreflect.MethodDescriptor, input []byte, meta metadata.MD, fn func(json []byte) error) (Result, error) {
+	var res Result
+	if md.IsStreamingClient() {
+		return res, fmt.Errorf("%s is a client or bidirectional streaming method, which is not supported", md.FullName())
+	}
+	req, err := NewRequest(md, input)
+	if err != nil {
+		return res, err
+	}
+	ctx = metadata.NewOutgoingContext(ctx, meta)
+
+	if !md.IsStreamingServer() {
+		resp := dynamicpb.NewMessage(md.Output())
+		err := conn.Invoke(ctx, FullMethod(md), req, resp, grpc.Header(&res.Header), grpc.Trailer(&res.Trailer))
+		if err != nil {
+			return res, err
+		}
+		return res, emit(resp, fn)
+	}
+
+	stream, err := conn.NewStream(ctx, &grpc.StreamDesc{ServerStreams: true}, FullMethod(md))
+	if err != nil {
+		return res, err
+	}
+	if err := stream.SendMsg(req); err != nil {
+		return res, err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return res, err
+	}
+	for {
+		resp := dynamicpb.NewMessage(md.Output())
+		err := stream.RecvMsg(resp)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			res.Header, _ = stream.Header()
+			res.Trailer = stream.Trailer()
+			return res, err
+		}
+		if err := emit(resp, fn); err != nil {
+			return res, err
+		}
+	}
+	res.Header, _ = stream.Header()
+	res.Trailer = stream.Trailer()
+	return res, nil
+}
+
+func emit(m proto.Message, fn func([]byte) error) error {
+	b, err := protojson.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return fn(b)
+}
@@ -0,0 +1,139 @@
+// Package grpcx invokes gRPC and gRPC-Web methods with JSON input, using
+// descriptors discovered through server reflection or loaded from .proto
+// files and descriptor sets.
+package grpcx
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jhump/protoreflect/desc/protoparse"
+	"github.com/jhump/protoreflect/grpcreflect"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// Source resolves service descriptors.
+type Source interface {
+	FindService(name string) (protoreflect.ServiceDescriptor, error)
+	ListServices() ([]string, error)
+}
+
+// ReflectionSource asks the server through the gRPC reflection service. The
+// returned close function releases the reflection stream.
+func ReflectionSource(ctx context.Context, conn grpc.ClientConnInterface) (Source, func()) {
+	c := grpcreflect.NewClientAuto(ctx, conn)
+	return reflectionSource{c}, c.Reset
+}
+
+type reflectionSource struct{ c *grpcreflect.Client }
+
+func (s reflectionSource) FindService(name string) (protoreflect.ServiceDescriptor, error) {
+	sd, err := s.c.ResolveService(name)
+	if err != nil {
+		return nil, fmt.Errorf("server reflection: %w", err)
+	}
+	return sd.UnwrapService(), nil
+}
+
+func (s reflectionSource) ListServices() ([]string, error) {
+	names, err := s.c.ListServices()
+	if err != nil {
+		return nil, fmt.Errorf("server reflection: %w", err)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// ProtoSource compiles .proto files, resolving imports against importPaths
+// (the current directory when empty).
+func ProtoSource(importPaths []string, files ...string) (Source, error) {
+	p := protoparse.Parser{ImportPaths: importPaths}
+	fds, err := p.ParseFiles(files...)
+	if err != nil {
+		return nil, fmt.Errorf("parse proto: %w", err)
+	}
+	s := fileSource{}
+	for _, fd := range fds {
+		s = append(s, fd.UnwrapFile())
+	}
+	return s, nil
+}
+
+// DescriptorSetSource loads a FileDescriptorSet as written by
+// `protoc --include_imports -o` or `buf build -o`.
+func DescriptorSetSource(path string) (Source, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(b, &set); err != nil {
+		return nil, fmt.Errorf("read descriptor set %s: %w", path, err)
+	}
+	files, err := protodesc.NewFiles(&set)
+	if err != nil {
+		return nil, fmt.Errorf("read descriptor set %s: %w", path, err)
+	}
+	s := fileSource{}
+	files.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		s = append(s, fd)
+		return true
+	})
+	return s, nil
+}
+
+type fileSource []protoreflect.FileDescriptor
+
+func (s fileSource) FindService(name string) (protoreflect.ServiceDescriptor, error) {
+	for _, fd := range s {
+		if sd := fd.Services().ByName(protoreflect.FullName(name).Name()); sd != nil && string(sd.FullName()) == name {
+			return sd, nil
+		}
+	}
+	return nil, fmt.Errorf("service %q not found in the given proto files", name)
+}
+
+func (s fileSource) ListServices() ([]string, error) {
+	var names []string
+	for _, fd := range s {
+		for i := 0; i < fd.Services().Len(); i++ {
+			names = append(names, string(fd.Services().Get(i).FullName()))
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// FindMethod resolves "pkg.Service/Method" (or "pkg.Service.Method").
+func FindMethod(src Source, name string) (protoreflect.MethodDescriptor, error) {
+	name = strings.TrimPrefix(name, "/")
+	svc, method, ok := strings.Cut(name, "/")
+	if !ok {
+		i := strings.LastIndex(name, ".")
+		if i < 0 {
+			return nil, fmt.Errorf("invalid method %q – expected package.Service/Method", name)
+		}
+		svc, method = name[:i], name[i+1:]
+	}
+	sd, err := src.FindService(svc)
+	if err != nil {
+		return nil, err
+	}
+	md := sd.Methods().ByName(protoreflect.Name(method))
+	if md == nil {
+		return nil, fmt.Errorf("service %s has no method %s", svc, method)
+	}
+	return md, nil
+}
+
+// FullMethod returns the ":path" form of md, e.g. "/pkg.Service/Method".
+func FullMethod(md protoreflect.MethodDescriptor) string {
+	return "/" + string(md.Parent().FullName()) + "/" + string(md.Name())
+}
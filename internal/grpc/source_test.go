@@ -0,0 +1,102 @@
+package grpcx
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+const healthProto = `syntax = "proto3";
+package grpc.health.v1;
+
+message HealthCheckRequest { string service = 1; }
+message HealthCheckResponse {
+  enum ServingStatus { UNKNOWN = 0; SERVING = 1; NOT_SERVING = 2; SERVICE_UNKNOWN = 3; }
+  ServingStatus status = 1;
+}
+service Health {
+  rpc Check(HealthCheckRequest) returns (HealthCheckResponse);
+  rpc Watch(HealthCheckRequest) returns (stream HealthCheckResponse);
+}
+`
+
+func TestReflectionSource_ListServices(t *testing.T) {
+	conn, _ := startServer(t)
+	src, closeSrc := ReflectionSource(context.Background(), conn)
+	defer closeSrc()
+	names, err := src.ListServices()
+	if err != nil {
+		t.Fatalf("ListServices() error: %v", err)
+	}
+	if !strings.Contains(strings.Join(names, ","), "grpc.health.v1.Health") {
+		t.Errorf("services = %v", names)
+	}
+}
+
+func TestProtoSource(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "health.proto"), []byte(healthProto), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	src, err := ProtoSource([]string{dir}, "health.proto")
+	if err != nil {
+		t.Fatalf("ProtoSource() error: %v", err)
+	}
+	md, err := FindMethod(src, "grpc.health.v1.Health/Watch")
+	if err != nil {
+		t.Fatalf("FindMethod() error: %v", err)
+	}
+	if !md.IsStreamingServer() || FullMethod(md) != "/grpc.health.v1.Health/Watch" {
+		t.Errorf("method = %s, streaming %v", FullMethod(md), md.IsStreamingServer())
+	}
+
+	// the compiled descriptors are enough to call a real server
+	conn, _ := startServer(t)
+	check, _ := FindMethod(src, "grpc.health.v1.Health.Check")
+	var out string
+	_, err = Invoke(context.Background(), conn, check, []byte(`{}`), nil, func(b []byte) error {
+		out = string(b)
+		return nil
+	})
+	if err != nil || !strings.Contains(out, "SERVING") {
+		t.Errorf("Invoke() = %q, %v", out, err)
+	}
+}
+
+func TestDescriptorSetSource(t *testing.T) {
+	set := &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{
+		protodesc.ToFileDescriptorProto(healthpb.File_grpc_health_v1_health_proto),
+	}}
+	b, err := proto.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "health.protoset")
+	if err := os.WriteFile(path, b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	src, err := DescriptorSetSource(path)
+	if err != nil {
+		t.Fatalf("DescriptorSetSource() error: %v", err)
+	}
+	names, _ := src.ListServices()
+	if len(names) != 1 || names[0] != "grpc.health.v1.Health" {
+		t.Errorf("services = %v", names)
+	}
+}
+
+func TestFindMethod_Errors(t *testing.T) {
+	src := fileSource{healthpb.File_grpc_health_v1_health_proto}
+	for _, name := range []string{"Check", "grpc.health.v1.Nope/Check", "grpc.health.v1.Health/Nope"} {
+		if _, err := FindMethod(src, name); err == nil {
+			t.Errorf("FindMethod(%q) should fail", name)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package grpcx
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Target turns an environment address into a dial target. "https://host"
+// and bare "host:port" use TLS, "http://host" is plaintext; missing ports
+// default to 443 and 80.
+func Target(addr string) (target string, plaintext bool, err error) {
+	if !strings.Contains(addr, "://") {
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			return "", false, fmt.Errorf("invalid gRPC address %q – use host:port or http(s)://host[:port]", addr)
+		}
+		return addr, false, nil
+	}
+	u, err := url.Parse(addr)
+	if err != nil {
+		return "", false, fmt.Errorf("invalid gRPC address %q: %w", addr, err)
+	}
+	port := u.Port()
+	switch u.Scheme {
+	case "http":
+		plaintext = true
+		if port == "" {
+			port = "80"
+		}
+	case "https":
+		if port == "" {
+			port = "443"
+		}
+	default:
+		return "", false, fmt.Errorf("invalid gRPC address %q – unsupported scheme %s", addr, u.Scheme)
+	}
+	return net.JoinHostPort(u.Hostname(), port), plaintext, nil
+}
+
+// Dial opens a client connection. insecureTLS skips certificate checks.
+func Dial(target string, plaintext, insecureTLS bool) (*grpc.ClientConn, error) {
+	creds := insecure.NewCredentials()
+	if !plaintext {
+		creds = credentials.NewTLS(&tls.Config{InsecureSkipVerify: insecureTLS})
+	}
+	return grpc.NewClient(target, grpc.WithTransportCredentials(creds))
+}
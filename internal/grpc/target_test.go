@@ -0,0 +1,30 @@
+package grpcx
+
+import "testing"
+
+func TestTarget(t *testing.T) {
+	tests := []struct {
+		addr      string
+		target    string
+		plaintext bool
+		wantErr   bool
+	}{
+		{"localhost:50051", "localhost:50051", false, false},
+		{"http://localhost:50051", "localhost:50051", true, false},
+		{"http://example.com", "example.com:80", true, false},
+		{"https://example.com", "example.com:443", false, false},
+		{"https://example.com:8443/api", "example.com:8443", false, false},
+		{"example.com", "", false, true},
+		{"ftp://example.com", "", false, true},
+	}
+	for _, tt := range tests {
+		target, plaintext, err := Target(tt.addr)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Target(%q) error = %v, wantErr %v", tt.addr, err, tt.wantErr)
+			continue
+		}
+		if target != tt.target || plaintext != tt.plaintext {
+			t.Errorf("Target(%q) = %q, %v; want %q, %v", tt.addr, target, plaintext, tt.target, tt.plaintext)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package grpcx
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	httpx "github.com/suprbdev/reqo/internal/http"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const (
+	webContentType  = "application/grpc-web+proto"
+	webTrailerFlag  = 0x80
+	webCompressFlag = 0x01
+)
+
+// InvokeWeb calls a unary or server‑streaming method over gRPC-Web. base
+// supplies the endpoint (its URL is the prefix the method path is appended
+// to) and the headers sent as metadata, typically from httpx.BuildRequest.
+func InvokeWeb(ctx context.Context, base *http.Request, md protoreflect.MethodDescriptor, input []byte, opts httpx.ExecOpts, fn func(json []byte) error) (Result, error) {
+	var res Result
+	if md.IsStreamingClient() {
+		return res, fmt.Errorf("%s is a client or bidirectional streaming method, which gRPC-Web does not support", md.FullName())
+	}
+	msg, err := NewRequest(md, input)
+	if err != nil {
+		return res, err
+	}
+	payload, err := proto.Marshal(msg)
+	if err != nil {
+		return res, err
+	}
+	frame := make([]byte, 5, 5+len(payload))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(payload)))
+	frame = append(frame, payload...)
+
+	u := *base.URL
+	u.Path = strings.TrimSuffix(u.Path, "/") + FullMethod(md)
+	u.RawPath = ""
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), bytes.NewReader(frame))
+	if err != nil {
+		return res, err
+	}
+	for k, v := range base.Header {
+		if http.CanonicalHeaderKey(k) != "Content-Type" {
+			req.Header[k] = v
+		}
+	}
+	req.Header.Set("Content-Type", webContentType)
+	req.Header.Set("Accept", webContentType)
+	req.Header.Set("X-Grpc-Web", "1")
+
+	resp, err := httpx.Execute(ctx, nil, req, opts)
+	if err != nil {
+		return res, err
+	}
+	defer resp.Body.Close()
+	res.Header = headerMD(resp.Header)
+	if resp.StatusCode != http.StatusOK {
+		return res, status.Errorf(httpCode(resp.StatusCode), "gRPC-Web: HTTP %s", resp.Status)
+	}
+
+	br := bufio.NewReader(resp.Body)
+	var hdr [5]byte
+	for {
+		if _, err := io.ReadFull(br, hdr[:]); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return res, fmt.Errorf("gRPC-Web: %w", err)
+		}
+		data := make([]byte, binary.BigEndian.Uint32(hdr[1:]))
+		if _, err := io.ReadFull(br, data); err != nil {
+			return res, fmt.Errorf("gRPC-Web: %w", err)
+		}
+		if hdr[0]&webCompressFlag != 0 {
+			return res, fmt.Errorf("gRPC-Web: compressed messages are not supported")
+		}
+		if hdr[0]&webTrailerFlag != 0 {
+			res.Trailer = parseTrailer(data)
+			continue
+		}
+		out := dynamicpb.NewMessage(md.Output())
+		if err := proto.Unmarshal(data, out); err != nil {
+			return res, fmt.Errorf("gRPC-Web: decode %s: %w", md.Output().FullName(), err)
+		}
+		if err := emit(out, fn); err != nil {
+			return res, err
+		}
+	}
+
+	// trailers‑only responses carry the status in the headers
+	st := res.Trailer
+	if len(st.Get("grpc-status")) == 0 {
+		st = res.Header
+	}
+	return res, statusFromMD(st)
+}
+
+// headerMD converts HTTP headers into lower‑case gRPC metadata.
+func headerMD(h http.Header) metadata.MD {
+	md := metadata.MD{}
+	for k, v := range h {
+		md.Append(k, v...)
+	}
+	return md
+}
+
+// parseTrailer decodes the "key: value\r\n" block of a trailer frame.
+func parseTrailer(b []byte) metadata.MD {
+	md := metadata.MD{}
+	for _, line := range strings.Split(string(b), "\r\n") {
+		if k, v, ok := strings.Cut(line, ":"); ok {
+			md.Append(strings.TrimSpace(k), strings.TrimSpace(v))
+		}
+	}
+	return md
+}
+
+func statusFromMD(md metadata.MD) error {
+	vals := md.Get("grpc-status")
+	if len(vals) == 0 {
+		return status.Error(codes.Unknown, "gRPC-Web: response has no grpc-status")
+	}
+	code, err := strconv.Atoi(vals[0])
+	if err != nil {
+		return status.Errorf(codes.Unknown, "gRPC-Web: invalid grpc-status %q", vals[0])
+	}
+	if code == 0 {
+		return nil
+	}
+	msg := ""
+	if m := md.Get("grpc-message"); len(m) > 0 {
+		msg, _ = url.PathUnescape(m[0])
+	}
+	return status.Error(codes.Code(code), msg)
+}
+
+// httpCode maps an HTTP failure to a gRPC code as the gRPC-Web spec does.
+func httpCode(s int) codes.Code {
+	switch s {
+	case http.StatusBadRequest:
+		return codes.Internal
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.Unimplemented
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return codes.Unavailable
+	default:
+		return codes.Unknown
+	}
+}
@@ -0,0 +1,92 @@
+package grpcx
+
+import (
+	"context"
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	httpx "github.com/suprbdev/reqo/internal/http"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func webFrame(flag byte, data []byte) []byte {
+	f := make([]byte, 5, 5+len(data))
+	f[0] = flag
+	binary.BigEndian.PutUint32(f[1:], uint32(len(data)))
+	return append(f, data...)
+}
+
+func TestInvokeWeb(t *testing.T) {
+	var got *http.Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		w.Header().Set("Content-Type", webContentType)
+		out, _ := proto.Marshal(&healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING})
+		w.Write(webFrame(0, out))
+		w.Write(webFrame(webTrailerFlag, []byte("grpc-status: 0\r\nx-done: yes\r\n")))
+	}))
+	defer srv.Close()
+
+	base, _ := http.NewRequest(http.MethodGet, srv.URL+"/rpc/", nil)
+	base.Header.Set("Authorization", "Bearer t")
+	md := healthpb.File_grpc_health_v1_health_proto.Services().Get(0).Methods().ByName("Check")
+	var out []string
+	res, err := InvokeWeb(context.Background(), base, md, []byte(`{"service":"x"}`), httpx.ExecOpts{Timeout: 5 * time.Second}, func(b []byte) error {
+		out = append(out, string(b))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("InvokeWeb() error: %v", err)
+	}
+	if got.URL.Path != "/rpc/grpc.health.v1.Health/Check" {
+		t.Errorf("path = %q", got.URL.Path)
+	}
+	if got.Header.Get("Authorization") != "Bearer t" || got.Header.Get("Content-Type") != webContentType {
+		t.Errorf("headers = %v", got.Header)
+	}
+	if len(out) != 1 || !strings.Contains(out[0], "SERVING") {
+		t.Errorf("responses = %v", out)
+	}
+	if v := res.Trailer.Get("x-done"); len(v) != 1 || v[0] != "yes" {
+		t.Errorf("trailer = %v", res.Trailer)
+	}
+}
+
+func TestInvokeWeb_Status(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// trailers‑only response
+		w.Header().Set("Content-Type", webContentType)
+		w.Header().Set("Grpc-Status", "5")
+		w.Header().Set("Grpc-Message", "unknown%20service")
+	}))
+	defer srv.Close()
+
+	base, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	md := healthpb.File_grpc_health_v1_health_proto.Services().Get(0).Methods().ByName("Check")
+	_, err := InvokeWeb(context.Background(), base, md, []byte(`{}`), httpx.ExecOpts{Timeout: 5 * time.Second}, func([]byte) error { return nil })
+	st, _ := status.FromError(err)
+	if st.Code() != codes.NotFound || st.Message() != "unknown service" {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestInvokeWeb_HTTPError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	base, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	md := healthpb.File_grpc_health_v1_health_proto.Services().Get(0).Methods().ByName("Check")
+	_, err := InvokeWeb(context.Background(), base, md, []byte(`{}`), httpx.ExecOpts{Timeout: 5 * time.Second}, func([]byte) error { return nil })
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("err = %v, want Unauthenticated", err)
+	}
+}
@@ -0,0 +1,60 @@
+package history
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Filter selects history entries; zero fields match everything.
+type Filter struct {
+	Method   string    // e.g. GET, case-insensitive
+	Status   string    // a code such as 404 or a class such as 4xx
+	Call     string    // saved call alias
+	Env      string    // environment name
+	Contains string    // substring of the URL
+	Failed   bool      // only requests without a response or with a 4xx/5xx status
+	Since    time.Time // sent at or after
+	Before   time.Time // sent before
+}
+
+// Validate checks the status pattern.
+func (f Filter) Validate() error {
+	if f.Status == "" {
+		return nil
+	}
+	ok := len(f.Status) == 3 && f.Status[0] >= '1' && f.Status[0] <= '5'
+	for _, c := range strings.ToLower(f.Status[1:]) {
+		ok = ok && (c == 'x' || c >= '0' && c <= '9')
+	}
+	if !ok {
+		return fmt.Errorf("invalid status %q (want e.g. 404 or 4xx)", f.Status)
+	}
+	return nil
+}
+
+// Match reports whether e passes every filter that is set.
+func (f Filter) Match(e Entry) bool {
+	switch {
+	case f.Method != "" && !strings.EqualFold(f.Method, e.Request.Method),
+		f.Call != "" && f.Call != e.Call,
+		f.Env != "" && f.Env != e.Env,
+		f.Contains != "" && !strings.Contains(e.Request.URL, f.Contains),
+		f.Failed && !e.Failed(),
+		!f.Since.IsZero() && e.Time.Before(f.Since),
+		!f.Before.IsZero() && !e.Time.Before(f.Before):
+		return false
+	}
+	return f.Status == "" || matchStatus(f.Status, e.Status)
+}
+
+// matchStatus compares a status with a pattern like 404 or 4xx.
+func matchStatus(pattern string, status int) bool {
+	code := fmt.Sprintf("%03d", status)
+	for i := 0; i < 3; i++ {
+		if c := pattern[i]; c != 'x' && c != 'X' && c != code[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,54 @@
+package history
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFilter_Match(t *testing.T) {
+	now := time.Now()
+	e := Entry{Time: now, Env: "dev", Call: "get-user", Status: 404, Request: Request{Method: "GET", URL: "http://h/users/7"}}
+	tests := []struct {
+		f    Filter
+		want bool
+	}{
+		{Filter{}, true},
+		{Filter{Method: "get", Env: "dev", Call: "get-user", Contains: "/users/"}, true},
+		{Filter{Method: "POST"}, false},
+		{Filter{Env: "prod"}, false},
+		{Filter{Call: "other"}, false},
+		{Filter{Contains: "/orders"}, false},
+		{Filter{Status: "404"}, true},
+		{Filter{Status: "4xx"}, true},
+		{Filter{Status: "2XX"}, false},
+		{Filter{Failed: true}, true},
+		{Filter{Since: now.Add(-time.Hour)}, true},
+		{Filter{Since: now.Add(time.Minute)}, false},
+		{Filter{Before: now}, false},
+		{Filter{Before: now.Add(time.Minute)}, true},
+	}
+	for _, tt := range tests {
+		if got := tt.f.Match(e); got != tt.want {
+			t.Errorf("%+v.Match = %v, want %v", tt.f, got, tt.want)
+		}
+	}
+	if (Filter{Failed: true}).Match(Entry{Status: 200}) {
+		t.Error("a 200 is not failed")
+	}
+	if !(Filter{Failed: true}).Match(Entry{Error: "refused"}) {
+		t.Error("an error is failed")
+	}
+}
+
+func TestFilter_Validate(t *testing.T) {
+	for _, s := range []string{"", "200", "4xx", "5XX", "20x"} {
+		if err := (Filter{Status: s}).Validate(); err != nil {
+			t.Errorf("%q: %v", s, err)
+		}
+	}
+	for _, s := range []string{"4", "600", "abc", "40404"} {
+		if err := (Filter{Status: s}).Validate(); err == nil {
+			t.Errorf("%q should be invalid", s)
+		}
+	}
+}
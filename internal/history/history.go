@@ -0,0 +1,224 @@
+// Package history keeps a log of the requests sent with reqo req and reqo
+// call run, one JSON entry per line, so they can be listed, shown and sent
+// again later.
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Entry is one request in the history and what came back.
+type Entry struct {
+	ID         int       `json:"id"`
+	Time       time.Time `json:"time"`
+	Project    string    `json:"project,omitempty"`
+	Env        string    `json:"env,omitempty"`
+	Call       string    `json:"call,omitempty"` // alias of a saved call, empty for reqo req
+	Request    Request   `json:"request"`
+	Status     int       `json:"status,omitempty"` // 0 when no response arrived
+	DurationMS int64     `json:"duration_ms"`
+	Size       int64     `json:"size"` // bytes of the response body
+	Error      string    `json:"error,omitempty"`
+
+	Response          string `json:"response,omitempty"` // start of the response body, see project.HistorySettings
+	ResponseTruncated bool   `json:"response_truncated,omitempty"`
+}
+
+// Failed reports whether the request got no response or a 4xx/5xx status.
+func (e Entry) Failed() bool { return e.Error != "" || e.Status >= 400 }
+
+// Path is the history file of the project in dir: .reqo/history.jsonl.
+func Path(projectDir string) string {
+	return filepath.Join(projectDir, ".reqo", "history.jsonl")
+}
+
+// GlobalPath is the history file shared by all projects:
+// ~/.reqo/history.jsonl.
+func GlobalPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".reqo", "history.jsonl"), nil
+}
+
+// appendMu serialises appends from concurrent requests, such as the calls
+// of call run-many, so each gets its own id.
+var appendMu sync.Mutex
+
+// Append gives e the next free id and adds it to the history at path. The
+// directory of path gets a .gitignore listing the file, so a project's
+// history is not committed along with .reqo/project.yaml.
+func Append(path string, e *Entry) error {
+	appendMu.Lock()
+	defer appendMu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	if err := ignoreInGit(path); err != nil {
+		return err
+	}
+	// requests and responses may hold personal data
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	last, err := lastID(f)
+	if err != nil {
+		return fmt.Errorf("read history %s: %w", path, err)
+	}
+	e.ID = last + 1
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// ignoreInGit adds the name of path to the .gitignore beside it unless it
+// is listed there already.
+func ignoreInGit(path string) error {
+	ignore := filepath.Join(filepath.Dir(path), ".gitignore")
+	name := filepath.Base(path)
+	data, err := os.ReadFile(ignore)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if l := strings.TrimSpace(line); l == name || l == "/"+name {
+			return nil
+		}
+	}
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		name = "\n" + name
+	}
+	f, err := os.OpenFile(ignore, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.WriteString(name + "\n")
+	return err
+}
+
+// lastID reads the id of the last entry from the end of f, so appending
+// does not get slower as the history grows.
+func lastID(f *os.File) (int, error) {
+	fi, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	const chunk = 4096
+	var buf []byte
+	for off := fi.Size(); off > 0; {
+		n := min(off, chunk)
+		off -= n
+		part := make([]byte, n)
+		if _, err := f.ReadAt(part, off); err != nil {
+			return 0, err
+		}
+		buf = append(part, buf...)
+		line := bytes.TrimRight(buf, "\n")
+		if i := bytes.LastIndexByte(line, '\n'); i >= 0 || off == 0 {
+			var last struct {
+				ID int `json:"id"`
+			}
+			if err := json.Unmarshal(line[i+1:], &last); err != nil {
+				return 0, err
+			}
+			return last.ID, nil
+		}
+	}
+	return 0, nil
+}
+
+// Load reads the history at path, oldest first. A missing file is an empty
+// history.
+func Load(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var entries []Entry
+	r := bufio.NewReader(f)
+	for n := 1; ; n++ {
+		line, err := r.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			var e Entry
+			if err := json.Unmarshal(line, &e); err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", path, n, err)
+			}
+			entries = append(entries, e)
+		}
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Find returns the entry with the id.
+func Find(path string, id int) (*Entry, error) {
+	entries, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		if entries[i].ID == id {
+			return &entries[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no history entry %d in %s", id, path)
+}
+
+// Remove deletes the entries matched by match and returns how many there
+// were. The file is removed when no entry is left.
+func Remove(path string, match func(Entry) bool) (int, error) {
+	entries, err := Load(path)
+	if err != nil {
+		return 0, err
+	}
+	var keep bytes.Buffer
+	removed := 0
+	for _, e := range entries {
+		if match(e) {
+			removed++
+			continue
+		}
+		data, err := json.Marshal(e)
+		if err != nil {
+			return 0, err
+		}
+		keep.Write(append(data, '\n'))
+	}
+	switch {
+	case removed == 0:
+		return 0, nil
+	case keep.Len() == 0:
+		return removed, os.Remove(path)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, keep.Bytes(), 0o600); err != nil {
+		return 0, err
+	}
+	return removed, os.Rename(tmp, path)
+}
@@ -0,0 +1,102 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAppendLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := Path(dir)
+	if want := filepath.Join(dir, ".reqo", "history.jsonl"); path != want {
+		t.Errorf("Path = %s, want %s", path, want)
+	}
+	if entries, err := Load(path); err != nil || entries != nil {
+		t.Fatalf("missing file: %v %v", entries, err)
+	}
+
+	// entries longer than the chunk lastID reads at a time
+	big := strings.Repeat("x", 10000)
+	for i := 0; i < 3; i++ {
+		e := &Entry{Time: time.Now(), Request: Request{Method: "GET", URL: "http://h/"}, Status: 200, Response: big}
+		if err := Append(path, e); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+		if e.ID != i+1 {
+			t.Errorf("entry %d got id %d", i+1, e.ID)
+		}
+	}
+	entries, err := Load(path)
+	if err != nil || len(entries) != 3 {
+		t.Fatalf("Load: %d entries, %v", len(entries), err)
+	}
+	if entries[2].ID != 3 || entries[2].Response != big {
+		t.Errorf("last entry = %+v", entries[2].ID)
+	}
+	if fi, _ := os.Stat(path); fi.Mode().Perm() != 0o600 {
+		t.Errorf("history mode = %v", fi.Mode())
+	}
+
+	// listed once, however many entries are appended
+	if data, _ := os.ReadFile(filepath.Join(dir, ".reqo", ".gitignore")); string(data) != "history.jsonl\n" {
+		t.Errorf(".gitignore = %q", data)
+	}
+
+	if e, err := Find(path, 2); err != nil || e.ID != 2 {
+		t.Errorf("Find(2) = %v, %v", e, err)
+	}
+	if _, err := Find(path, 9); err == nil || !strings.Contains(err.Error(), "no history entry 9") {
+		t.Errorf("Find(9): %v", err)
+	}
+}
+
+func TestAppend_KeepsGitignore(t *testing.T) {
+	dir := t.TempDir()
+	ignore := filepath.Join(dir, ".gitignore")
+	os.WriteFile(ignore, []byte("snapshots/tmp"), 0o644)
+	if err := Append(filepath.Join(dir, "history.jsonl"), &Entry{}); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	if data, _ := os.ReadFile(ignore); string(data) != "snapshots/tmp\nhistory.jsonl\n" {
+		t.Errorf(".gitignore = %q", data)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	path := Path(t.TempDir())
+	for _, status := range []int{200, 404, 500} {
+		Append(path, &Entry{Request: Request{Method: "GET", URL: "http://h/"}, Status: status})
+	}
+	n, err := Remove(path, func(e Entry) bool { return e.Failed() })
+	if err != nil || n != 2 {
+		t.Fatalf("Remove = %d, %v", n, err)
+	}
+	entries, _ := Load(path)
+	if len(entries) != 1 || entries[0].ID != 1 {
+		t.Errorf("left %+v", entries)
+	}
+	// ids keep counting from the last entry
+	e := &Entry{}
+	Append(path, e)
+	if e.ID != 2 {
+		t.Errorf("next id = %d", e.ID)
+	}
+
+	if n, err := Remove(path, func(Entry) bool { return true }); err != nil || n != 2 {
+		t.Fatalf("Remove all = %d, %v", n, err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("empty history should be removed: %v", err)
+	}
+}
+
+func TestLoad_Corrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	os.WriteFile(path, []byte("{\"id\":1}\nnot json\n"), 0o600)
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), ":2:") {
+		t.Errorf("expected an error on line 2, got %v", err)
+	}
+}
@@ -0,0 +1,202 @@
+package history
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	httpx "github.com/suprbdev/reqo/internal/http"
+)
+
+// MaxRequestBody is the largest request body kept in an entry.
+const MaxRequestBody = 64 << 10
+
+// Request is a request as it was sent, with credentials in headers, query
+// parameters and form or JSON bodies replaced by httpx.RedactedValue.
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+
+	BodyFile     string   `json:"body_file,omitempty"`     // file streamed as the body (--data-binary @file)
+	BodyOmitted  string   `json:"body_omitted,omitempty"`  // why a body was sent but not kept
+	BodyRedacted []string `json:"body_redacted,omitempty"` // masked body fields, e.g. password or auth.api_key
+}
+
+// NewRequest records req. Its body is read through GetBody, so req can
+// still be sent afterwards.
+func NewRequest(req *http.Request) Request {
+	red := httpx.RedactRequest(req)
+	r := Request{Method: red.Method, URL: red.URL.String()}
+	if len(red.Header) > 0 {
+		r.Header = red.Header
+	}
+	switch file := httpx.BinaryFile(req); {
+	case file == "-":
+		r.BodyOmitted = "read from stdin"
+	case file != "":
+		r.BodyFile = file
+	case httpx.FormParts(req) != nil:
+		r.BodyOmitted = "multipart form"
+	case req.GetBody != nil:
+		body, err := req.GetBody()
+		if err != nil {
+			r.BodyOmitted = err.Error()
+			break
+		}
+		defer body.Close()
+		data, err := io.ReadAll(io.LimitReader(body, MaxRequestBody+1))
+		switch {
+		case err != nil:
+			r.BodyOmitted = err.Error()
+		case len(data) > MaxRequestBody:
+			r.BodyOmitted = "larger than 64 KB"
+		case !utf8.Valid(data):
+			r.BodyOmitted = "binary"
+		default:
+			r.Body, r.BodyRedacted = redactBody(req.Header.Get("Content-Type"), data)
+		}
+	case req.Body != nil && req.Body != http.NoBody:
+		r.BodyOmitted = "streamed"
+	}
+	return r
+}
+
+// redactBody masks the values of sensitive fields of a JSON or URL-encoded
+// body and returns the masked fields, sorted; other bodies are kept as they
+// are.
+func redactBody(contentType string, data []byte) (string, []string) {
+	mt, _, _ := mime.ParseMediaType(contentType)
+	var fields []string
+	switch {
+	case mt == "application/x-www-form-urlencoded":
+		form, err := url.ParseQuery(string(data))
+		if err != nil {
+			return string(data), nil
+		}
+		for k, vals := range form {
+			if httpx.IsSensitive(k) {
+				for i := range vals {
+					vals[i] = httpx.RedactedValue
+				}
+				fields = append(fields, k)
+			}
+		}
+		if len(fields) > 0 {
+			sort.Strings(fields)
+			return form.Encode(), fields
+		}
+	case mt == "application/json" || strings.HasSuffix(mt, "+json"):
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		var doc interface{}
+		if dec.Decode(&doc) != nil {
+			return string(data), nil
+		}
+		if redactJSON(doc, "", &fields); len(fields) == 0 {
+			return string(data), nil
+		}
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		if enc.Encode(doc) == nil {
+			sort.Strings(fields)
+			return strings.TrimSuffix(buf.String(), "\n"), fields
+		}
+	}
+	return string(data), nil
+}
+
+// redactJSON masks sensitive object members in place and adds their
+// dotted paths below prefix to fields.
+func redactJSON(v interface{}, prefix string, fields *[]string) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, val := range v {
+			if httpx.IsSensitive(k) {
+				if _, isObj := val.(map[string]interface{}); !isObj {
+					v[k] = httpx.RedactedValue
+					*fields = append(*fields, prefix+k)
+					continue
+				}
+			}
+			redactJSON(val, prefix+k+".", fields)
+		}
+	case []interface{}:
+		for i, val := range v {
+			redactJSON(val, fmt.Sprintf("%s%d.", prefix, i), fields)
+		}
+	}
+}
+
+// Redacted lists the headers and query parameters whose values were masked,
+// sorted. Masked body fields are in BodyRedacted.
+func (r Request) Redacted() []string {
+	var names []string
+	for k, vals := range r.Header {
+		for _, v := range vals {
+			if strings.Contains(v, httpx.RedactedValue) {
+				names = append(names, k)
+				break
+			}
+		}
+	}
+	if u, err := url.Parse(r.URL); err == nil {
+		for k, vals := range u.Query() {
+			for _, v := range vals {
+				if v == httpx.RedactedValue {
+					names = append(names, "?"+k)
+					break
+				}
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// HTTPRequest builds the request again for a replay.
+func (r Request) HTTPRequest() (*http.Request, error) {
+	if r.BodyOmitted != "" {
+		return nil, fmt.Errorf("the request body was not recorded (%s)", r.BodyOmitted)
+	}
+	var body io.Reader
+	var size int64
+	switch {
+	case r.BodyFile != "":
+		f, err := os.Open(r.BodyFile)
+		if err != nil {
+			return nil, err
+		}
+		if fi, err := f.Stat(); err == nil {
+			size = fi.Size()
+		}
+		body = f
+	case r.Body != "":
+		body = strings.NewReader(r.Body)
+	}
+	req, err := http.NewRequest(r.Method, r.URL, body)
+	if err != nil {
+		if f, ok := body.(*os.File); ok {
+			f.Close()
+		}
+		return nil, err
+	}
+	if r.BodyFile != "" {
+		req.ContentLength = size
+	}
+	req.Header = r.Header.Clone()
+	if req.Header == nil {
+		req.Header = http.Header{}
+	}
+	return req, nil
+}
@@ -0,0 +1,110 @@
+package history
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	httpx "github.com/suprbdev/reqo/internal/http"
+	"github.com/suprbdev/reqo/internal/project"
+)
+
+func build(t *testing.T, spec httpx.RequestSpec) *http.Request {
+	t.Helper()
+	p := &project.Project{Environments: map[string]project.Environment{"dev": {BaseURL: "https://api.example.com"}}}
+	spec.EnvName = "dev"
+	req, err := httpx.BuildRequest(p, spec)
+	if err != nil {
+		t.Fatalf("BuildRequest: %v", err)
+	}
+	return req
+}
+
+func TestNewRequest_Redacts(t *testing.T) {
+	body := `{"user":"ada","password":"hunter2","auth":{"api_key":"k1"},"n":1.50}`
+	req := build(t, httpx.RequestSpec{
+		Method:      "POST",
+		Path:        "/login",
+		QueryParams: []string{"access_token=abc", "page=2"},
+		Headers:     []string{"Authorization: Bearer s3cr3t", "X-Trace: 1"},
+		JSONBody:    &body,
+	})
+	r := NewRequest(req)
+	if r.Method != "POST" || !strings.Contains(r.URL, "access_token=REDACTED") || !strings.Contains(r.URL, "page=2") {
+		t.Errorf("URL = %s %s", r.Method, r.URL)
+	}
+	if got := r.Header.Get("Authorization"); got != "Bearer REDACTED" {
+		t.Errorf("Authorization = %q", got)
+	}
+	want := `{"auth":{"api_key":"REDACTED"},"n":1.50,"password":"REDACTED","user":"ada"}`
+	if r.Body != want {
+		t.Errorf("Body = %s, want %s", r.Body, want)
+	}
+	if got := r.Redacted(); !reflect.DeepEqual(got, []string{"?access_token", "Authorization"}) {
+		t.Errorf("Redacted = %v", got)
+	}
+	if want := []string{"auth.api_key", "password"}; !reflect.DeepEqual(r.BodyRedacted, want) {
+		t.Errorf("BodyRedacted = %v, want %v", r.BodyRedacted, want)
+	}
+	// the request can still be sent
+	data, _ := io.ReadAll(req.Body)
+	if string(data) != body {
+		t.Errorf("request body consumed: %q", data)
+	}
+}
+
+func TestNewRequest_Bodies(t *testing.T) {
+	form := build(t, httpx.RequestSpec{Method: "POST", Path: "/", URLEncoded: []string{"user=ada", "password=x"}})
+	if got := NewRequest(form); got.Body != "password=REDACTED&user=ada" || !reflect.DeepEqual(got.BodyRedacted, []string{"password"}) {
+		t.Errorf("form body = %q, redacted %v", got.Body, got.BodyRedacted)
+	}
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "blob.bin")
+	os.WriteFile(file, []byte{0, 1, 2}, 0o644)
+	bin := "@" + file
+	r := NewRequest(build(t, httpx.RequestSpec{Method: "PUT", Path: "/", BinaryBody: &bin}))
+	if r.BodyFile != file || r.Body != "" {
+		t.Errorf("binary body = %+v", r)
+	}
+
+	multipart := NewRequest(build(t, httpx.RequestSpec{Method: "POST", Path: "/", Form: []project.FormField{{Name: "a", Value: "1"}}}))
+	if multipart.BodyOmitted != "multipart form" {
+		t.Errorf("multipart = %+v", multipart)
+	}
+	if _, err := multipart.HTTPRequest(); err == nil || !strings.Contains(err.Error(), "not recorded (multipart form)") {
+		t.Errorf("replaying multipart: %v", err)
+	}
+
+	large := strings.Repeat("a", MaxRequestBody+1)
+	if r := NewRequest(build(t, httpx.RequestSpec{Method: "POST", Path: "/", RawBody: &large})); r.BodyOmitted == "" || r.Body != "" {
+		t.Errorf("large body kept: %q", r.BodyOmitted)
+	}
+}
+
+func TestRequest_HTTPRequest(t *testing.T) {
+	r := Request{Method: "POST", URL: "https://api.example.com/x?a=1", Header: http.Header{"X-A": {"1"}}, Body: "hello"}
+	req, err := r.HTTPRequest()
+	if err != nil {
+		t.Fatalf("HTTPRequest: %v", err)
+	}
+	data, _ := io.ReadAll(req.Body)
+	if req.Method != "POST" || req.URL.String() != r.URL || req.Header.Get("X-A") != "1" || string(data) != "hello" {
+		t.Errorf("got %s %s %v %q", req.Method, req.URL, req.Header, data)
+	}
+
+	file := filepath.Join(t.TempDir(), "body.txt")
+	os.WriteFile(file, []byte("streamed"), 0o644)
+	req, err = Request{Method: "PUT", URL: "https://h/", BodyFile: file}.HTTPRequest()
+	if err != nil || req.ContentLength != 8 {
+		t.Fatalf("file body: %v, length %d", err, req.ContentLength)
+	}
+	req.Body.Close()
+	if req.Header == nil {
+		t.Error("header should not be nil")
+	}
+}
@@ -0,0 +1,131 @@
+package httpx
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// streamBody is a request body assembled from literal bytes and files. Files
+// are opened only while they are read, so large uploads are never held in
+// memory and the exact Content-Length is known up front.
+type streamBody struct {
+	parts []bodyPart
+	size  int64
+}
+
+type bodyPart struct {
+	data []byte
+	file string // read from this path when set
+}
+
+func (b *streamBody) addBytes(p []byte) {
+	if len(p) == 0 {
+		return
+	}
+	b.parts = append(b.parts, bodyPart{data: append([]byte(nil), p...)})
+	b.size += int64(len(p))
+}
+
+func (b *streamBody) addFile(path string) error {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if !fi.Mode().IsRegular() {
+		return fmt.Errorf("%s is not a regular file", path)
+	}
+	b.parts = append(b.parts, bodyPart{file: path})
+	b.size += fi.Size()
+	return nil
+}
+
+// reader returns a fresh reader over the whole body; it is also used as the
+// request's GetBody.
+func (b *streamBody) reader() io.ReadCloser {
+	return &partsReader{parts: b.parts}
+}
+
+type partsReader struct {
+	parts []bodyPart
+	cur   io.Reader
+	file  *os.File
+}
+
+func (r *partsReader) Read(p []byte) (int, error) {
+	for {
+		if r.cur == nil {
+			if len(r.parts) == 0 {
+				return 0, io.EOF
+			}
+			part := r.parts[0]
+			r.parts = r.parts[1:]
+			if part.file == "" {
+				r.cur = bytes.NewReader(part.data)
+				continue
+			}
+			f, err := os.Open(part.file)
+			if err != nil {
+				return 0, err
+			}
+			r.file, r.cur = f, f
+		}
+		n, err := r.cur.Read(p)
+		if err == io.EOF {
+			r.closeFile()
+			r.cur = nil
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+		return n, err
+	}
+}
+
+func (r *partsReader) Close() error {
+	r.closeFile()
+	r.parts = nil
+	return nil
+}
+
+func (r *partsReader) closeFile() {
+	if r.file != nil {
+		r.file.Close()
+		r.file = nil
+	}
+}
+
+type binaryFileKey struct{}
+
+// BinaryFile returns the path of the file BuildRequest streams as the body
+// of req ("-" for standard input), or "" when the body is not a binary
+// upload.
+func BinaryFile(req *http.Request) string {
+	path, _ := req.Context().Value(binaryFileKey{}).(string)
+	return path
+}
+
+// binaryContentType guesses a Content-Type from the file extension.
+func binaryContentType(path string) string {
+	if ct := mime.TypeByExtension(filepath.Ext(path)); ct != "" {
+		return ct
+	}
+	return "application/octet-stream"
+}
+
+// withStreamBody installs b as the body of req with its exact length.
+func withStreamBody(req *http.Request, b *streamBody) {
+	if b.size == 0 {
+		req.Body, req.ContentLength = http.NoBody, 0
+		req.GetBody = func() (io.ReadCloser, error) { return http.NoBody, nil }
+		return
+	}
+	req.Body = b.reader()
+	req.ContentLength = b.size
+	req.GetBody = func() (io.ReadCloser, error) { return b.reader(), nil }
+}
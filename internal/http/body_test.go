@@ -0,0 +1,58 @@
+package httpx
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStreamBody_Reader(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "part.bin")
+	os.WriteFile(path, []byte("FILE"), 0o644)
+
+	b := &streamBody{}
+	b.addBytes([]byte("head-"))
+	if err := b.addFile(path); err != nil {
+		t.Fatal(err)
+	}
+	b.addBytes(nil)
+	b.addBytes([]byte("-tail"))
+	if b.size != 14 {
+		t.Errorf("size = %d, want 14", b.size)
+	}
+	// every reader starts from the beginning, as GetBody requires
+	for i := 0; i < 2; i++ {
+		r := b.reader()
+		got, err := io.ReadAll(r)
+		r.Close()
+		if err != nil || string(got) != "head-FILE-tail" {
+			t.Errorf("read %d = %q, %v", i, got, err)
+		}
+	}
+}
+
+func TestStreamBody_AddFileErrors(t *testing.T) {
+	b := &streamBody{}
+	if err := b.addFile(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("missing file should error")
+	}
+	if err := b.addFile(t.TempDir()); err == nil {
+		t.Error("directory should error")
+	}
+}
+
+func TestBinaryContentType(t *testing.T) {
+	tests := map[string]string{
+		"photo.png":    "image/png",
+		"doc.pdf":      "application/pdf",
+		"archive.blob": "application/octet-stream",
+		"noext":        "application/octet-stream",
+	}
+	for path, want := range tests {
+		if got := binaryContentType(path); got != want {
+			t.Errorf("binaryContentType(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
@@ -1,8 +1,11 @@
 package httpx
 
 import (
+	"io"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/suprbdev/reqo/internal/project"
@@ -295,12 +298,12 @@ func TestBuildRequest_JSONBodyWithVars(t *testing.T) {
 	}
 }
 
-func TestBuildRequest_FormFields(t *testing.T) {
+func TestBuildRequest_Form(t *testing.T) {
 	p := makeProject()
 	spec := RequestSpec{
-		Method:     "POST",
-		Path:       "/upload",
-		FormFields: map[string]string{"field1": "value1", "field2": "value2"},
+		Method: "POST",
+		Path:   "/upload",
+		Form:   []project.FormField{{Name: "field1", Value: "value1"}, {Name: "field2", Value: "value2"}},
 	}
 	req, err := BuildRequest(p, spec)
 	if err != nil {
@@ -319,10 +322,10 @@ func TestBuildRequest_FormFields(t *testing.T) {
 func TestBuildRequest_FormFieldWithVars(t *testing.T) {
 	p := makeProject()
 	spec := RequestSpec{
-		Method:     "POST",
-		Path:       "/upload",
-		FormFields: map[string]string{"desc": "file_${id}"},
-		Vars:       map[string]string{"id": "42"},
+		Method: "POST",
+		Path:   "/upload",
+		Form:   []project.FormField{{Name: "desc", Value: "file_${id}"}},
+		Vars:   map[string]string{"id": "42"},
 	}
 	req, err := BuildRequest(p, spec)
 	if err != nil {
@@ -338,9 +341,9 @@ func TestBuildRequest_FormFileUpload(t *testing.T) {
 	// Create temp file for upload
 	tmpFile := "/tmp/reqo_test_upload.txt"
 	spec := RequestSpec{
-		Method:     "POST",
-		Path:       "/upload",
-		FormFields: map[string]string{"file": "@" + tmpFile},
+		Method: "POST",
+		Path:   "/upload",
+		Form:   []project.FormField{{Name: "file", File: tmpFile}},
 	}
 	_, err := BuildRequest(p, spec)
 	if err == nil {
@@ -504,3 +507,120 @@ func TestBuildRequest_JSONBodyFromFileMissing(t *testing.T) {
 func writeFile(path, content string) error {
 	return os.WriteFile(path, []byte(content), 0o644)
 }
+
+func TestBuildRequest_URLEncoded(t *testing.T) {
+	p := makeProject()
+	spec := RequestSpec{
+		Method:     "POST",
+		Path:       "/oauth/token",
+		URLEncoded: []string{"grant_type=client_credentials", "scope=read write", "scope=${extra}", "redirect=https://x/?a=1&b"},
+		Vars:       map[string]string{"extra": "admin"},
+	}
+	req, err := BuildRequest(p, spec)
+	if err != nil {
+		t.Fatalf("BuildRequest() error: %v", err)
+	}
+	if req.Header.Get("Content-Type") != "application/x-www-form-urlencoded" {
+		t.Errorf("Content-Type = %q", req.Header.Get("Content-Type"))
+	}
+	b, _ := io.ReadAll(req.Body)
+	want := "grant_type=client_credentials&scope=read+write&scope=admin&redirect=https%3A%2F%2Fx%2F%3Fa%3D1%26b"
+	if string(b) != want {
+		t.Errorf("body = %q, want %q", b, want)
+	}
+}
+
+func TestBuildRequest_URLEncodedErrors(t *testing.T) {
+	p := makeProject()
+	if _, err := BuildRequest(p, RequestSpec{Method: "POST", Path: "/x", URLEncoded: []string{"novalue"}}); err == nil {
+		t.Errorf("expected error for a field without =")
+	}
+	jsonBody := `{}`
+	if _, err := BuildRequest(p, RequestSpec{Method: "POST", Path: "/x", URLEncoded: []string{"a=b"}, JSONBody: &jsonBody}); err == nil {
+		t.Errorf("expected error when combining --form-urlencoded and --json")
+	}
+}
+
+func TestBuildRequest_JSONBodyFromStdin(t *testing.T) {
+	withStdin(t, `{"from":"${src}"}`)
+	p := makeProject()
+	jsonBody := "@-"
+	req, err := BuildRequest(p, RequestSpec{Method: "POST", Path: "/x", JSONBody: &jsonBody, Vars: map[string]string{"src": "pipe"}})
+	if err != nil {
+		t.Fatalf("BuildRequest() error: %v", err)
+	}
+	b, _ := io.ReadAll(req.Body)
+	if string(b) != `{"from":"pipe"}` {
+		t.Errorf("body = %q", b)
+	}
+}
+
+func TestBuildRequest_BinaryBody(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "image.png")
+	content := []byte("\x89PNG\x00${name}\xff")
+	os.WriteFile(path, content, 0o644)
+
+	p := makeProject()
+	bin := "@" + path
+	req, err := BuildRequest(p, RequestSpec{Method: "PUT", Path: "/upload", BinaryBody: &bin, Vars: map[string]string{"name": "x"}})
+	if err != nil {
+		t.Fatalf("BuildRequest() error: %v", err)
+	}
+	if req.ContentLength != int64(len(content)) {
+		t.Errorf("ContentLength = %d, want %d", req.ContentLength, len(content))
+	}
+	if req.Header.Get("Content-Type") != "image/png" {
+		t.Errorf("Content-Type = %q", req.Header.Get("Content-Type"))
+	}
+	got, _ := io.ReadAll(req.Body)
+	if string(got) != string(content) {
+		t.Errorf("body = %q, want it unmodified", got)
+	}
+	if BinaryFile(req) != path {
+		t.Errorf("BinaryFile() = %q", BinaryFile(req))
+	}
+}
+
+func TestBuildRequest_BinaryBodyMissingFile(t *testing.T) {
+	bin := "@/tmp/reqo_nonexistent_binary.bin"
+	if _, err := BuildRequest(makeProject(), RequestSpec{Method: "PUT", Path: "/x", BinaryBody: &bin}); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+func TestBuildRequest_MultipleBodies_Error(t *testing.T) {
+	bin := "@file"
+	_, err := BuildRequest(makeProject(), RequestSpec{Method: "PUT", Path: "/x", BinaryBody: &bin, Form: []project.FormField{{Name: "a", Value: "b"}}})
+	if err == nil || !strings.Contains(err.Error(), "--data-binary and --form") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestBuildRequest_FormFileStreamed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.csv")
+	os.WriteFile(path, []byte("a,b\n1,2\n"), 0o644)
+
+	req, err := BuildRequest(makeProject(), RequestSpec{
+		Method: "POST",
+		Path:   "/upload",
+		Form:   []project.FormField{{Name: "file", File: path}, {Name: "note", Value: "hi"}},
+	})
+	if err != nil {
+		t.Fatalf("BuildRequest() error: %v", err)
+	}
+	body, _ := io.ReadAll(req.Body)
+	if req.ContentLength != int64(len(body)) {
+		t.Errorf("ContentLength = %d, body is %d bytes", req.ContentLength, len(body))
+	}
+	req.Body, _ = req.GetBody()
+	if err := req.ParseMultipartForm(1 << 20); err != nil {
+		t.Fatalf("ParseMultipartForm() error: %v", err)
+	}
+	if req.FormValue("note") != "hi" {
+		t.Errorf("note = %q", req.FormValue("note"))
+	}
+	fh := req.MultipartForm.File["file"]
+	if len(fh) != 1 || fh[0].Filename != "report.csv" || fh[0].Size != 8 {
+		t.Errorf("file part = %+v", fh)
+	}
+}
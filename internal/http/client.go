@@ -6,11 +6,9 @@ import (
 	"crypto/tls"
 	"fmt"
 	"io"
-	"mime/multipart"
 	"net/http"
 	"net/url"
 	"os"
-	"path/filepath"
 	"strings"
 	"time"
 
@@ -25,13 +23,36 @@ type RequestSpec struct {
 	QueryParams  []string // each "k=v"
 	Headers      []string // raw header lines
 	UseHeaderSet string
-	JSONBody     *string // raw JSON or @file
-	RawBody      *string // raw body or @file
-	FormFields   map[string]string
+	JSONBody     *string             // raw JSON or @file
+	RawBody      *string             // raw body or @file
+	Form         []project.FormField // multipart parts in order; names may repeat
+	URLEncoded   []string            // each "k=v", sent as application/x-www-form-urlencoded
+	BinaryBody   *string             // @file, @- or a literal, sent unmodified (no template expansion)
+	GraphQL      *GraphQLBody
 	Vars         map[string]string // --var values for expansion
 	EnvName      string            // optional env override
 }
 
+// FormPart is one multipart field as it was specified. BuildRequest keeps
+// these alongside the request so exporters can reproduce file uploads by
+// path instead of by content.
+type FormPart struct {
+	Name     string
+	Value    string // literal value (encoded JSON for JSON parts), empty for file uploads
+	File     string // path of the uploaded file, if any
+	Type     string // Content-Type given for the part, if any
+	Filename string // file name override, if any
+}
+
+type formPartsKey struct{}
+
+// FormParts returns the multipart fields BuildRequest encoded into req, in
+// the order they were written. It returns nil for non-multipart requests.
+func FormParts(req *http.Request) []FormPart {
+	parts, _ := req.Context().Value(formPartsKey{}).([]FormPart)
+	return parts
+}
+
 // ExecOpts holds runtime options (timeout, retries,…)
 type ExecOpts struct {
 	Timeout      time.Duration
@@ -85,13 +106,23 @@ func BuildRequest(p *project.Project, spec RequestSpec) (*http.Request, error) {
 
 	// 2️⃣ Body handling
 	var body io.Reader
+	var stream *streamBody // set for bodies read from files as they are sent
+	var formParts []FormPart
+	binaryFile := ""
 	contentType := ""
 
-	if spec.JSONBody != nil && spec.RawBody != nil {
-		return nil, fmt.Errorf("cannot specify both --json and --data")
+	if given := bodyFlags(spec); len(given) > 1 {
+		return nil, fmt.Errorf("cannot combine %s", strings.Join(given, " and "))
 	}
 
 	switch {
+	case spec.GraphQL != nil:
+		data, err := encodeGraphQL(spec.GraphQL, spec.Vars)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(data)
+		contentType = "application/json"
 	case spec.JSONBody != nil:
 		data, err := readPossiblyFile(*spec.JSONBody)
 		if err != nil {
@@ -109,44 +140,63 @@ func BuildRequest(p *project.Project, spec RequestSpec) (*http.Request, error) {
 		// Apply variable expansion to file content
 		expandedData := template.Expand(data, spec.Vars)
 		body = strings.NewReader(expandedData)
-	case len(spec.FormFields) > 0:
-		var b bytes.Buffer
-		w := multipart.NewWriter(&b)
-		for k, v := range spec.FormFields {
-			val := template.Expand(v, spec.Vars)
-			if strings.HasPrefix(val, "@") { // file upload
-				fpath := strings.TrimPrefix(val, "@")
-				f, err := os.Open(fpath)
-				if err != nil {
-					return nil, fmt.Errorf("open form file %s: %w", fpath, err)
-				}
-				defer f.Close()
-				part, err := w.CreateFormFile(k, filepath.Base(fpath))
-				if err != nil {
-					return nil, err
-				}
-				if _, err = io.Copy(part, f); err != nil {
-					return nil, err
-				}
-			} else {
-				_ = w.WriteField(k, val)
+	case len(spec.URLEncoded) > 0:
+		data, err := encodeURLForm(spec.URLEncoded, spec.Vars)
+		if err != nil {
+			return nil, err
+		}
+		body = strings.NewReader(data)
+		contentType = "application/x-www-form-urlencoded"
+	case spec.BinaryBody != nil:
+		switch v := *spec.BinaryBody; {
+		case v == "@-":
+			body = io.NopCloser(os.Stdin)
+			binaryFile = "-"
+		case strings.HasPrefix(v, "@"):
+			binaryFile = strings.TrimPrefix(v, "@")
+			stream = &streamBody{}
+			if err := stream.addFile(binaryFile); err != nil {
+				return nil, fmt.Errorf("read file %s: %w", binaryFile, err)
 			}
+			contentType = binaryContentType(binaryFile)
+		default:
+			body = strings.NewReader(v)
+		}
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+	case len(spec.Form) > 0:
+		var err error
+		if stream, formParts, contentType, err = encodeMultipart(spec.Form, spec.Vars); err != nil {
+			return nil, err
 		}
-		w.Close()
-		body = &b
-		contentType = w.FormDataContentType()
 	default:
 		body = nil
 	}
 
 	method := spec.Method
-	if method == "" {
+	if method == "" && spec.GraphQL != nil {
+		method = http.MethodPost
+	} else if method == "" {
 		method = http.MethodGet
 	}
-	req, err := http.NewRequest(method, u.String(), body)
+	ctx := context.Background()
+	if formParts != nil {
+		ctx = context.WithValue(ctx, formPartsKey{}, formParts)
+	}
+	if spec.GraphQL != nil {
+		ctx = context.WithValue(ctx, graphQLKey{}, true)
+	}
+	if binaryFile != "" {
+		ctx = context.WithValue(ctx, binaryFileKey{}, binaryFile)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
 	if err != nil {
 		return nil, err
 	}
+	if stream != nil {
+		withStreamBody(req, stream)
+	}
 
 	// 3️⃣ Merge headers (order of precedence):
 	//    a) env.Headers
@@ -194,24 +244,39 @@ func BuildRequest(p *project.Project, spec RequestSpec) (*http.Request, error) {
 	return req, nil
 }
 
-// Execute performs the HTTP request with timeout/retry/backoff.
+// NewTransport returns a transport that keeps up to conns idle connections
+// per host, so that conns concurrent requests to one server reuse their
+// connections instead of dialing anew.
+func NewTransport(insecure bool, conns int) *http.Transport {
+	return &http.Transport{
+		TLSClientConfig:     &tls.Config{InsecureSkipVerify: insecure},
+		MaxIdleConns:        max(conns, 10),
+		MaxIdleConnsPerHost: max(conns, http.DefaultMaxIdleConnsPerHost),
+		IdleConnTimeout:     90 * time.Second,
+		TLSHandshakeTimeout: 10 * time.Second,
+	}
+}
+
+// NewClient returns a client on tr with the timeout and redirect limit of
+// opts.
+func NewClient(tr http.RoundTripper, opts ExecOpts) *http.Client {
+	return &http.Client{
+		Transport: tr,
+		Timeout:   opts.Timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > opts.MaxRedirects {
+				return fmt.Errorf("stopped after %d redirects", opts.MaxRedirects)
+			}
+			return nil
+		},
+	}
+}
+
+// Execute performs the HTTP request with timeout/retry/backoff. Without a
+// client a new one is made from opts.
 func Execute(ctx context.Context, client *http.Client, req *http.Request, opts ExecOpts) (*http.Response, error) {
 	if client == nil {
-		tr := &http.Transport{
-			TLSClientConfig:   &tls.Config{InsecureSkipVerify: opts.Insecure},
-			MaxIdleConns:      10,
-			DisableKeepAlives: false,
-		}
-		client = &http.Client{
-			Transport: tr,
-			Timeout:   opts.Timeout,
-			CheckRedirect: func(req *http.Request, via []*http.Request) error {
-				if len(via) > opts.MaxRedirects {
-					return fmt.Errorf("stopped after %d redirects", opts.MaxRedirects)
-				}
-				return nil
-			},
-		}
+		client = NewClient(NewTransport(opts.Insecure, 1), opts)
 	}
 
 	var resp *http.Response
@@ -233,7 +298,18 @@ func Execute(ctx context.Context, client *http.Client, req *http.Request, opts E
 
 // Helper utilities ---------------------------------------------------------
 
+// ReadPossiblyFile returns v, or the content of the file it names when it
+// starts with "@" ("@-" reads standard input).
+func ReadPossiblyFile(v string) (string, error) { return readPossiblyFile(v) }
+
 func readPossiblyFile(v string) (string, error) {
+	if v == "@-" {
+		b, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", fmt.Errorf("read stdin: %w", err)
+		}
+		return string(b), nil
+	}
 	if strings.HasPrefix(v, "@") {
 		path := strings.TrimPrefix(v, "@")
 		b, err := os.ReadFile(path)
@@ -245,6 +321,44 @@ func readPossiblyFile(v string) (string, error) {
 	return v, nil
 }
 
+// bodyFlags names the body options set in spec; at most one may be given.
+func bodyFlags(spec RequestSpec) []string {
+	var given []string
+	if spec.JSONBody != nil {
+		given = append(given, "--json")
+	}
+	if spec.RawBody != nil {
+		given = append(given, "--data")
+	}
+	if spec.BinaryBody != nil {
+		given = append(given, "--data-binary")
+	}
+	if len(spec.Form) > 0 {
+		given = append(given, "--form")
+	}
+	if len(spec.URLEncoded) > 0 {
+		given = append(given, "--form-urlencoded")
+	}
+	if spec.GraphQL != nil {
+		given = append(given, "a GraphQL body")
+	}
+	return given
+}
+
+// encodeURLForm encodes "k=v" fields in the order given, expanding
+// templates in both names and values. Repeated names are kept.
+func encodeURLForm(fields []string, vars map[string]string) (string, error) {
+	pairs := make([]string, 0, len(fields))
+	for _, f := range fields {
+		k, v, ok := strings.Cut(f, "=")
+		if !ok {
+			return "", fmt.Errorf("invalid form field %q – must be k=v", f)
+		}
+		pairs = append(pairs, url.QueryEscape(template.Expand(k, vars))+"="+url.QueryEscape(template.Expand(v, vars)))
+	}
+	return strings.Join(pairs, "&"), nil
+}
+
 func isIdempotent(m string) bool {
 	switch strings.ToUpper(m) {
 	case http.MethodGet, http.MethodHead, http.MethodOptions:
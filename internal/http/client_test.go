@@ -2,8 +2,10 @@ package httpx
 
 import (
 	"context"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"strings"
 	"sync/atomic"
 	"testing"
@@ -220,6 +222,34 @@ func TestReadPossiblyFile_MissingFile(t *testing.T) {
 	}
 }
 
+// withStdin replaces os.Stdin with a file holding content for the test.
+func withStdin(t *testing.T, content string) {
+	t.Helper()
+	f, err := os.CreateTemp(t.TempDir(), "stdin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(content)
+	f.Seek(0, io.SeekStart)
+	orig := os.Stdin
+	os.Stdin = f
+	t.Cleanup(func() {
+		os.Stdin = orig
+		f.Close()
+	})
+}
+
+func TestReadPossiblyFile_Stdin(t *testing.T) {
+	withStdin(t, "piped payload")
+	got, err := readPossiblyFile("@-")
+	if err != nil {
+		t.Fatalf("readPossiblyFile() error: %v", err)
+	}
+	if got != "piped payload" {
+		t.Errorf("got = %q", got)
+	}
+}
+
 func TestExecute_InsecureTLS(t *testing.T) {
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -255,3 +285,17 @@ func TestExecute_RedirectLimit(t *testing.T) {
 		// The error should be redirect-related if present
 	}
 }
+
+func TestNewTransport_Conns(t *testing.T) {
+	tr := NewTransport(true, 50)
+	if tr.MaxIdleConns != 50 || tr.MaxIdleConnsPerHost != 50 {
+		t.Errorf("idle conns = %d, per host %d", tr.MaxIdleConns, tr.MaxIdleConnsPerHost)
+	}
+	if !tr.TLSClientConfig.InsecureSkipVerify {
+		t.Error("insecure should skip verification")
+	}
+	tr = NewTransport(false, 1)
+	if tr.MaxIdleConns != 10 || tr.MaxIdleConnsPerHost != http.DefaultMaxIdleConnsPerHost {
+		t.Errorf("defaults = %d, per host %d", tr.MaxIdleConns, tr.MaxIdleConnsPerHost)
+	}
+}
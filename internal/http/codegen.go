@@ -0,0 +1,655 @@
+package httpx
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// exporter renders a request snapshot as source code in one language.
+type exporter struct {
+	comment string // line comment prefix for the language
+	render  func(s *snapshot) (string, error)
+	// renderAll writes several requests as one program, for languages
+	// whose snippets are whole programs and cannot simply be joined.
+	renderAll func(names []string, ss []*snapshot) (string, error)
+}
+
+var exporters = map[string]exporter{
+	"curl":            {comment: "#", render: func(s *snapshot) (string, error) { return AsCurl(s.req) }},
+	"go":              {comment: "//", render: renderGo, renderAll: renderGoAll},
+	"python-requests": {comment: "#", render: renderPython},
+	"js-fetch":        {comment: "//", render: renderJSFetch, renderAll: renderJSFetchAll},
+	"httpie":          {comment: "#", render: renderHTTPie},
+	"wget":            {comment: "#", render: renderWget},
+	"powershell":      {comment: "#", render: renderPowerShell},
+}
+
+// ExportLangs lists the languages accepted by Export, sorted by name.
+func ExportLangs() []string {
+	langs := make([]string, 0, len(exporters))
+	for l := range exporters {
+		langs = append(langs, l)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// Export turns a built request into a runnable snippet for lang.
+func Export(req *http.Request, lang string) (string, error) {
+	e, ok := exporters[lang]
+	if !ok {
+		return "", fmt.Errorf("unknown export language %q (supported: %s)", lang, strings.Join(ExportLangs(), ", "))
+	}
+	s, err := newSnapshot(req)
+	if err != nil {
+		return "", err
+	}
+	return e.render(s)
+}
+
+// ExportAll turns several built requests into one runnable file for lang,
+// each labelled with its name. Snippets are written one after another
+// under a comment; Go and JavaScript get a single program with a function
+// per request, called in order.
+func ExportAll(names []string, reqs []*http.Request, lang string) (string, error) {
+	e, ok := exporters[lang]
+	if !ok {
+		return "", fmt.Errorf("unknown export language %q (supported: %s)", lang, strings.Join(ExportLangs(), ", "))
+	}
+	ss := make([]*snapshot, len(reqs))
+	for i, req := range reqs {
+		s, err := newSnapshot(req)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", names[i], err)
+		}
+		ss[i] = s
+	}
+	if e.renderAll != nil {
+		return e.renderAll(names, ss)
+	}
+	var b strings.Builder
+	for i, s := range ss {
+		snippet, err := e.render(s)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", names[i], err)
+		}
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(ExportComment(lang, names[i]) + "\n")
+		b.WriteString(strings.TrimRight(snippet, "\n") + "\n")
+	}
+	return b.String(), nil
+}
+
+// ExportComment formats text as a line comment in lang, e.g. to separate
+// several exported snippets.
+func ExportComment(lang, text string) string {
+	prefix := "#"
+	if e, ok := exporters[lang]; ok {
+		prefix = e.comment
+	}
+	return prefix + " " + text
+}
+
+// snapshot is the language‑neutral view of a request used by the renderers.
+type snapshot struct {
+	req     *http.Request
+	method  string
+	url     string
+	headers [][2]string // sorted by name, multi‑values kept in order
+	body    string      // empty when form or file is set
+	form    []FormPart
+	file    string // binary body streamed from this path
+}
+
+func newSnapshot(req *http.Request) (*snapshot, error) {
+	u := *req.URL
+	u.User = nil
+	s := &snapshot{
+		req:    req,
+		method: req.Method,
+		url:    u.String(),
+		form:   FormParts(req),
+		file:   BinaryFile(req),
+	}
+	if s.file == "-" {
+		return nil, fmt.Errorf("a body read from stdin cannot be exported; save it to a file and use --data-binary @file")
+	}
+	for _, k := range sortedHeaderKeys(req.Header) {
+		// multipart boundaries are generated again by every client library
+		if s.form != nil && strings.EqualFold(k, "Content-Type") {
+			continue
+		}
+		for _, v := range req.Header[k] {
+			s.headers = append(s.headers, [2]string{k, v})
+		}
+	}
+	if s.form == nil && s.file == "" && req.GetBody != nil {
+		rc, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		var buf bytes.Buffer
+		if _, err = buf.ReadFrom(rc); err != nil {
+			return nil, err
+		}
+		s.body = buf.String()
+	}
+	return s, nil
+}
+
+func sortedHeaderKeys(h http.Header) []string {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Go ----------------------------------------------------------------------
+
+// goCode is the Go statements sending one request, indented one tab, and
+// what they need from the rest of the program.
+type goCode struct {
+	stmts              string
+	imports            []string
+	needFile, needPart bool
+}
+
+func goRequest(s *snapshot) goCode {
+	c := goCode{imports: []string{"fmt", "io", "net/http", "os"}}
+	var b strings.Builder
+	bodyVar := "nil"
+	switch {
+	case s.form != nil:
+		c.imports = append(c.imports, "bytes", "mime/multipart")
+		b.WriteString("\tvar body bytes.Buffer\n")
+		b.WriteString("\tw := multipart.NewWriter(&body)\n")
+		for _, p := range s.form {
+			switch {
+			case p.Type != "" || p.Filename != "":
+				c.needPart = true
+				fmt.Fprintf(&b, "\tif err := addPart(w, %s, %s, %s, %s, %s); err != nil {\n\t\tpanic(err)\n\t}\n",
+					strconv.Quote(p.Name), strconv.Quote(p.Filename), strconv.Quote(p.Type), strconv.Quote(p.File), strconv.Quote(p.Value))
+			case p.File == "":
+				fmt.Fprintf(&b, "\tw.WriteField(%s, %s)\n", strconv.Quote(p.Name), strconv.Quote(p.Value))
+			default:
+				c.needFile = true
+				fmt.Fprintf(&b, "\tif err := addFile(w, %s, %s); err != nil {\n\t\tpanic(err)\n\t}\n",
+					strconv.Quote(p.Name), strconv.Quote(p.File))
+			}
+		}
+		b.WriteString("\tw.Close()\n\n")
+		bodyVar = "&body"
+	case s.file != "":
+		fmt.Fprintf(&b, "\tbody, err := os.Open(%s)\n", strconv.Quote(s.file))
+		b.WriteString("\tif err != nil {\n\t\tpanic(err)\n\t}\n\tdefer body.Close()\n\n")
+		bodyVar = "body"
+	case s.body != "":
+		c.imports = append(c.imports, "strings")
+		fmt.Fprintf(&b, "\tbody := strings.NewReader(%s)\n\n", strconv.Quote(s.body))
+		bodyVar = "body"
+	}
+	fmt.Fprintf(&b, "\treq, err := http.NewRequest(%s, %s, %s)\n", strconv.Quote(s.method), strconv.Quote(s.url), bodyVar)
+	b.WriteString("\tif err != nil {\n\t\tpanic(err)\n\t}\n")
+	for _, h := range s.headers {
+		fmt.Fprintf(&b, "\treq.Header.Add(%s, %s)\n", strconv.Quote(h[0]), strconv.Quote(h[1]))
+	}
+	if s.form != nil {
+		b.WriteString("\treq.Header.Set(\"Content-Type\", w.FormDataContentType())\n")
+	}
+	b.WriteString("\n\tresp, err := http.DefaultClient.Do(req)\n")
+	b.WriteString("\tif err != nil {\n\t\tpanic(err)\n\t}\n")
+	b.WriteString("\tdefer resp.Body.Close()\n\n")
+	b.WriteString("\tfmt.Println(resp.Status)\n")
+	b.WriteString("\tio.Copy(os.Stdout, resp.Body)\n")
+	c.stmts = b.String()
+	return c
+}
+
+func renderGo(s *snapshot) (string, error) {
+	c := goRequest(s)
+	return goProgram([]goCode{c}, "func main() {\n"+c.stmts+"}\n"), nil
+}
+
+// renderGoAll writes one function per request, named after it, and a main
+// calling them in order.
+func renderGoAll(names []string, ss []*snapshot) (string, error) {
+	codes := make([]goCode, len(ss))
+	var funcs, calls strings.Builder
+	seen := map[string]int{}
+	for i, s := range ss {
+		codes[i] = goRequest(s)
+		fn := funcName("call", names[i], seen)
+		fmt.Fprintf(&calls, "\t%s()\n", fn)
+		fmt.Fprintf(&funcs, "\n// %s\nfunc %s() {\n%s}\n", names[i], fn, codes[i].stmts)
+	}
+	return goProgram(codes, "func main() {\n"+calls.String()+"}\n"+funcs.String()), nil
+}
+
+// goProgram wraps code in package main with the imports and helpers the
+// requests in codes need.
+func goProgram(codes []goCode, code string) string {
+	needFile, needPart := false, false
+	seen := map[string]bool{}
+	var imports []string
+	for _, c := range codes {
+		needFile = needFile || c.needFile
+		needPart = needPart || c.needPart
+		imports = append(imports, c.imports...)
+	}
+	if needFile || needPart {
+		imports = append(imports, "path/filepath")
+	}
+	if needPart {
+		imports = append(imports, "net/textproto")
+	}
+	sort.Strings(imports)
+
+	var b strings.Builder
+	b.WriteString("package main\n\nimport (\n")
+	for _, imp := range imports {
+		if !seen[imp] {
+			seen[imp] = true
+			fmt.Fprintf(&b, "\t%q\n", imp)
+		}
+	}
+	b.WriteString(")\n\n")
+	b.WriteString(code)
+	if needFile {
+		b.WriteString(`
+func addFile(w *multipart.Writer, field, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	part, err := w.CreateFormFile(field, filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(part, f)
+	return err
+}
+`)
+	}
+	if needPart {
+		b.WriteString(`
+// addPart writes a part with its own file name and Content-Type; the
+// content is the file at path, or value when path is empty.
+func addPart(w *multipart.Writer, field, filename, contentType, path, value string) error {
+	disp := fmt.Sprintf("form-data; name=%q", field)
+	if filename == "" && path != "" {
+		filename = filepath.Base(path)
+	}
+	if filename != "" {
+		disp += fmt.Sprintf("; filename=%q", filename)
+	}
+	h := textproto.MIMEHeader{"Content-Disposition": {disp}}
+	if contentType != "" {
+		h.Set("Content-Type", contentType)
+	}
+	part, err := w.CreatePart(h)
+	if err != nil {
+		return err
+	}
+	if path == "" {
+		_, err = io.WriteString(part, value)
+		return err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(part, f)
+	return err
+}
+`)
+	}
+	return b.String()
+}
+
+// funcName turns a request name such as "get-users" into an identifier
+// like callGetUsers, numbering names that come out the same.
+func funcName(prefix, name string, seen map[string]int) string {
+	fn := prefix
+	for _, w := range strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		r, size := utf8.DecodeRuneInString(w)
+		fn += string(unicode.ToUpper(r)) + w[size:]
+	}
+	seen[fn]++
+	if n := seen[fn]; n > 1 {
+		fn += strconv.Itoa(n)
+	}
+	return fn
+}
+
+// Python (requests) -------------------------------------------------------
+
+func renderPython(s *snapshot) (string, error) {
+	var b strings.Builder
+	b.WriteString("import requests\n\n")
+	fmt.Fprintf(&b, "url = %s\n", jsQuote(s.url))
+	args := []string{"url"}
+	if len(s.headers) > 0 {
+		b.WriteString("headers = {\n")
+		for _, h := range joinedHeaders(s.headers) {
+			fmt.Fprintf(&b, "    %s: %s,\n", jsQuote(h[0]), jsQuote(h[1]))
+		}
+		b.WriteString("}\n")
+		args = append(args, "headers=headers")
+	}
+	switch {
+	case s.form != nil:
+		var fields, files []FormPart
+		for _, p := range s.form {
+			if p.File != "" || p.Type != "" || p.Filename != "" {
+				files = append(files, p)
+			} else {
+				fields = append(fields, p)
+			}
+		}
+		if len(fields) > 0 {
+			b.WriteString("data = [\n")
+			for _, p := range fields {
+				fmt.Fprintf(&b, "    (%s, %s),\n", jsQuote(p.Name), jsQuote(p.Value))
+			}
+			b.WriteString("]\n")
+			args = append(args, "data=data")
+		}
+		if len(files) > 0 {
+			b.WriteString("files = [\n")
+			for _, p := range files {
+				fmt.Fprintf(&b, "    (%s, %s),\n", jsQuote(p.Name), pythonFilePart(p))
+			}
+			b.WriteString("]\n")
+			args = append(args, "files=files")
+		}
+	case s.file != "":
+		fmt.Fprintf(&b, "data = open(%s, \"rb\")\n", jsQuote(s.file))
+		args = append(args, "data=data")
+	case s.body != "":
+		fmt.Fprintf(&b, "data = %s\n", jsQuote(s.body))
+		args = append(args, "data=data.encode(\"utf-8\")")
+	}
+	fmt.Fprintf(&b, "\nresponse = requests.request(%s, %s)\n", jsQuote(s.method), strings.Join(args, ", "))
+	b.WriteString("print(response.status_code)\n")
+	b.WriteString("print(response.text)\n")
+	return b.String(), nil
+}
+
+// pythonFilePart renders the value of a requests "files" entry: an open
+// file, or a (filename, content, content_type) tuple when headers are set.
+func pythonFilePart(p FormPart) string {
+	if p.Type == "" && p.Filename == "" {
+		return fmt.Sprintf("open(%s, \"rb\")", jsQuote(p.File))
+	}
+	filename, content := "None", jsQuote(p.Value)
+	if p.File != "" {
+		filename, content = jsQuote(filepath.Base(p.File)), fmt.Sprintf("open(%s, \"rb\")", jsQuote(p.File))
+	}
+	if p.Filename != "" {
+		filename = jsQuote(p.Filename)
+	}
+	if p.Type == "" {
+		return fmt.Sprintf("(%s, %s)", filename, content)
+	}
+	return fmt.Sprintf("(%s, %s, %s)", filename, content, jsQuote(p.Type))
+}
+
+// JavaScript (fetch) ------------------------------------------------------
+
+// jsRequest returns the statements sending one request and whether they
+// read files, which needs the node:fs import.
+func jsRequest(s *snapshot) (string, bool) {
+	var b strings.Builder
+	readsFile := s.file != ""
+	if s.form != nil {
+		b.WriteString("const form = new FormData();\n")
+		for _, p := range s.form {
+			opts := ""
+			if p.Type != "" {
+				opts = fmt.Sprintf(", { type: %s }", jsQuote(p.Type))
+			}
+			filename := p.Filename
+			switch {
+			case p.File != "":
+				readsFile = true
+				if filename == "" {
+					filename = filepath.Base(p.File)
+				}
+				fmt.Fprintf(&b, "form.append(%s, new Blob([await readFile(%s)]%s), %s);\n",
+					jsQuote(p.Name), jsQuote(p.File), opts, jsQuote(filename))
+			case p.Type != "" || filename != "":
+				fmt.Fprintf(&b, "form.append(%s, new Blob([%s]%s)", jsQuote(p.Name), jsQuote(p.Value), opts)
+				if filename != "" {
+					fmt.Fprintf(&b, ", %s", jsQuote(filename))
+				}
+				b.WriteString(");\n")
+			default:
+				fmt.Fprintf(&b, "form.append(%s, %s);\n", jsQuote(p.Name), jsQuote(p.Value))
+			}
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "const response = await fetch(%s, {\n", jsQuote(s.url))
+	fmt.Fprintf(&b, "  method: %s,\n", jsQuote(s.method))
+	if len(s.headers) > 0 {
+		b.WriteString("  headers: {\n")
+		for _, h := range joinedHeaders(s.headers) {
+			fmt.Fprintf(&b, "    %s: %s,\n", jsQuote(h[0]), jsQuote(h[1]))
+		}
+		b.WriteString("  },\n")
+	}
+	switch {
+	case s.form != nil:
+		b.WriteString("  body: form,\n")
+	case s.file != "":
+		fmt.Fprintf(&b, "  body: await readFile(%s),\n", jsQuote(s.file))
+	case s.body != "":
+		fmt.Fprintf(&b, "  body: %s,\n", jsQuote(s.body))
+	}
+	b.WriteString("});\n\n")
+	b.WriteString("console.log(response.status);\n")
+	b.WriteString("console.log(await response.text());\n")
+	return b.String(), readsFile
+}
+
+const jsReadFileImport = "// Node.js 18+ ES module (save as .mjs)\n" +
+	"import { readFile } from \"node:fs/promises\";\n\n"
+
+func renderJSFetch(s *snapshot) (string, error) {
+	stmts, readsFile := jsRequest(s)
+	if readsFile {
+		stmts = jsReadFileImport + stmts
+	}
+	return stmts, nil
+}
+
+// renderJSFetchAll writes one async function per request, named after it,
+// and awaits them in order so the module's constants do not clash.
+func renderJSFetchAll(names []string, ss []*snapshot) (string, error) {
+	var funcs, calls strings.Builder
+	anyReadsFile := false
+	seen := map[string]int{}
+	for i, s := range ss {
+		stmts, readsFile := jsRequest(s)
+		anyReadsFile = anyReadsFile || readsFile
+		fn := funcName("call", names[i], seen)
+		fmt.Fprintf(&calls, "await %s();\n", fn)
+		fmt.Fprintf(&funcs, "// %s\nasync function %s() {\n", names[i], fn)
+		for _, line := range strings.SplitAfter(strings.TrimSuffix(stmts, "\n"), "\n") {
+			if line != "\n" {
+				funcs.WriteString("  ")
+			}
+			funcs.WriteString(line)
+		}
+		funcs.WriteString("\n}\n\n")
+	}
+	out := funcs.String() + calls.String()
+	if anyReadsFile {
+		out = jsReadFileImport + out
+	}
+	return out, nil
+}
+
+// HTTPie ------------------------------------------------------------------
+
+func renderHTTPie(s *snapshot) (string, error) {
+	args := []string{"http"}
+	if s.form != nil {
+		args = append(args, "--multipart")
+	}
+	if s.body != "" {
+		args = append(args, "--raw", shellQuote(s.body))
+	}
+	args = append(args, s.method, shellQuote(s.url))
+	for _, h := range s.headers {
+		args = append(args, shellQuote(h[0]+":"+h[1]))
+	}
+	for _, p := range s.form {
+		switch {
+		case p.Filename != "" || (p.File == "" && p.Type != ""):
+			return "", fmt.Errorf("httpie cannot set the file name or content type of part %q", p.Name)
+		case p.File != "" && p.Type != "":
+			args = append(args, shellQuote(p.Name+"@"+p.File+";type="+p.Type))
+		case p.File != "":
+			args = append(args, shellQuote(p.Name+"@"+p.File))
+		default:
+			args = append(args, shellQuote(p.Name+"="+p.Value))
+		}
+	}
+	if s.file != "" {
+		args = append(args, "<", shellQuote(s.file))
+	}
+	return strings.Join(args, " "), nil
+}
+
+// wget --------------------------------------------------------------------
+
+func renderWget(s *snapshot) (string, error) {
+	if s.form != nil {
+		return "", fmt.Errorf("wget cannot send multipart form bodies")
+	}
+	args := []string{"wget", "--quiet", "--output-document=-", "--method=" + s.method}
+	for _, h := range s.headers {
+		args = append(args, "--header="+shellQuote(h[0]+": "+h[1]))
+	}
+	if s.file != "" {
+		args = append(args, "--body-file="+shellQuote(s.file))
+	}
+	if s.body != "" {
+		args = append(args, "--body-data="+shellQuote(s.body))
+	}
+	args = append(args, shellQuote(s.url))
+	return strings.Join(args, " "), nil
+}
+
+// PowerShell --------------------------------------------------------------
+
+func renderPowerShell(s *snapshot) (string, error) {
+	var b strings.Builder
+	var contentType string
+	var headers [][2]string
+	for _, h := range joinedHeaders(s.headers) {
+		// Invoke-WebRequest rejects Content-Type in -Headers for some bodies
+		if strings.EqualFold(h[0], "Content-Type") {
+			contentType = h[1]
+			continue
+		}
+		headers = append(headers, h)
+	}
+	if len(headers) > 0 {
+		b.WriteString("$headers = @{\n")
+		for _, h := range headers {
+			fmt.Fprintf(&b, "    %s = %s\n", psQuote(h[0]), psQuote(h[1]))
+		}
+		b.WriteString("}\n")
+	}
+	seen := map[string]bool{}
+	for _, p := range s.form {
+		if p.Type != "" || p.Filename != "" || seen[p.Name] {
+			return "", fmt.Errorf("powershell -Form cannot send repeated names or set the file name or content type of part %q", p.Name)
+		}
+		seen[p.Name] = true
+	}
+	if s.form != nil {
+		b.WriteString("$form = @{\n")
+		for _, p := range s.form {
+			if p.File != "" {
+				fmt.Fprintf(&b, "    %s = Get-Item -Path %s\n", psQuote(p.Name), psQuote(p.File))
+			} else {
+				fmt.Fprintf(&b, "    %s = %s\n", psQuote(p.Name), psQuote(p.Value))
+			}
+		}
+		b.WriteString("}\n")
+	}
+	if s.body != "" {
+		fmt.Fprintf(&b, "$body = %s\n", psQuote(s.body))
+	}
+
+	b.WriteString("Invoke-WebRequest")
+	fmt.Fprintf(&b, " -Method %s -Uri %s", psQuote(s.method), psQuote(s.url))
+	if len(headers) > 0 {
+		b.WriteString(" -Headers $headers")
+	}
+	if contentType != "" {
+		fmt.Fprintf(&b, " -ContentType %s", psQuote(contentType))
+	}
+	if s.form != nil {
+		b.WriteString(" -Form $form")
+	}
+	if s.file != "" {
+		fmt.Fprintf(&b, " -InFile %s", psQuote(s.file))
+	}
+	if s.body != "" {
+		b.WriteString(" -Body $body")
+	}
+	b.WriteString("\n")
+	return b.String(), nil
+}
+
+// Helper utilities ---------------------------------------------------------
+
+// joinedHeaders merges repeated headers into one comma‑separated value, for
+// targets whose header containers are dictionaries.
+func joinedHeaders(hs [][2]string) [][2]string {
+	var out [][2]string
+	for _, h := range hs {
+		if n := len(out); n > 0 && out[n-1][0] == h[0] {
+			out[n-1][1] += ", " + h[1]
+			continue
+		}
+		out = append(out, h)
+	}
+	return out
+}
+
+// jsQuote produces a double‑quoted string literal that is valid in both
+// JavaScript and Python.
+func jsQuote(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// psQuote puts a string in PowerShell single quotes (no interpolation).
+func psQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,359 @@
+package httpx
+
+import (
+	"go/format"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/suprbdev/reqo/internal/project"
+)
+
+func newExportRequest(t *testing.T, body string) *http.Request {
+	t.Helper()
+	var req *http.Request
+	var err error
+	if body != "" {
+		req, err = http.NewRequest("POST", "https://example.com/api?x=1", strings.NewReader(body))
+	} else {
+		req, err = http.NewRequest("GET", "https://example.com/api?x=1", nil)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("X-Token", "abc")
+	req.Header.Set("Accept", "application/json")
+	return req
+}
+
+func TestExport_UnknownLang(t *testing.T) {
+	req := newExportRequest(t, "")
+	if _, err := Export(req, "cobol"); err == nil {
+		t.Errorf("expected error for unknown language")
+	}
+}
+
+func TestExportAll_Snippets(t *testing.T) {
+	reqs := []*http.Request{newExportRequest(t, ""), newExportRequest(t, `{"a":1}`)}
+	out, err := ExportAll([]string{"list", "create"}, reqs, "curl")
+	if err != nil {
+		t.Fatalf("ExportAll() error: %v", err)
+	}
+	if !strings.HasPrefix(out, "# list\ncurl ") || !strings.Contains(out, "\n\n# create\ncurl ") {
+		t.Errorf("snippets should follow each other under a comment:\n%s", out)
+	}
+}
+
+func TestExportAll_GoProgram(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "a.txt")
+	os.WriteFile(file, []byte("x"), 0o644)
+	upload, err := BuildRequest(makeProject(), RequestSpec{
+		Method: "POST",
+		Path:   "/upload",
+		Form:   []project.FormField{{Name: "f", File: file}, {Name: "n", Value: "1"}},
+	})
+	if err != nil {
+		t.Fatalf("BuildRequest() error: %v", err)
+	}
+	reqs := []*http.Request{newExportRequest(t, ""), newExportRequest(t, `{"a":1}`), upload}
+	out, err := ExportAll([]string{"get-users", "get_users", "upload"}, reqs, "go")
+	if err != nil {
+		t.Fatalf("ExportAll() error: %v", err)
+	}
+	if strings.Count(out, "package main") != 1 || strings.Count(out, "func main()") != 1 {
+		t.Errorf("should be a single program:\n%s", out)
+	}
+	for _, want := range []string{
+		"func main() {\n\tcallGetUsers()\n\tcallGetUsers2()\n\tcallUpload()\n}\n",
+		"\n// get_users\nfunc callGetUsers2() {\n",
+		`strings.NewReader("{\"a\":1}")`,
+		"\t\"mime/multipart\"\n",
+		"func addFile(",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("program should contain %q:\n%s", want, out)
+		}
+	}
+	if src, err := format.Source([]byte(out)); err != nil || string(src) != out {
+		t.Errorf("program should be gofmt-clean: %v\n%s", err, out)
+	}
+}
+
+func TestExportAll_JSFetchModule(t *testing.T) {
+	reqs := []*http.Request{newExportRequest(t, ""), newExportRequest(t, `{"a":1}`)}
+	out, err := ExportAll([]string{"list", "create"}, reqs, "js-fetch")
+	if err != nil {
+		t.Fatalf("ExportAll() error: %v", err)
+	}
+	for _, want := range []string{
+		"// list\nasync function callList() {\n  const response = await fetch(",
+		"\n  console.log(await response.text());\n}\n",
+		"await callList();\nawait callCreate();\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("module should contain %q:\n%s", want, out)
+		}
+	}
+	if strings.Count(out, "const response") != 2 || strings.Contains(out, "\nconst response") {
+		t.Errorf("each request should be inside its own function:\n%s", out)
+	}
+}
+
+func TestExportLangs_Sorted(t *testing.T) {
+	langs := ExportLangs()
+	for i := 1; i < len(langs); i++ {
+		if langs[i-1] > langs[i] {
+			t.Errorf("ExportLangs() not sorted: %v", langs)
+		}
+	}
+	for _, want := range []string{"go", "python-requests", "js-fetch", "httpie", "wget", "powershell"} {
+		found := false
+		for _, l := range langs {
+			if l == want {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("ExportLangs() missing %q", want)
+		}
+	}
+}
+
+func TestExport_AllLangsWithBody(t *testing.T) {
+	body := `{"name":"it's \"quoted\""}`
+	for _, lang := range ExportLangs() {
+		out, err := Export(newExportRequest(t, body), lang)
+		if err != nil {
+			t.Errorf("Export(%s) error: %v", lang, err)
+			continue
+		}
+		if !strings.Contains(out, "https://example.com/api?x=1") {
+			t.Errorf("Export(%s) should contain URL: %q", lang, out)
+		}
+		if !strings.Contains(out, "X-Token") {
+			t.Errorf("Export(%s) should contain header: %q", lang, out)
+		}
+		if !strings.Contains(out, "POST") {
+			t.Errorf("Export(%s) should contain method: %q", lang, out)
+		}
+	}
+}
+
+func TestExport_Go(t *testing.T) {
+	out, err := Export(newExportRequest(t, `{"a":1}`), "go")
+	if err != nil {
+		t.Fatalf("Export() error: %v", err)
+	}
+	if !strings.HasPrefix(out, "package main") {
+		t.Errorf("Go snippet should be a program: %q", out)
+	}
+	if !strings.Contains(out, `strings.NewReader("{\"a\":1}")`) {
+		t.Errorf("Go snippet should quote body: %q", out)
+	}
+	if !strings.Contains(out, `req.Header.Add("X-Token", "abc")`) {
+		t.Errorf("Go snippet should set header: %q", out)
+	}
+}
+
+func TestExport_Python(t *testing.T) {
+	out, err := Export(newExportRequest(t, "line1\nline2"), "python-requests")
+	if err != nil {
+		t.Fatalf("Export() error: %v", err)
+	}
+	if !strings.Contains(out, `data = "line1\nline2"`) {
+		t.Errorf("Python snippet should escape newlines: %q", out)
+	}
+	if !strings.Contains(out, `requests.request("POST", url`) {
+		t.Errorf("Python snippet should call requests: %q", out)
+	}
+}
+
+func TestExport_HTTPieQuoting(t *testing.T) {
+	out, err := Export(newExportRequest(t, "it's"), "httpie")
+	if err != nil {
+		t.Fatalf("Export() error: %v", err)
+	}
+	if !strings.Contains(out, `--raw 'it'\''s'`) {
+		t.Errorf("httpie snippet should shell-quote body: %q", out)
+	}
+	if !strings.Contains(out, `'X-Token:abc'`) {
+		t.Errorf("httpie snippet should include header item: %q", out)
+	}
+}
+
+func TestExport_PowerShellQuoting(t *testing.T) {
+	out, err := Export(newExportRequest(t, "it's"), "powershell")
+	if err != nil {
+		t.Fatalf("Export() error: %v", err)
+	}
+	if !strings.Contains(out, `$body = 'it''s'`) {
+		t.Errorf("PowerShell snippet should double single quotes: %q", out)
+	}
+}
+
+func TestExport_HeaderOrderDeterministic(t *testing.T) {
+	first, _ := Export(newExportRequest(t, ""), "js-fetch")
+	for i := 0; i < 10; i++ {
+		again, _ := Export(newExportRequest(t, ""), "js-fetch")
+		if again != first {
+			t.Fatalf("export output not stable:\n%s\n---\n%s", first, again)
+		}
+	}
+	if strings.Index(first, "Accept") > strings.Index(first, "X-Token") {
+		t.Errorf("headers should be sorted: %q", first)
+	}
+}
+
+func TestExport_Multipart(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "avatar.png")
+	if err := os.WriteFile(file, []byte("png"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p := makeProject()
+	req, err := BuildRequest(p, RequestSpec{
+		Method: "POST",
+		Path:   "/upload",
+		Form:   []project.FormField{{Name: "avatar", File: file}, {Name: "name", Value: "John"}},
+	})
+	if err != nil {
+		t.Fatalf("BuildRequest() error: %v", err)
+	}
+
+	tests := map[string][]string{
+		"python-requests": {`("name", "John")`, `("avatar", open("` + file + `", "rb"))`},
+		"js-fetch":        {`form.append("name", "John")`, `readFile("` + file + `")`},
+		"httpie":          {"--multipart", "'name=John'", "'avatar@" + file + "'"},
+		"powershell":      {"-Form $form", "Get-Item -Path '" + file + "'"},
+		"go":              {`w.WriteField("name", "John")`, `addFile(w, "avatar", "` + file + `")`},
+	}
+	for lang, wants := range tests {
+		out, err := Export(req, lang)
+		if err != nil {
+			t.Errorf("Export(%s) error: %v", lang, err)
+			continue
+		}
+		for _, want := range wants {
+			if !strings.Contains(out, want) {
+				t.Errorf("Export(%s) should contain %q: %q", lang, want, out)
+			}
+		}
+		if strings.Contains(out, "boundary=") {
+			t.Errorf("Export(%s) should not hardcode multipart boundary: %q", lang, out)
+		}
+	}
+}
+
+func TestExport_BinaryFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "photo.jpg")
+	if err := os.WriteFile(file, []byte("\xff\xd8"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	bin := "@" + file
+	req, err := BuildRequest(makeProject(), RequestSpec{Method: "PUT", Path: "/photo", BinaryBody: &bin})
+	if err != nil {
+		t.Fatalf("BuildRequest() error: %v", err)
+	}
+
+	tests := map[string]string{
+		"python-requests": `data = open("` + file + `", "rb")`,
+		"js-fetch":        `body: await readFile("` + file + `")`,
+		"httpie":          "< '" + file + "'",
+		"wget":            "--body-file='" + file + "'",
+		"powershell":      "-InFile '" + file + "'",
+		"go":              `os.Open("` + file + `")`,
+	}
+	for lang, want := range tests {
+		out, err := Export(req, lang)
+		if err != nil {
+			t.Errorf("Export(%s) error: %v", lang, err)
+			continue
+		}
+		if !strings.Contains(out, want) || !strings.Contains(out, "image/jpeg") {
+			t.Errorf("Export(%s) should contain %q and the content type: %q", lang, want, out)
+		}
+	}
+
+	stdin := "@-"
+	req, _ = BuildRequest(makeProject(), RequestSpec{Method: "PUT", Path: "/photo", BinaryBody: &stdin})
+	if _, err := Export(req, "go"); err == nil {
+		t.Errorf("exporting a stdin body should error")
+	}
+}
+
+func TestExport_MultipartParams(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "me.bin")
+	if err := os.WriteFile(file, []byte("png"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	req, err := BuildRequest(makeProject(), RequestSpec{
+		Method: "POST",
+		Path:   "/upload",
+		Form: []project.FormField{
+			{Name: "photo", File: file, Type: "image/png", Filename: "x.png"},
+			{Name: "meta", JSON: map[string]interface{}{"a": 1}},
+			{Name: "tags", Value: "a"},
+			{Name: "tags", Value: "b"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("BuildRequest() error: %v", err)
+	}
+
+	tests := map[string][]string{
+		"python-requests": {`("photo", ("x.png", open("` + file + `", "rb"), "image/png"))`, `("meta", (None, "{\"a\":1}", "application/json"))`, `("tags", "b")`},
+		"js-fetch":        {`new Blob([await readFile("` + file + `")], { type: "image/png" }), "x.png")`, `new Blob(["{\"a\":1}"], { type: "application/json" })`},
+		"go":              {`addPart(w, "photo", "x.png", "image/png", "` + file + `", "")`, `"net/textproto"`, `w.WriteField("tags", "b")`},
+	}
+	for lang, wants := range tests {
+		out, err := Export(req, lang)
+		if err != nil {
+			t.Errorf("Export(%s) error: %v", lang, err)
+			continue
+		}
+		for _, want := range wants {
+			if !strings.Contains(out, want) {
+				t.Errorf("Export(%s) should contain %q: %q", lang, want, out)
+			}
+		}
+	}
+	for _, lang := range []string{"httpie", "powershell"} {
+		if _, err := Export(req, lang); err == nil {
+			t.Errorf("Export(%s) should reject parts it cannot express", lang)
+		}
+	}
+}
+
+func TestExport_WgetRejectsMultipart(t *testing.T) {
+	p := makeProject()
+	req, err := BuildRequest(p, RequestSpec{
+		Method: "POST",
+		Path:   "/upload",
+		Form:   []project.FormField{{Name: "name", Value: "John"}},
+	})
+	if err != nil {
+		t.Fatalf("BuildRequest() error: %v", err)
+	}
+	if _, err := Export(req, "wget"); err == nil {
+		t.Errorf("wget export of multipart body should error")
+	}
+}
+
+func TestExportComment(t *testing.T) {
+	if got := ExportComment("go", "alias"); got != "// alias" {
+		t.Errorf("ExportComment(go) = %q", got)
+	}
+	if got := ExportComment("httpie", "alias"); got != "# alias" {
+		t.Errorf("ExportComment(httpie) = %q", got)
+	}
+}
+
+func TestFormParts_NonMultipart(t *testing.T) {
+	req, _ := http.NewRequest("GET", "https://example.com", nil)
+	if FormParts(req) != nil {
+		t.Errorf("FormParts() should be nil for plain requests")
+	}
+}
@@ -7,21 +7,56 @@ import (
 	"strings"
 )
 
+// CurlOpts mirrors execution options in the generated curl command.
+type CurlOpts struct {
+	Compressed bool // add --compressed
+	Insecure   bool // add -k (skip TLS verification)
+	Redact     bool // mask credentials (see RedactRequest)
+}
+
 // AsCurl returns a string that reproduces the request with the curl CLI.
 func AsCurl(req *http.Request) (string, error) {
+	return AsCurlOpts(req, CurlOpts{})
+}
+
+// AsCurlOpts is AsCurl with extra flags. Headers are written in sorted order
+// so the output is stable across runs and can be diffed.
+func AsCurlOpts(req *http.Request, opts CurlOpts) (string, error) {
+	if opts.Redact {
+		req = RedactRequest(req)
+	}
+	form := FormParts(req)
+
 	var b strings.Builder
 	b.WriteString("curl -X ")
 	b.WriteString(req.Method)
+	if opts.Compressed {
+		b.WriteString(" --compressed")
+	}
+	if opts.Insecure {
+		b.WriteString(" -k")
+	}
 
 	// headers
-	for k, vals := range req.Header {
-		for _, v := range vals {
+	for _, k := range sortedHeaderKeys(req.Header) {
+		// curl generates its own multipart boundary
+		if form != nil && strings.EqualFold(k, "Content-Type") {
+			continue
+		}
+		for _, v := range req.Header[k] {
 			b.WriteString(fmt.Sprintf(` -H "%s: %s"`, escape(k), escape(v)))
 		}
 	}
 
 	// body (if any)
-	if req.Body != nil && req.GetBody != nil {
+	switch {
+	case BinaryFile(req) != "":
+		b.WriteString(" --data-binary " + shellQuote("@"+BinaryFile(req)))
+	case form != nil:
+		for _, p := range form {
+			b.WriteString(curlFormArg(p))
+		}
+	case req.Body != nil && req.GetBody != nil:
 		bodyCopy, err := req.GetBody()
 		if err == nil {
 			buf := new(bytes.Buffer)
@@ -41,9 +76,31 @@ func AsCurl(req *http.Request) (string, error) {
 	return b.String(), nil
 }
 
-// escape makes sure double quotes inside a header value are escaped.
+// curlFormArg renders one multipart part as a -F or --form-string option.
+func curlFormArg(p FormPart) string {
+	var params string
+	if p.Type != "" {
+		params += ";type=" + p.Type
+	}
+	if p.Filename != "" {
+		params += ";filename=" + p.Filename
+	}
+	switch {
+	case p.File != "":
+		return " -F " + shellQuote(p.Name+"=@"+p.File+params)
+	case params == "":
+		// --form-string never treats a leading @ or < specially
+		return " --form-string " + shellQuote(p.Name+"="+p.Value)
+	default:
+		// a double-quoted value keeps ";" and a leading @ or < literal
+		return " -F " + shellQuote(p.Name+`="`+strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(p.Value)+`"`+params)
+	}
+}
+
+// escape makes a string safe inside a double‑quoted shell word.
 func escape(s string) string {
-	return strings.ReplaceAll(s, `"`, `\"`)
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "`", "\\`")
+	return r.Replace(s)
 }
 
 // shellQuote puts a string in single‑quotes and escapes existing ones.
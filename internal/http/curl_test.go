@@ -1,9 +1,12 @@
 package httpx
 
 import (
+	"context"
 	"net/http"
 	"strings"
 	"testing"
+
+	"github.com/suprbdev/reqo/internal/project"
 )
 
 func TestAsCurl_BasicGet(t *testing.T) {
@@ -152,3 +155,130 @@ func TestAsCurl_StripsUserinfo(t *testing.T) {
 		t.Errorf("output should not contain userinfo: %q", out)
 	}
 }
+
+func TestAsCurl_HeaderOrderDeterministic(t *testing.T) {
+	req, _ := http.NewRequest("GET", "https://example.com", nil)
+	req.Header.Set("X-Zeta", "z")
+	req.Header.Set("Accept", "a")
+	req.Header.Set("X-Alpha", "x")
+	first, _ := AsCurl(req)
+	for i := 0; i < 20; i++ {
+		again, _ := AsCurl(req)
+		if again != first {
+			t.Fatalf("AsCurl() output not stable: %q vs %q", first, again)
+		}
+	}
+	a := strings.Index(first, "Accept")
+	x := strings.Index(first, "X-Alpha")
+	z := strings.Index(first, "X-Zeta")
+	if !(a < x && x < z) {
+		t.Errorf("headers should be sorted by name: %q", first)
+	}
+}
+
+func TestAsCurl_Multipart(t *testing.T) {
+	dir := t.TempDir()
+	file := dir + "/avatar.png"
+	if err := writeFile(file, "png"); err != nil {
+		t.Fatal(err)
+	}
+	req, err := BuildRequest(makeProject(), RequestSpec{
+		Method: "POST",
+		Path:   "/upload",
+		Form:   []project.FormField{{Name: "avatar", File: file}, {Name: "note", Value: "hello"}},
+	})
+	if err != nil {
+		t.Fatalf("BuildRequest() error: %v", err)
+	}
+	out, _ := AsCurl(req)
+	if !strings.Contains(out, "-F 'avatar=@"+file+"'") {
+		t.Errorf("file part should use -F: %q", out)
+	}
+	if !strings.Contains(out, "--form-string 'note=hello'") {
+		t.Errorf("field part should use --form-string: %q", out)
+	}
+	if strings.Contains(out, "--data-raw") || strings.Contains(out, "boundary=") {
+		t.Errorf("multipart body should not be inlined: %q", out)
+	}
+}
+
+func TestAsCurl_BinaryFile(t *testing.T) {
+	file := t.TempDir() + "/blob.bin"
+	if err := writeFile(file, "\x00\x01${x}"); err != nil {
+		t.Fatal(err)
+	}
+	bin := "@" + file
+	req, err := BuildRequest(makeProject(), RequestSpec{Method: "PUT", Path: "/blob", BinaryBody: &bin})
+	if err != nil {
+		t.Fatalf("BuildRequest() error: %v", err)
+	}
+	out, _ := AsCurl(req)
+	if !strings.Contains(out, "--data-binary '@"+file+"'") {
+		t.Errorf("binary body should reference the file: %q", out)
+	}
+	if strings.Contains(out, "--data-raw") {
+		t.Errorf("binary body should not be inlined: %q", out)
+	}
+}
+
+func TestAsCurl_MultipartParams(t *testing.T) {
+	req, _ := http.NewRequest("POST", "https://example.com/upload", nil)
+	req = req.WithContext(context.WithValue(req.Context(), formPartsKey{}, []FormPart{
+		{Name: "photo", File: "me.bin", Type: "image/png", Filename: "x.png"},
+		{Name: "meta", Value: `{"a":"b;c"}`, Type: "application/json"},
+		{Name: "tags[]", Value: "a"},
+	}))
+	out, _ := AsCurl(req)
+	for _, want := range []string{
+		`-F 'photo=@me.bin;type=image/png;filename=x.png'`,
+		`-F 'meta="{\"a\":\"b;c\"}";type=application/json'`,
+		`--form-string 'tags[]=a'`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output should contain %s: %q", want, out)
+		}
+	}
+}
+
+func TestAsCurlOpts_Flags(t *testing.T) {
+	req, _ := http.NewRequest("GET", "https://example.com", nil)
+	out, _ := AsCurlOpts(req, CurlOpts{Compressed: true, Insecure: true})
+	if !strings.Contains(out, " --compressed") {
+		t.Errorf("should contain --compressed: %q", out)
+	}
+	if !strings.Contains(out, " -k") {
+		t.Errorf("should contain -k: %q", out)
+	}
+	plain, _ := AsCurl(req)
+	if strings.Contains(plain, "--compressed") || strings.Contains(plain, " -k") {
+		t.Errorf("flags should be opt-in: %q", plain)
+	}
+}
+
+func TestAsCurlOpts_Redact(t *testing.T) {
+	req, _ := http.NewRequest("GET", "https://example.com/api?api_key=s3cr3t&page=2", nil)
+	req.Header.Set("Authorization", "Bearer s3cr3t")
+	req.Header.Set("Cookie", "session=s3cr3t")
+	req.Header.Set("X-Request-Id", "abc")
+	out, _ := AsCurlOpts(req, CurlOpts{Redact: true})
+	if strings.Contains(out, "s3cr3t") {
+		t.Errorf("secrets should be redacted: %q", out)
+	}
+	if !strings.Contains(out, "Authorization: Bearer "+RedactedValue) {
+		t.Errorf("auth scheme should be kept: %q", out)
+	}
+	if !strings.Contains(out, "X-Request-Id: abc") || !strings.Contains(out, "page=2") {
+		t.Errorf("non-sensitive values should be kept: %q", out)
+	}
+	if req.Header.Get("Authorization") != "Bearer s3cr3t" {
+		t.Errorf("original request should not be modified")
+	}
+}
+
+func TestEscape_ShellMetacharacters(t *testing.T) {
+	got := escape("$HOME `id` \\")
+	want := "\\$HOME \\`id\\` \\\\"
+	if got != want {
+		t.Errorf("escape() = %q, want %q", got, want)
+	}
+}
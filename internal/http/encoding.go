@@ -0,0 +1,258 @@
+package httpx
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// AcceptEncodings is the Accept-Encoding sent for --compressed: every
+// encoding DecodeResponse understands.
+const AcceptEncodings = "gzip, deflate, br, zstd"
+
+// RequestEncodings lists the encodings CompressRequest can produce.
+var RequestEncodings = []string{"gzip", "deflate", "zstd"}
+
+// maxBufferedCompress is the largest body compressed in memory so that the
+// request keeps an exact Content-Length; larger or unknown-length bodies are
+// compressed while they are sent, using chunked transfer encoding.
+const maxBufferedCompress = 32 << 20
+
+// CompressRequest compresses the body of req with encoding (one of
+// RequestEncodings) and sets Content-Encoding. Requests without a body are
+// left alone.
+func CompressRequest(req *http.Request, encoding string) error {
+	if !slices.Contains(RequestEncodings, encoding) {
+		return fmt.Errorf("unsupported compression %q (supported: %s)", encoding, strings.Join(RequestEncodings, ", "))
+	}
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+	req.Header.Set("Content-Encoding", encoding)
+	if req.ContentLength >= 0 && req.ContentLength <= maxBufferedCompress {
+		var b bytes.Buffer
+		w, _ := newEncoder(encoding, &b)
+		_, err := io.Copy(w, req.Body)
+		req.Body.Close()
+		if err == nil {
+			err = w.Close()
+		}
+		if err != nil {
+			return fmt.Errorf("compress body: %w", err)
+		}
+		data := b.Bytes()
+		req.Body = io.NopCloser(bytes.NewReader(data))
+		req.ContentLength = int64(len(data))
+		req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(data)), nil }
+		return nil
+	}
+	getBody := req.GetBody
+	req.Body = compressPipe(req.Body, encoding)
+	req.ContentLength = -1
+	req.GetBody = nil
+	if getBody != nil {
+		req.GetBody = func() (io.ReadCloser, error) {
+			src, err := getBody()
+			if err != nil {
+				return nil, err
+			}
+			return compressPipe(src, encoding), nil
+		}
+	}
+	return nil
+}
+
+// compressPipe returns a reader yielding src compressed on the fly.
+func compressPipe(src io.ReadCloser, encoding string) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		defer src.Close()
+		w, _ := newEncoder(encoding, pw)
+		_, err := io.Copy(w, src)
+		if cerr := w.Close(); err == nil {
+			err = cerr
+		}
+		pw.CloseWithError(err)
+	}()
+	return pr
+}
+
+func newEncoder(encoding string, w io.Writer) (io.WriteCloser, error) {
+	switch encoding {
+	case "gzip":
+		return gzip.NewWriter(w), nil
+	case "deflate":
+		return zlib.NewWriter(w), nil
+	case "zstd":
+		return zstd.NewWriter(w)
+	}
+	return nil, fmt.Errorf("unsupported compression %q", encoding)
+}
+
+// BodySize reports the size of a response body on the wire and after
+// decoding. Both are complete once the body has been read and closed.
+type BodySize struct {
+	Encoding string // Content-Encoding of the response, "" when not encoded
+
+	encoded, decoded *countingReader
+	mu               sync.Mutex
+	wait             chan struct{} // closed when a background decode ends
+	background       int64
+}
+
+// Encoded returns the number of body bytes received.
+func (s *BodySize) Encoded() int64 { return s.encoded.n }
+
+// Decoded returns the size of the decoded body, or -1 when the encoding is
+// not supported or the body could not be decoded.
+func (s *BodySize) Decoded() int64 {
+	if s.wait != nil {
+		<-s.wait
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return s.background
+	}
+	if s.decoded == nil {
+		return -1
+	}
+	return s.decoded.n
+}
+
+// DecodeResponse measures the body of resp and, when decode is set,
+// replaces it with the decoded content: gzip, deflate, br and zstd are
+// understood. A decoded response loses its Content-Encoding and
+// Content-Length headers as with Go's own transparent gzip handling. When
+// decode is false the body is passed through untouched and its decoded size
+// is computed on the side.
+func DecodeResponse(resp *http.Response, decode bool) *BodySize {
+	s := &BodySize{encoded: &countingReader{r: resp.Body}}
+	enc := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+	if enc == "identity" {
+		enc = ""
+	}
+	s.Encoding = enc
+	body := resp.Body
+	switch {
+	case enc == "" || !bodyAllowed(resp):
+		s.decoded = s.encoded
+		resp.Body = readCloser{s.encoded, body.Close}
+	case !supportedEncoding(enc):
+		resp.Body = readCloser{s.encoded, body.Close}
+	case decode:
+		s.decoded = &countingReader{r: &lazyDecoder{encoding: enc, src: s.encoded}}
+		resp.Body = readCloser{s.decoded, body.Close}
+		resp.Header.Del("Content-Encoding")
+		resp.Header.Del("Content-Length")
+		resp.ContentLength = -1
+		resp.Uncompressed = true
+	default:
+		pr, pw := io.Pipe()
+		s.wait = make(chan struct{})
+		go func() {
+			defer close(s.wait)
+			n, err := io.Copy(io.Discard, &lazyDecoder{encoding: enc, src: pr})
+			io.Copy(io.Discard, pr) // keep the tee flowing after a decode error
+			if err != nil {
+				n = -1
+			}
+			s.mu.Lock()
+			s.background = n
+			s.mu.Unlock()
+		}()
+		resp.Body = readCloser{io.TeeReader(s.encoded, pw), func() error {
+			err := body.Close()
+			pw.Close()
+			return err
+		}}
+	}
+	return s
+}
+
+func bodyAllowed(resp *http.Response) bool {
+	if resp.Request != nil && resp.Request.Method == http.MethodHead {
+		return false
+	}
+	return resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusNotModified
+}
+
+func supportedEncoding(enc string) bool {
+	switch enc {
+	case "gzip", "x-gzip", "deflate", "br", "zstd":
+		return true
+	}
+	return false
+}
+
+// lazyDecoder creates its decompressor on the first Read, so an empty body
+// is not an error.
+type lazyDecoder struct {
+	encoding string
+	src      io.Reader
+	r        io.Reader
+}
+
+func (d *lazyDecoder) Read(p []byte) (int, error) {
+	if d.r == nil {
+		br := bufio.NewReader(d.src)
+		if _, err := br.Peek(1); err == io.EOF {
+			return 0, io.EOF
+		}
+		r, err := newDecoder(d.encoding, br)
+		if err != nil {
+			return 0, fmt.Errorf("decode %s response: %w", d.encoding, err)
+		}
+		d.r = r
+	}
+	return d.r.Read(p)
+}
+
+func newDecoder(encoding string, r *bufio.Reader) (io.Reader, error) {
+	switch encoding {
+	case "gzip", "x-gzip":
+		return gzip.NewReader(r)
+	case "deflate":
+		// RFC 9110 deflate is zlib‑wrapped, but some servers send raw DEFLATE
+		if h, err := r.Peek(2); err == nil && h[0]&0x0f == 8 && (uint16(h[0])<<8|uint16(h[1]))%31 == 0 {
+			return zlib.NewReader(r)
+		}
+		return flate.NewReader(r), nil
+	case "br":
+		return brotli.NewReader(r), nil
+	case "zstd":
+		d, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		return d.IOReadCloser(), nil
+	}
+	return nil, fmt.Errorf("unsupported encoding %q", encoding)
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+type readCloser struct {
+	io.Reader
+	close func() error
+}
+
+func (r readCloser) Close() error { return r.close() }
@@ -0,0 +1,174 @@
+package httpx
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+func encode(t *testing.T, encoding string, data string) []byte {
+	t.Helper()
+	var b bytes.Buffer
+	var w io.WriteCloser
+	switch encoding {
+	case "gzip":
+		w = gzip.NewWriter(&b)
+	case "deflate":
+		w = zlib.NewWriter(&b)
+	case "raw-deflate":
+		w, _ = flate.NewWriter(&b, flate.DefaultCompression)
+	case "br":
+		w = brotli.NewWriter(&b)
+	case "zstd":
+		w, _ = zstd.NewWriter(&b)
+	}
+	w.Write([]byte(data))
+	w.Close()
+	return b.Bytes()
+}
+
+func decode(t *testing.T, encoding string, data []byte) string {
+	t.Helper()
+	resp := &http.Response{StatusCode: 200, Header: http.Header{"Content-Encoding": {encoding}}, Body: io.NopCloser(bytes.NewReader(data))}
+	DecodeResponse(resp, true)
+	got, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("decode %s: %v", encoding, err)
+	}
+	return string(got)
+}
+
+func TestCompressRequest(t *testing.T) {
+	for _, enc := range RequestEncodings {
+		t.Run(enc, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", "http://x", strings.NewReader("hello hello hello"))
+			if err := CompressRequest(req, enc); err != nil {
+				t.Fatal(err)
+			}
+			if req.Header.Get("Content-Encoding") != enc {
+				t.Errorf("Content-Encoding = %q", req.Header.Get("Content-Encoding"))
+			}
+			body, _ := io.ReadAll(req.Body)
+			if int64(len(body)) != req.ContentLength {
+				t.Errorf("ContentLength = %d, body is %d bytes", req.ContentLength, len(body))
+			}
+			if got := decode(t, enc, body); got != "hello hello hello" {
+				t.Errorf("round trip = %q", got)
+			}
+			again, _ := req.GetBody()
+			if b, _ := io.ReadAll(again); !bytes.Equal(b, body) {
+				t.Error("GetBody should return the compressed body")
+			}
+		})
+	}
+}
+
+func TestCompressRequest_Streamed(t *testing.T) {
+	req, _ := http.NewRequest("POST", "http://x", nil)
+	req.Body = io.NopCloser(strings.NewReader("streamed"))
+	req.ContentLength = -1
+	if err := CompressRequest(req, "zstd"); err != nil {
+		t.Fatal(err)
+	}
+	if req.ContentLength != -1 || req.GetBody != nil {
+		t.Errorf("unknown-length body should stay streamed: %d", req.ContentLength)
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := decode(t, "zstd", body); got != "streamed" {
+		t.Errorf("round trip = %q", got)
+	}
+}
+
+func TestCompressRequest_Errors(t *testing.T) {
+	req, _ := http.NewRequest("GET", "http://x", nil)
+	if err := CompressRequest(req, "br"); err == nil || !strings.Contains(err.Error(), "unsupported compression") {
+		t.Errorf("br should not be accepted for requests: %v", err)
+	}
+	if err := CompressRequest(req, "gzip"); err != nil || req.Header.Get("Content-Encoding") != "" {
+		t.Errorf("a request without a body should be left alone: %v", err)
+	}
+}
+
+func TestDecodeResponse(t *testing.T) {
+	const body = `{"message":"compressed"}`
+	for _, enc := range []string{"gzip", "deflate", "raw-deflate", "br", "zstd"} {
+		t.Run(enc, func(t *testing.T) {
+			header := enc
+			if enc == "raw-deflate" {
+				header = "deflate"
+			}
+			data := encode(t, enc, body)
+			resp := &http.Response{
+				StatusCode:    200,
+				Header:        http.Header{"Content-Encoding": {header}, "Content-Length": {"1"}},
+				ContentLength: int64(len(data)),
+				Body:          io.NopCloser(bytes.NewReader(data)),
+			}
+			size := DecodeResponse(resp, true)
+			got, err := io.ReadAll(resp.Body)
+			if err != nil || string(got) != body {
+				t.Fatalf("body = %q, %v", got, err)
+			}
+			if resp.Header.Get("Content-Encoding") != "" || resp.Header.Get("Content-Length") != "" || resp.ContentLength != -1 {
+				t.Error("encoding headers should be removed from a decoded response")
+			}
+			if size.Encoding != header || size.Encoded() != int64(len(data)) || size.Decoded() != int64(len(body)) {
+				t.Errorf("size = %s %d/%d", size.Encoding, size.Encoded(), size.Decoded())
+			}
+		})
+	}
+}
+
+func TestDecodeResponse_Keep(t *testing.T) {
+	data := encode(t, "gzip", "abcdefabcdef")
+	resp := &http.Response{StatusCode: 200, Header: http.Header{"Content-Encoding": {"gzip"}}, Body: io.NopCloser(bytes.NewReader(data))}
+	size := DecodeResponse(resp, false)
+	got, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !bytes.Equal(got, data) || resp.Header.Get("Content-Encoding") != "gzip" {
+		t.Error("body should be passed through encoded")
+	}
+	if size.Encoded() != int64(len(data)) || size.Decoded() != 12 {
+		t.Errorf("size = %d/%d", size.Encoded(), size.Decoded())
+	}
+}
+
+func TestDecodeResponse_NoBody(t *testing.T) {
+	for _, resp := range []*http.Response{
+		{StatusCode: 200, Body: http.NoBody, Header: http.Header{"Content-Encoding": {"gzip"}}, Request: &http.Request{Method: "HEAD"}},
+		{StatusCode: 200, Body: http.NoBody, Header: http.Header{"Content-Encoding": {"gzip"}}},
+		{StatusCode: 200, Body: io.NopCloser(strings.NewReader("plain")), Header: http.Header{}},
+	} {
+		size := DecodeResponse(resp, true)
+		got, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Errorf("read: %v", err)
+		}
+		if size.Decoded() != int64(len(got)) {
+			t.Errorf("decoded = %d, want %d", size.Decoded(), len(got))
+		}
+	}
+}
+
+func TestDecodeResponse_Unsupported(t *testing.T) {
+	resp := &http.Response{StatusCode: 200, Header: http.Header{"Content-Encoding": {"compress"}}, Body: io.NopCloser(strings.NewReader("xyz"))}
+	size := DecodeResponse(resp, true)
+	got, _ := io.ReadAll(resp.Body)
+	if string(got) != "xyz" || resp.Header.Get("Content-Encoding") != "compress" {
+		t.Error("an unknown encoding should be left as is")
+	}
+	if size.Encoded() != 3 || size.Decoded() != -1 {
+		t.Errorf("size = %d/%d", size.Encoded(), size.Decoded())
+	}
+}
@@ -0,0 +1,164 @@
+package httpx
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/suprbdev/reqo/internal/template"
+)
+
+// GraphQLBody is a GraphQL operation, sent as a JSON POST body.
+type GraphQLBody struct {
+	Query         string      // query document or @file.graphql
+	Variables     interface{} // decoded JSON/YAML; string values are template‑expanded
+	OperationName string
+}
+
+type graphQLKey struct{}
+
+// IsGraphQL reports whether BuildRequest encoded a GraphQL operation into req.
+func IsGraphQL(req *http.Request) bool {
+	ok, _ := req.Context().Value(graphQLKey{}).(bool)
+	return ok
+}
+
+// ParseGraphQLVariables decodes a --variables value (JSON or @file).
+// Template expansion happens later on the decoded values, so substituted
+// text never has to be JSON‑escaped by hand.
+func ParseGraphQLVariables(v string) (interface{}, error) {
+	data, err := readPossiblyFile(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(strings.NewReader(data))
+	dec.UseNumber()
+	var out interface{}
+	if err := dec.Decode(&out); err != nil {
+		return nil, fmt.Errorf("invalid GraphQL variables: %w", err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("invalid GraphQL variables: invalid character after top-level value")
+	}
+	return exactNumbers(out), nil
+}
+
+// exactNumbers turns the json.Numbers in v into int64 when they are
+// integers that fit, so ids above 2^53 are sent as given, and into float64
+// otherwise. Unlike json.Number both are saved to YAML as numbers.
+func exactNumbers(v interface{}) interface{} {
+	switch t := v.(type) {
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return i
+		}
+		f, _ := t.Float64()
+		return f
+	case map[string]interface{}:
+		for k, val := range t {
+			t[k] = exactNumbers(val)
+		}
+	case []interface{}:
+		for i, val := range t {
+			t[i] = exactNumbers(val)
+		}
+	}
+	return v
+}
+
+func encodeGraphQL(g *GraphQLBody, vars map[string]string) ([]byte, error) {
+	query, err := readPossiblyFile(g.Query)
+	if err != nil {
+		return nil, err
+	}
+	if query == "" {
+		return nil, fmt.Errorf("GraphQL query is empty")
+	}
+	payload := map[string]interface{}{"query": query}
+	if g.Variables != nil {
+		payload["variables"] = template.ExpandValue(g.Variables, vars)
+	}
+	if g.OperationName != "" {
+		payload["operationName"] = g.OperationName
+	}
+	return json.Marshal(payload)
+}
+
+// IntrospectionQuery is the standard query used by GraphQL tooling to fetch
+// a server's schema.
+const IntrospectionQuery = `query IntrospectionQuery {
+  __schema {
+    queryType { name }
+    mutationType { name }
+    subscriptionType { name }
+    types { ...FullType }
+    directives {
+      name
+      description
+      locations
+      args { ...InputValue }
+    }
+  }
+}
+
+fragment FullType on __Type {
+  kind
+  name
+  description
+  fields(includeDeprecated: true) {
+    name
+    description
+    args { ...InputValue }
+    type { ...TypeRef }
+    isDeprecated
+    deprecationReason
+  }
+  inputFields { ...InputValue }
+  interfaces { ...TypeRef }
+  enumValues(includeDeprecated: true) {
+    name
+    description
+    isDeprecated
+    deprecationReason
+  }
+  possibleTypes { ...TypeRef }
+}
+
+fragment InputValue on __InputValue {
+  name
+  description
+  type { ...TypeRef }
+  defaultValue
+}
+
+fragment TypeRef on __Type {
+  kind
+  name
+  ofType {
+    kind
+    name
+    ofType {
+      kind
+      name
+      ofType {
+        kind
+        name
+        ofType {
+          kind
+          name
+          ofType {
+            kind
+            name
+            ofType {
+              kind
+              name
+              ofType { kind name }
+            }
+          }
+        }
+      }
+    }
+  }
+}`
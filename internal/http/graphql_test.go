@@ -0,0 +1,103 @@
+package httpx
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func decodeBody(t *testing.T, req *http.Request) map[string]interface{} {
+	t.Helper()
+	b, _ := io.ReadAll(req.Body)
+	var m map[string]interface{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		t.Fatalf("body is not JSON: %s", b)
+	}
+	return m
+}
+
+func TestBuildRequest_GraphQL(t *testing.T) {
+	p := makeProject()
+	req, err := BuildRequest(p, RequestSpec{
+		Path: "@",
+		GraphQL: &GraphQLBody{
+			Query:         "query User($id: ID!) { user(id: $id) { name } }",
+			Variables:     map[string]interface{}{"id": "${id}", "filter": map[string]interface{}{"q": "${q}"}, "limit": 5.0},
+			OperationName: "User",
+		},
+		Vars: map[string]string{"id": "42", "q": "say \"hi\"\nbye"},
+	})
+	if err != nil {
+		t.Fatalf("BuildRequest() error: %v", err)
+	}
+	if req.Method != http.MethodPost {
+		t.Errorf("Method = %q, want POST", req.Method)
+	}
+	if req.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("Content-Type = %q", req.Header.Get("Content-Type"))
+	}
+	if !IsGraphQL(req) {
+		t.Error("IsGraphQL() should be true")
+	}
+	m := decodeBody(t, req)
+	if m["operationName"] != "User" {
+		t.Errorf("operationName = %v", m["operationName"])
+	}
+	vars := m["variables"].(map[string]interface{})
+	if vars["id"] != "42" || vars["limit"] != 5.0 {
+		t.Errorf("variables = %v", vars)
+	}
+	if vars["filter"].(map[string]interface{})["q"] != "say \"hi\"\nbye" {
+		t.Errorf("quotes and newlines must survive expansion: %v", vars["filter"])
+	}
+}
+
+func TestBuildRequest_GraphQLFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.graphql")
+	os.WriteFile(path, []byte("{ users { id } }"), 0o644)
+	req, err := BuildRequest(makeProject(), RequestSpec{Path: "/graphql", GraphQL: &GraphQLBody{Query: "@" + path}})
+	if err != nil {
+		t.Fatalf("BuildRequest() error: %v", err)
+	}
+	m := decodeBody(t, req)
+	if m["query"] != "{ users { id } }" {
+		t.Errorf("query = %v", m["query"])
+	}
+	if _, ok := m["variables"]; ok {
+		t.Error("variables should be omitted when unset")
+	}
+}
+
+func TestBuildRequest_GraphQLConflict(t *testing.T) {
+	js := `{}`
+	_, err := BuildRequest(makeProject(), RequestSpec{Path: "/", JSONBody: &js, GraphQL: &GraphQLBody{Query: "{ a }"}})
+	if err == nil {
+		t.Error("GraphQL with --json should error")
+	}
+}
+
+func TestBuildRequest_NotGraphQL(t *testing.T) {
+	req, _ := BuildRequest(makeProject(), RequestSpec{Path: "/"})
+	if IsGraphQL(req) {
+		t.Error("plain request reported as GraphQL")
+	}
+}
+
+func TestParseGraphQLVariables(t *testing.T) {
+	v, err := ParseGraphQLVariables(`{"n": 1, "id": 9007199254740993, "ids": [2.5]}`)
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+	m := v.(map[string]interface{})
+	if m["n"] != int64(1) || m["id"] != int64(9007199254740993) || m["ids"].([]interface{})[0] != 2.5 {
+		t.Errorf("v = %#v", v)
+	}
+	for _, bad := range []string{`{n: 1}`, `{"n": 1} {}`} {
+		if _, err := ParseGraphQLVariables(bad); err == nil {
+			t.Errorf("invalid JSON %s should error", bad)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package httpx
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/textproto"
+	"path/filepath"
+	"strings"
+
+	"github.com/suprbdev/reqo/internal/project"
+	"github.com/suprbdev/reqo/internal/template"
+)
+
+// encodeMultipart writes fields in order. File parts are not read here: the
+// multipart framing is kept as bytes and the files are streamed when the
+// body is sent. Templates are expanded in names, values, paths and the
+// string leaves of JSON parts, never in file contents.
+func encodeMultipart(fields []project.FormField, vars map[string]string) (*streamBody, []FormPart, string, error) {
+	var b bytes.Buffer
+	stream := &streamBody{}
+	parts := make([]FormPart, 0, len(fields))
+	w := multipart.NewWriter(&b)
+	for _, f := range fields {
+		p := FormPart{
+			Name:     template.Expand(f.Name, vars),
+			Type:     template.Expand(f.Type, vars),
+			Filename: template.Expand(f.Filename, vars),
+		}
+		h := make(textproto.MIMEHeader)
+		switch {
+		case f.File != "":
+			p.File = template.Expand(f.File, vars)
+			filename := p.Filename
+			if filename == "" {
+				filename = filepath.Base(p.File)
+			}
+			ct := p.Type
+			if ct == "" {
+				ct = binaryContentType(p.File)
+			}
+			h.Set("Content-Disposition", formDisposition(p.Name, filename))
+			h.Set("Content-Type", ct)
+			if _, err := w.CreatePart(h); err != nil {
+				return nil, nil, "", err
+			}
+			stream.addBytes(b.Bytes())
+			b.Reset()
+			if err := stream.addFile(p.File); err != nil {
+				return nil, nil, "", fmt.Errorf("open form file %s: %w", p.File, err)
+			}
+		default:
+			if f.JSON != nil {
+				data, err := json.Marshal(template.ExpandValue(f.JSON, vars))
+				if err != nil {
+					return nil, nil, "", fmt.Errorf("form field %s: %w", p.Name, err)
+				}
+				p.Value = string(data)
+				if p.Type == "" {
+					p.Type = "application/json"
+				}
+			} else {
+				p.Value = template.Expand(f.Value, vars)
+			}
+			h.Set("Content-Disposition", formDisposition(p.Name, p.Filename))
+			if p.Type != "" {
+				h.Set("Content-Type", p.Type)
+			}
+			pw, err := w.CreatePart(h)
+			if err != nil {
+				return nil, nil, "", err
+			}
+			pw.Write([]byte(p.Value))
+		}
+		parts = append(parts, p)
+	}
+	w.Close()
+	stream.addBytes(b.Bytes())
+	return stream, parts, w.FormDataContentType(), nil
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// formDisposition builds a form-data Content-Disposition the way
+// mime/multipart does for CreateFormFile.
+func formDisposition(name, filename string) string {
+	d := fmt.Sprintf(`form-data; name="%s"`, quoteEscaper.Replace(name))
+	if filename != "" {
+		d += fmt.Sprintf(`; filename="%s"`, quoteEscaper.Replace(filename))
+	}
+	return d
+}
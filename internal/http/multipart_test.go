@@ -0,0 +1,81 @@
+package httpx
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/suprbdev/reqo/internal/project"
+)
+
+func TestBuildRequest_FormOrderedParts(t *testing.T) {
+	dir := t.TempDir()
+	photo := filepath.Join(dir, "photo.bin")
+	os.WriteFile(photo, []byte("\x89PNG${x}"), 0o644)
+	doc := filepath.Join(dir, "doc.pdf")
+	os.WriteFile(doc, []byte("%PDF"), 0o644)
+
+	req, err := BuildRequest(makeProject(), RequestSpec{
+		Method: "POST",
+		Path:   "/upload",
+		Form: []project.FormField{
+			{Name: "z", Value: "first"},
+			{Name: "tags[]", Value: "${tag}"},
+			{Name: "tags[]", Value: "b"},
+			{Name: "photo", File: photo, Type: "image/png", Filename: "avatar.png"},
+			{Name: "doc", File: doc},
+			{Name: "meta", JSON: map[string]interface{}{"id": "${id}", "n": 1}},
+			{Name: "note", Value: "<b>", Type: "text/html"},
+		},
+		Vars: map[string]string{"tag": "a", "id": "42", "x": "expanded"},
+	})
+	if err != nil {
+		t.Fatalf("BuildRequest() error: %v", err)
+	}
+	_, params, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	r := multipart.NewReader(req.Body, params["boundary"])
+
+	type part struct{ name, filename, ctype, body string }
+	want := []part{
+		{"z", "", "", "first"},
+		{"tags[]", "", "", "a"},
+		{"tags[]", "", "", "b"},
+		{"photo", "avatar.png", "image/png", "\x89PNG${x}"},
+		{"doc", "doc.pdf", "application/pdf", "%PDF"},
+		{"meta", "", "application/json", `{"id":"42","n":1}`},
+		{"note", "", "text/html", "<b>"},
+	}
+	for i, w := range want {
+		p, err := r.NextPart()
+		if err != nil {
+			t.Fatalf("part %d: %v", i, err)
+		}
+		body, _ := io.ReadAll(p)
+		got := part{p.FormName(), p.FileName(), p.Header.Get("Content-Type"), string(body)}
+		if got != w {
+			t.Errorf("part %d = %+v, want %+v", i, got, w)
+		}
+	}
+	if _, err := r.NextPart(); err != io.EOF {
+		t.Errorf("expected %d parts, next err = %v", len(want), err)
+	}
+
+	parts := FormParts(req)
+	if len(parts) != 7 || parts[3].Filename != "avatar.png" || parts[5].Value != `{"id":"42","n":1}` || parts[5].Type != "application/json" {
+		t.Errorf("FormParts() = %+v", parts)
+	}
+}
+
+func TestFormDisposition(t *testing.T) {
+	got := formDisposition(`a"b`, `c\d.txt`)
+	if got != `form-data; name="a\"b"; filename="c\\d.txt"` {
+		t.Errorf("formDisposition() = %s", got)
+	}
+	if got := formDisposition("x", ""); strings.Contains(got, "filename") {
+		t.Errorf("formDisposition() = %s", got)
+	}
+}
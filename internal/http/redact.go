@@ -0,0 +1,80 @@
+package httpx
+
+import (
+	"net/http"
+	"strings"
+)
+
+// RedactedValue replaces secrets in redacted output.
+const RedactedValue = "REDACTED"
+
+// sensitiveHeaders always carry credentials.
+var sensitiveHeaders = map[string]bool{
+	"authorization":       true,
+	"proxy-authorization": true,
+	"cookie":              true,
+	"set-cookie":          true,
+}
+
+// secretHints mark header and query names that probably hold a secret,
+// e.g. X-API-Key, X-Auth-Token or ?access_token=.
+var secretHints = []string{"token", "secret", "password", "passwd", "apikey", "api-key", "api_key", "session", "signature"}
+
+// IsSensitive reports whether a header or query parameter name is likely to
+// carry a credential.
+func IsSensitive(name string) bool {
+	n := strings.ToLower(name)
+	if sensitiveHeaders[n] || n == "key" {
+		return true
+	}
+	for _, h := range secretHints {
+		if strings.Contains(n, h) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactHeaderValue masks v if name is sensitive. An authorization scheme
+// such as "Bearer" is kept so the output still shows how auth is done.
+func RedactHeaderValue(name, v string) string {
+	if !IsSensitive(name) {
+		return v
+	}
+	lname := strings.ToLower(name)
+	if lname == "authorization" || lname == "proxy-authorization" {
+		if scheme, _, ok := strings.Cut(v, " "); ok {
+			return scheme + " " + RedactedValue
+		}
+	}
+	return RedactedValue
+}
+
+// RedactRequest returns a copy of req with credentials masked in headers,
+// query parameters and URL userinfo. The body is shared with req.
+func RedactRequest(req *http.Request) *http.Request {
+	r := req.Clone(req.Context())
+	for k, vals := range r.Header {
+		for i, v := range vals {
+			vals[i] = RedactHeaderValue(k, v)
+		}
+	}
+	if r.URL.User != nil {
+		r.URL.User = nil
+	}
+	q := r.URL.Query()
+	changed := false
+	for k, vals := range q {
+		if !IsSensitive(k) {
+			continue
+		}
+		for i := range vals {
+			vals[i] = RedactedValue
+		}
+		changed = true
+	}
+	if changed {
+		r.URL.RawQuery = q.Encode()
+	}
+	return r
+}
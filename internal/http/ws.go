@@ -0,0 +1,63 @@
+package httpx
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gorilla/websocket"
+)
+
+// WSURL rewrites an http(s) URL to the matching ws(s) scheme. ws and wss
+// URLs are returned unchanged.
+func WSURL(u *url.URL) *url.URL {
+	c := *u
+	switch strings.ToLower(c.Scheme) {
+	case "http":
+		c.Scheme = "ws"
+	case "https":
+		c.Scheme = "wss"
+	}
+	return &c
+}
+
+// handshakeHeaders are set by the WebSocket dialer itself and must not be
+// passed through from the request.
+var handshakeHeaders = map[string]bool{
+	"Upgrade":                  true,
+	"Connection":               true,
+	"Sec-Websocket-Key":        true,
+	"Sec-Websocket-Version":    true,
+	"Sec-Websocket-Extensions": true,
+	"Content-Length":           true,
+	"Content-Type":             true,
+}
+
+// DialWS opens a WebSocket to the URL of req (rewritten with WSURL), sending
+// its headers – env headers, header sets and --header values from
+// BuildRequest – with the handshake. The request body is ignored.
+func DialWS(ctx context.Context, req *http.Request, opts ExecOpts) (*websocket.Conn, *http.Response, error) {
+	dialer := &websocket.Dialer{
+		Proxy:            http.ProxyFromEnvironment,
+		HandshakeTimeout: opts.Timeout,
+		TLSClientConfig:  &tls.Config{InsecureSkipVerify: opts.Insecure},
+	}
+	header := http.Header{}
+	for k, v := range req.Header {
+		if handshakeHeaders[http.CanonicalHeaderKey(k)] {
+			continue
+		}
+		if http.CanonicalHeaderKey(k) == "Sec-Websocket-Protocol" {
+			for _, p := range v {
+				for _, s := range strings.Split(p, ",") {
+					dialer.Subprotocols = append(dialer.Subprotocols, strings.TrimSpace(s))
+				}
+			}
+			continue
+		}
+		header[k] = v
+	}
+	return dialer.DialContext(ctx, WSURL(req.URL).String(), header)
+}
@@ -0,0 +1,56 @@
+package httpx
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestWSURL(t *testing.T) {
+	tests := map[string]string{
+		"http://api.example.com/ws":    "ws://api.example.com/ws",
+		"https://api.example.com/ws":   "wss://api.example.com/ws",
+		"wss://api.example.com/ws?a=1": "wss://api.example.com/ws?a=1",
+	}
+	for in, want := range tests {
+		u, _ := url.Parse(in)
+		if got := WSURL(u).String(); got != want {
+			t.Errorf("WSURL(%s) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+func TestDialWS_SendsHeaders(t *testing.T) {
+	var gotAuth, gotProto string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		up := websocket.Upgrader{Subprotocols: []string{"graphql-ws"}}
+		c, err := up.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		gotProto = c.Subprotocol()
+		c.Close()
+	}))
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	req.Header.Set("Authorization", "Bearer abc")
+	req.Header.Set("Content-Type", "application/json") // must not reach the handshake
+	req.Header.Set("Sec-WebSocket-Protocol", "graphql-ws")
+	conn, _, err := DialWS(context.Background(), req, ExecOpts{})
+	if err != nil {
+		t.Fatalf("DialWS() error: %v", err)
+	}
+	conn.Close()
+	if gotAuth != "Bearer abc" {
+		t.Errorf("Authorization = %q", gotAuth)
+	}
+	if gotProto != "graphql-ws" {
+		t.Errorf("subprotocol = %q", gotProto)
+	}
+}
@@ -0,0 +1,29 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteCheck prints a passed (✓) or failed (✗) line of a test run,
+// indented by depth: calls at 0, their assertions at 1.
+func WriteCheck(out io.Writer, depth int, passed bool, text string, color bool) {
+	mark, code := "✓", ansiGreen
+	if !passed {
+		mark, code = "✗", ansiRed
+	}
+	if color {
+		mark = paint(code, mark)
+	}
+	fmt.Fprintf(out, "%s%s %s\n", strings.Repeat("  ", depth), mark, text)
+}
+
+// WriteSkipped prints a step that did not run, marked "-" in gray.
+func WriteSkipped(out io.Writer, depth int, text string, color bool) {
+	mark := "-"
+	if color {
+		mark = paint(ansiGray, mark)
+	}
+	fmt.Fprintf(out, "%s%s %s\n", strings.Repeat("  ", depth), mark, text)
+}
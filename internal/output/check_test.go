@@ -0,0 +1,30 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteCheck(t *testing.T) {
+	var buf bytes.Buffer
+	WriteCheck(&buf, 0, true, "get-user", false)
+	WriteCheck(&buf, 1, false, "status == 200 – got 404", false)
+	want := "✓ get-user\n  ✗ status == 200 – got 404\n"
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	WriteCheck(&buf, 0, false, "x", true)
+	if !bytes.Contains(buf.Bytes(), []byte(ansiRed+"✗"+ansiReset)) {
+		t.Errorf("failure mark should be red: %q", buf.String())
+	}
+}
+
+func TestWriteSkipped(t *testing.T) {
+	var buf bytes.Buffer
+	WriteSkipped(&buf, 1, "cleanup  not run", false)
+	if want := "  - cleanup  not run\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,203 @@
+package output
+
+import (
+	"bytes"
+	"io"
+	"os"
+)
+
+// ANSI escape sequences used for highlighting.
+const (
+	ansiReset   = "\x1b[0m"
+	ansiBold    = "\x1b[1m"
+	ansiRed     = "\x1b[31m"
+	ansiGreen   = "\x1b[32m"
+	ansiYellow  = "\x1b[33m"
+	ansiBlue    = "\x1b[34m"
+	ansiMagenta = "\x1b[35m"
+	ansiCyan    = "\x1b[36m"
+	ansiGray    = "\x1b[90m"
+)
+
+// ColorEnabled decides whether output written to w should be coloured.
+// Colour is off when noColor is set (--no-color), when the NO_COLOR
+// environment variable is non-empty, when TERM is "dumb", or when w is not
+// a terminal.
+func ColorEnabled(w io.Writer, noColor bool) bool {
+	if noColor || os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return isTerminal(w)
+}
+
+// IsTerminal reports whether w is an interactive terminal.
+func IsTerminal(w io.Writer) bool { return isTerminal(w) }
+
+// isTerminal reports whether w is a character device such as a TTY.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+func paint(code, s string) string {
+	return code + s + ansiReset
+}
+
+// statusColor picks a colour by status class.
+func statusColor(code int) string {
+	switch {
+	case code >= 500:
+		return ansiRed
+	case code >= 400:
+		return ansiYellow
+	case code >= 300:
+		return ansiCyan
+	case code >= 200:
+		return ansiGreen
+	default:
+		return ansiBlue
+	}
+}
+
+// colorJSON highlights keys, strings, numbers and literals in JSON text.
+// Input that is not JSON is passed through with best‑effort colouring.
+func colorJSON(src []byte) []byte {
+	var b bytes.Buffer
+	b.Grow(len(src) * 2)
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '"':
+			j := i + 1
+			for j < len(src) && src[j] != '"' {
+				if src[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j < len(src) {
+				j++
+			}
+			color := ansiGreen
+			k := j
+			for k < len(src) && (src[k] == ' ' || src[k] == '\t' || src[k] == '\n' || src[k] == '\r') {
+				k++
+			}
+			if k < len(src) && src[k] == ':' {
+				color = ansiBlue + ansiBold
+			}
+			b.WriteString(color)
+			b.Write(src[i:j])
+			b.WriteString(ansiReset)
+			i = j
+		case c == '-' || (c >= '0' && c <= '9'):
+			j := i + 1
+			for j < len(src) && bytes.IndexByte([]byte("0123456789.eE+-"), src[j]) >= 0 {
+				j++
+			}
+			b.WriteString(ansiCyan)
+			b.Write(src[i:j])
+			b.WriteString(ansiReset)
+			i = j
+		case bytes.HasPrefix(src[i:], []byte("true")), bytes.HasPrefix(src[i:], []byte("false")), bytes.HasPrefix(src[i:], []byte("null")):
+			n := 4
+			if c == 'f' {
+				n = 5
+			}
+			b.WriteString(ansiMagenta)
+			b.Write(src[i : i+n])
+			b.WriteString(ansiReset)
+			i += n
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.Bytes()
+}
+
+// colorXML highlights tags, attribute names/values and comments in XML or
+// HTML text.
+func colorXML(src []byte) []byte {
+	var b bytes.Buffer
+	b.Grow(len(src) * 2)
+	for i := 0; i < len(src); {
+		if src[i] != '<' {
+			j := bytes.IndexByte(src[i:], '<')
+			if j < 0 {
+				j = len(src) - i
+			}
+			b.Write(src[i : i+j])
+			i += j
+			continue
+		}
+		if bytes.HasPrefix(src[i:], []byte("<!--")) {
+			end := bytes.Index(src[i:], []byte("-->"))
+			if end < 0 {
+				end = len(src) - i
+			} else {
+				end += 3
+			}
+			b.WriteString(paint(ansiGray, string(src[i:i+end])))
+			i += end
+			continue
+		}
+		end := bytes.IndexByte(src[i:], '>')
+		if end < 0 {
+			b.Write(src[i:])
+			break
+		}
+		b.Write(colorTag(src[i : i+end+1]))
+		i += end + 1
+	}
+	return b.Bytes()
+}
+
+// colorTag colours a single "<name attr="v">" token.
+func colorTag(tag []byte) []byte {
+	var b bytes.Buffer
+	// tag name, including the leading "<", "</", "<?" or "<!"
+	j := 1
+	if j < len(tag) && bytes.IndexByte([]byte("/?!"), tag[j]) >= 0 {
+		j++
+	}
+	for j < len(tag) && bytes.IndexByte([]byte(" \t\r\n/>"), tag[j]) < 0 {
+		j++
+	}
+	b.WriteString(paint(ansiBlue, string(tag[:j])))
+	rest := tag[j:]
+	for len(rest) > 0 {
+		switch c := rest[0]; {
+		case c == '"' || c == '\'':
+			k := bytes.IndexByte(rest[1:], c)
+			if k < 0 {
+				k = len(rest)
+			} else {
+				k += 2
+			}
+			b.WriteString(paint(ansiGreen, string(rest[:k])))
+			rest = rest[k:]
+		case c == '>' || c == '/' || c == '?':
+			b.WriteString(paint(ansiBlue, string(rest)))
+			rest = nil
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '=':
+			b.WriteByte(c)
+			rest = rest[1:]
+		default:
+			k := 0
+			for k < len(rest) && bytes.IndexByte([]byte(" \t\r\n=/>\"'"), rest[k]) < 0 {
+				k++
+			}
+			b.WriteString(paint(ansiCyan, string(rest[:k])))
+			rest = rest[k:]
+		}
+	}
+	return b.Bytes()
+}
@@ -0,0 +1,129 @@
+package output
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func stripANSI(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\x1b' {
+			for i < len(s) && s[i] != 'm' {
+				i++
+			}
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func TestColorEnabled_NoColorFlag(t *testing.T) {
+	if ColorEnabled(os.Stdout, true) {
+		t.Errorf("--no-color should disable colour")
+	}
+}
+
+func TestColorEnabled_NoColorEnv(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	if ColorEnabled(os.Stdout, false) {
+		t.Errorf("NO_COLOR should disable colour")
+	}
+}
+
+func TestColorEnabled_NotTerminal(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	var buf bytes.Buffer
+	if ColorEnabled(&buf, false) {
+		t.Errorf("non-terminal writers should not get colour")
+	}
+	f, err := os.CreateTemp(t.TempDir(), "out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if ColorEnabled(f, false) {
+		t.Errorf("regular files should not get colour")
+	}
+}
+
+func TestColorJSON(t *testing.T) {
+	src := `{"name": "a\"b", "n": -1.5e3, "ok": true, "x": null}`
+	out := string(colorJSON([]byte(src)))
+	if stripANSI(out) != src {
+		t.Errorf("colouring should not change text: %q", stripANSI(out))
+	}
+	if !strings.Contains(out, ansiBlue+ansiBold+`"name"`+ansiReset) {
+		t.Errorf("keys should be highlighted: %q", out)
+	}
+	if !strings.Contains(out, ansiGreen+`"a\"b"`+ansiReset) {
+		t.Errorf("string values should be highlighted: %q", out)
+	}
+	if !strings.Contains(out, ansiCyan+"-1.5e3"+ansiReset) {
+		t.Errorf("numbers should be highlighted: %q", out)
+	}
+	if !strings.Contains(out, ansiMagenta+"true"+ansiReset) || !strings.Contains(out, ansiMagenta+"null"+ansiReset) {
+		t.Errorf("literals should be highlighted: %q", out)
+	}
+}
+
+func TestColorXML(t *testing.T) {
+	src := `<?xml version="1.0"?><!-- c --><a href='x' id="1">text</a><br/>`
+	out := string(colorXML([]byte(src)))
+	if stripANSI(out) != src {
+		t.Errorf("colouring should not change text: %q", stripANSI(out))
+	}
+	if !strings.Contains(out, ansiBlue+"<a"+ansiReset) || !strings.Contains(out, ansiBlue+"</a"+ansiReset) {
+		t.Errorf("tags should be highlighted: %q", out)
+	}
+	if !strings.Contains(out, ansiCyan+"href"+ansiReset) || !strings.Contains(out, ansiGreen+`"1"`+ansiReset) {
+		t.Errorf("attributes should be highlighted: %q", out)
+	}
+	if !strings.Contains(out, ansiGray+"<!-- c -->"+ansiReset) {
+		t.Errorf("comments should be highlighted: %q", out)
+	}
+}
+
+func TestRender_ColorStatusAndHeaders(t *testing.T) {
+	resp := newResp(t, 404, map[string]string{"Content-Type": "application/json"}, `{"error":"nope"}`)
+	var buf bytes.Buffer
+	if err := Render(resp, &buf, RenderOpts{ShowHeaders: true, Color: true}); err != nil {
+		t.Fatalf("Render() error: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, ansiYellow+"HTTP/1.1 404 Not Found") {
+		t.Errorf("4xx status line should be yellow: %q", out)
+	}
+	if !strings.Contains(out, ansiCyan+"Content-Type"+ansiReset+": ") {
+		t.Errorf("header names should be highlighted: %q", out)
+	}
+	if !strings.Contains(out, ansiBlue+ansiBold+`"error"`) {
+		t.Errorf("JSON body should be highlighted: %q", out)
+	}
+}
+
+func TestRender_NoColorByDefault(t *testing.T) {
+	resp := newResp(t, 200, map[string]string{"Content-Type": "application/json"}, `{"a":1}`)
+	var buf bytes.Buffer
+	if err := Render(resp, &buf, RenderOpts{ShowHeaders: true}); err != nil {
+		t.Fatalf("Render() error: %v", err)
+	}
+	if strings.Contains(buf.String(), "\x1b[") {
+		t.Errorf("output should not contain ANSI codes: %q", buf.String())
+	}
+}
+
+func TestRender_StatusTextNotRepeated(t *testing.T) {
+	resp := newResp(t, 200, nil, "")
+	resp.Status = "200 OK"
+	var buf bytes.Buffer
+	if err := Render(resp, &buf, RenderOpts{ShowHeaders: true}); err != nil {
+		t.Fatalf("Render() error: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "HTTP/1.1 200 OK\n") {
+		t.Errorf("status line = %q", buf.String())
+	}
+}
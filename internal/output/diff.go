@@ -0,0 +1,142 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ChangeKind tells how a value differs between two JSON documents.
+type ChangeKind int
+
+const (
+	Changed ChangeKind = iota // present in both with different values
+	Added                     // only in the new document
+	Removed                   // only in the old document
+)
+
+// Change is one difference found by DiffJSON.
+type Change struct {
+	Path string // jq path, e.g. .items[0].id; "." for the root
+	Kind ChangeKind
+	Old  interface{}
+	New  interface{}
+}
+
+// DiffJSON compares two decoded JSON documents structurally: objects key
+// by key (sorted) and arrays index by index. A value whose type differs is
+// reported as changed as a whole.
+func DiffJSON(old, new interface{}) []Change {
+	var out []Change
+	diffValue("", old, new, &out)
+	return out
+}
+
+func diffValue(path string, old, new interface{}, out *[]Change) {
+	switch o := old.(type) {
+	case map[string]interface{}:
+		if n, ok := new.(map[string]interface{}); ok {
+			keys := make([]string, 0, len(o)+len(n))
+			for k := range o {
+				keys = append(keys, k)
+			}
+			for k := range n {
+				if _, ok := o[k]; !ok {
+					keys = append(keys, k)
+				}
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				ov, inOld := o[k]
+				nv, inNew := n[k]
+				p := path + jqKey(k)
+				switch {
+				case !inNew:
+					*out = append(*out, Change{Path: p, Kind: Removed, Old: ov})
+				case !inOld:
+					*out = append(*out, Change{Path: p, Kind: Added, New: nv})
+				default:
+					diffValue(p, ov, nv, out)
+				}
+			}
+			return
+		}
+	case []interface{}:
+		if n, ok := new.([]interface{}); ok {
+			for i := 0; i < len(o) || i < len(n); i++ {
+				p := path + "[" + strconv.Itoa(i) + "]"
+				switch {
+				case i >= len(n):
+					*out = append(*out, Change{Path: p, Kind: Removed, Old: o[i]})
+				case i >= len(o):
+					*out = append(*out, Change{Path: p, Kind: Added, New: n[i]})
+				default:
+					diffValue(p, o[i], n[i], out)
+				}
+			}
+			return
+		}
+	}
+	if !reflect.DeepEqual(old, new) {
+		if path == "" {
+			path = "."
+		}
+		*out = append(*out, Change{Path: path, Kind: Changed, Old: old, New: new})
+	}
+}
+
+var jqIdent = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func jqKey(k string) string {
+	if jqIdent.MatchString(k) {
+		return "." + k
+	}
+	return "[" + strconv.Quote(k) + "]"
+}
+
+// WriteDiff prints changes one per line: "~" for changed values (old → new),
+// "+" for added and "-" for removed ones, in green and red when color is
+// set.
+func WriteDiff(out io.Writer, changes []Change, color bool) {
+	for _, c := range changes {
+		var mark, code, text string
+		switch c.Kind {
+		case Added:
+			mark, code, text = "+", ansiGreen, compactJSON(c.New)
+		case Removed:
+			mark, code, text = "-", ansiRed, compactJSON(c.Old)
+		default:
+			mark, code, text = "~", ansiYellow, compactJSON(c.Old)+" → "+compactJSON(c.New)
+		}
+		line := mark + " " + c.Path + ": " + text
+		if color {
+			if c.Kind == Changed {
+				line = paint(code, mark+" "+c.Path) + ": " + paint(ansiRed, compactJSON(c.Old)) + " → " + paint(ansiGreen, compactJSON(c.New))
+			} else {
+				line = paint(code, line)
+			}
+		}
+		fmt.Fprintln(out, line)
+	}
+}
+
+// compactJSON renders v on one line, shortening long values.
+func compactJSON(v interface{}) string {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return fmt.Sprint(v)
+	}
+	const max = 80
+	s := strings.TrimSuffix(b.String(), "\n")
+	if r := []rune(s); len(r) > max {
+		return string(r[:max-1]) + "…"
+	}
+	return s
+}
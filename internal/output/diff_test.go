@@ -0,0 +1,62 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func decode(t *testing.T, s string) interface{} {
+	t.Helper()
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestDiffJSON(t *testing.T) {
+	old := decode(t, `{"name":"Ada","tags":["a","b","c"],"meta":{"x-id":1},"n":1}`)
+	new := decode(t, `{"name":"Bob","tags":["a","b"],"meta":{"x-id":1},"n":"1","age":36}`)
+	got := DiffJSON(old, new)
+	want := []Change{
+		{Path: ".age", Kind: Added, New: 36.0},
+		{Path: ".n", Kind: Changed, Old: 1.0, New: "1"},
+		{Path: ".name", Kind: Changed, Old: "Ada", New: "Bob"},
+		{Path: ".tags[2]", Kind: Removed, Old: "c"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v\nwant %+v", got, want)
+	}
+
+	if d := DiffJSON(old, old); len(d) != 0 {
+		t.Errorf("equal documents: %+v", d)
+	}
+	if d := DiffJSON("a", 1.0); len(d) != 1 || d[0].Path != "." {
+		t.Errorf("root change: %+v", d)
+	}
+	if d := DiffJSON(decode(t, `{"a b":1}`), decode(t, `{"a b":2}`)); len(d) != 1 || d[0].Path != `["a b"]` {
+		t.Errorf("quoted key: %+v", d)
+	}
+}
+
+func TestWriteDiff(t *testing.T) {
+	changes := []Change{
+		{Path: ".age", Kind: Added, New: 36.0},
+		{Path: ".name", Kind: Changed, Old: "Ada", New: "<Bob>"},
+		{Path: ".tags[2]", Kind: Removed, Old: []interface{}{"c"}},
+	}
+	var buf bytes.Buffer
+	WriteDiff(&buf, changes, false)
+	want := "+ .age: 36\n~ .name: \"Ada\" → \"<Bob>\"\n- .tags[2]: [\"c\"]\n"
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	WriteDiff(&buf, changes[:1], true)
+	if buf.String() != ansiGreen+"+ .age: 36"+ansiReset+"\n" {
+		t.Errorf("colored: %q", buf.String())
+	}
+}
@@ -0,0 +1,201 @@
+package output
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RemoteName picks a local file name for resp the way curl -O does, but
+// preferring the Content-Disposition filename over the last URL segment.
+func RemoteName(resp *http.Response) (string, error) {
+	name := ""
+	if cd := resp.Header.Get("Content-Disposition"); cd != "" {
+		if _, params, err := mime.ParseMediaType(cd); err == nil {
+			name = params["filename"]
+		}
+	}
+	if name == "" && resp.Request != nil {
+		name = path.Base(resp.Request.URL.Path)
+	}
+	// never let the server choose a directory
+	name = filepath.Base(strings.ReplaceAll(name, `\`, "/"))
+	if name == "" || name == "." || name == ".." || name == "/" {
+		return "", fmt.Errorf("cannot derive a file name from the response; use --output-file")
+	}
+	return name, nil
+}
+
+// DownloadOpts controls how Download writes the body.
+type DownloadOpts struct {
+	Progress io.Writer // progress bar destination, typically stderr (nil = none)
+	Offset   int64     // bytes already on disk; non‑zero when a Range request was sent
+	SHA256   string    // expected hex digest of the complete file (optional)
+}
+
+// Download streams the body of resp into the file at path and returns the
+// size of the resulting file. With a non‑zero Offset a 206 response is
+// appended to the existing file and a 200 response replaces it, so servers
+// that ignore Range still produce a correct download.
+func Download(resp *http.Response, path string, opts DownloadOpts) (int64, error) {
+	offset := opts.Offset
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	switch {
+	case offset > 0 && resp.StatusCode == http.StatusPartialContent:
+		start, _, err := contentRange(resp.Header.Get("Content-Range"))
+		if err != nil {
+			return 0, err
+		}
+		if start != offset {
+			return 0, fmt.Errorf("server resumed at byte %d, expected %d", start, offset)
+		}
+		flags = os.O_WRONLY | os.O_APPEND
+	case offset > 0 && resp.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		// the partial file may already be complete
+		if _, total, err := contentRange(resp.Header.Get("Content-Range")); err != nil || total != offset {
+			return 0, fmt.Errorf("server rejected resume from byte %d (%s)", offset, resp.Status)
+		}
+		return offset, verifySHA256(path, opts.SHA256)
+	case offset > 0 && resp.StatusCode != http.StatusOK:
+		return 0, fmt.Errorf("server responded %s; partial file left unchanged", resp.Status)
+	default:
+		offset = 0
+	}
+
+	f, err := os.OpenFile(path, flags, 0o644)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	var dst io.Writer = f
+	var bar *progressBar
+	if opts.Progress != nil {
+		total := resp.ContentLength
+		if total >= 0 {
+			total += offset
+		}
+		bar = &progressBar{out: opts.Progress, total: total, written: offset}
+		dst = io.MultiWriter(f, bar)
+	}
+	n, err := io.Copy(dst, resp.Body)
+	if bar != nil {
+		bar.finish()
+	}
+	if err != nil {
+		return offset + n, err
+	}
+	if err := f.Close(); err != nil {
+		return offset + n, err
+	}
+	if resp.ContentLength >= 0 && n != resp.ContentLength {
+		return offset + n, fmt.Errorf("incomplete download: got %d of %d bytes; rerun with --continue", n, resp.ContentLength)
+	}
+	return offset + n, verifySHA256(path, opts.SHA256)
+}
+
+// contentRange parses "bytes start-end/total" (or "bytes */total"). total is
+// -1 when the server reports it as "*".
+func contentRange(v string) (start, total int64, err error) {
+	spec, ok := strings.CutPrefix(v, "bytes ")
+	rng, size, ok2 := strings.Cut(spec, "/")
+	if !ok || !ok2 {
+		return 0, 0, fmt.Errorf("invalid Content-Range %q", v)
+	}
+	total = -1
+	if size != "*" {
+		if total, err = strconv.ParseInt(size, 10, 64); err != nil {
+			return 0, 0, fmt.Errorf("invalid Content-Range %q", v)
+		}
+	}
+	if rng == "*" {
+		return 0, total, nil
+	}
+	first, _, _ := strings.Cut(rng, "-")
+	if start, err = strconv.ParseInt(first, 10, 64); err != nil {
+		return 0, 0, fmt.Errorf("invalid Content-Range %q", v)
+	}
+	return start, total, nil
+}
+
+// verifySHA256 compares the digest of the file at path with want (hex,
+// case‑insensitive). An empty want skips the check.
+func verifySHA256(path, want string) error {
+	if want == "" {
+		return nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); !strings.EqualFold(got, strings.TrimSpace(want)) {
+		return fmt.Errorf("sha256 mismatch for %s: got %s, want %s", path, got, want)
+	}
+	return nil
+}
+
+// progressBar is an io.Writer that counts bytes and redraws a one‑line bar.
+type progressBar struct {
+	out     io.Writer
+	total   int64 // -1 when unknown
+	written int64
+	drawn   time.Time
+}
+
+func (p *progressBar) Write(b []byte) (int, error) {
+	p.written += int64(len(b))
+	if time.Since(p.drawn) >= 100*time.Millisecond {
+		p.draw()
+	}
+	return len(b), nil
+}
+
+func (p *progressBar) draw() {
+	p.drawn = time.Now()
+	if p.total <= 0 {
+		fmt.Fprintf(p.out, "\r%s", HumanBytes(p.written))
+		return
+	}
+	const width = 30
+	frac := float64(p.written) / float64(p.total)
+	if frac > 1 {
+		frac = 1
+	}
+	filled := int(frac * width)
+	fmt.Fprintf(p.out, "\r[%s%s] %3.0f%% %s / %s",
+		strings.Repeat("#", filled), strings.Repeat("-", width-filled),
+		frac*100, HumanBytes(p.written), HumanBytes(p.total))
+}
+
+func (p *progressBar) finish() {
+	p.draw()
+	fmt.Fprintln(p.out)
+}
+
+// HumanBytes formats a byte count with a binary unit, e.g. "1.5 MiB".
+func HumanBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
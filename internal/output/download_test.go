@@ -0,0 +1,172 @@
+package output
+
+import (
+	"bytes"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRemoteName_ContentDisposition(t *testing.T) {
+	resp := newResp(t, 200, map[string]string{"Content-Disposition": `attachment; filename="report.pdf"`}, "")
+	resp.Request = &http.Request{URL: &url.URL{Path: "/files/123"}}
+	name, err := RemoteName(resp)
+	if err != nil {
+		t.Fatalf("RemoteName() error: %v", err)
+	}
+	if name != "report.pdf" {
+		t.Errorf("name = %q, want report.pdf", name)
+	}
+}
+
+func TestRemoteName_URLPath(t *testing.T) {
+	resp := newResp(t, 200, nil, "")
+	resp.Request = &http.Request{URL: &url.URL{Path: "/downloads/app.tar.gz"}}
+	name, err := RemoteName(resp)
+	if err != nil {
+		t.Fatalf("RemoteName() error: %v", err)
+	}
+	if name != "app.tar.gz" {
+		t.Errorf("name = %q", name)
+	}
+}
+
+func TestRemoteName_StripsDirectories(t *testing.T) {
+	resp := newResp(t, 200, map[string]string{"Content-Disposition": `attachment; filename="../../etc/passwd"`}, "")
+	name, err := RemoteName(resp)
+	if err != nil {
+		t.Fatalf("RemoteName() error: %v", err)
+	}
+	if name != "passwd" {
+		t.Errorf("name = %q, want passwd", name)
+	}
+}
+
+func TestRemoteName_NoName(t *testing.T) {
+	resp := newResp(t, 200, nil, "")
+	resp.Request = &http.Request{URL: &url.URL{Path: "/"}}
+	if _, err := RemoteName(resp); err == nil {
+		t.Error("expected error for a URL without a file name")
+	}
+}
+
+func TestDownload_WritesFile(t *testing.T) {
+	resp := newResp(t, 200, nil, "payload")
+	resp.ContentLength = 7
+	path := filepath.Join(t.TempDir(), "out.bin")
+	var progress bytes.Buffer
+	n, err := Download(resp, path, DownloadOpts{Progress: &progress})
+	if err != nil {
+		t.Fatalf("Download() error: %v", err)
+	}
+	if n != 7 {
+		t.Errorf("n = %d, want 7", n)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != "payload" {
+		t.Errorf("file = %q", data)
+	}
+	if !strings.Contains(progress.String(), "100%") {
+		t.Errorf("progress = %q", progress.String())
+	}
+}
+
+func TestDownload_ResumeAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.bin")
+	os.WriteFile(path, []byte("hello "), 0o644)
+	resp := newResp(t, 206, map[string]string{"Content-Range": "bytes 6-10/11"}, "world")
+	resp.ContentLength = 5
+	n, err := Download(resp, path, DownloadOpts{Offset: 6})
+	if err != nil {
+		t.Fatalf("Download() error: %v", err)
+	}
+	if n != 11 {
+		t.Errorf("n = %d, want 11", n)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != "hello world" {
+		t.Errorf("file = %q", data)
+	}
+}
+
+func TestDownload_ResumeRestartsOn200(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.bin")
+	os.WriteFile(path, []byte("stale"), 0o644)
+	resp := newResp(t, 200, nil, "fresh body")
+	resp.ContentLength = 10
+	if _, err := Download(resp, path, DownloadOpts{Offset: 5}); err != nil {
+		t.Fatalf("Download() error: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != "fresh body" {
+		t.Errorf("file = %q", data)
+	}
+}
+
+func TestDownload_ResumeWrongOffset(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.bin")
+	os.WriteFile(path, []byte("hello "), 0o644)
+	resp := newResp(t, 206, map[string]string{"Content-Range": "bytes 0-10/11"}, "hello world")
+	if _, err := Download(resp, path, DownloadOpts{Offset: 6}); err == nil {
+		t.Error("expected error when the server resumes at the wrong byte")
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != "hello " {
+		t.Errorf("partial file should be untouched: %q", data)
+	}
+}
+
+func TestDownload_AlreadyComplete(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.bin")
+	os.WriteFile(path, []byte("done"), 0o644)
+	resp := newResp(t, 416, map[string]string{"Content-Range": "bytes */4"}, "")
+	n, err := Download(resp, path, DownloadOpts{Offset: 4})
+	if err != nil {
+		t.Fatalf("Download() error: %v", err)
+	}
+	if n != 4 {
+		t.Errorf("n = %d, want 4", n)
+	}
+}
+
+func TestDownload_ShortBody(t *testing.T) {
+	resp := newResp(t, 200, nil, "abc")
+	resp.ContentLength = 10
+	path := filepath.Join(t.TempDir(), "out.bin")
+	if _, err := Download(resp, path, DownloadOpts{}); err == nil || !strings.Contains(err.Error(), "incomplete") {
+		t.Errorf("expected incomplete download error, got %v", err)
+	}
+}
+
+func TestDownload_SHA256(t *testing.T) {
+	const sum = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824" // "hello"
+	path := filepath.Join(t.TempDir(), "out.bin")
+	resp := newResp(t, 200, nil, "hello")
+	resp.ContentLength = -1
+	if _, err := Download(resp, path, DownloadOpts{SHA256: strings.ToUpper(sum)}); err != nil {
+		t.Errorf("matching digest should pass: %v", err)
+	}
+	resp = newResp(t, 200, nil, "hellO")
+	resp.ContentLength = -1
+	_, err := Download(resp, path, DownloadOpts{SHA256: sum})
+	if err == nil || !strings.Contains(err.Error(), "sha256 mismatch") {
+		t.Errorf("expected mismatch error, got %v", err)
+	}
+}
+
+func TestHumanBytes(t *testing.T) {
+	tests := map[int64]string{
+		512:           "512 B",
+		1536:          "1.5 KiB",
+		5 * 1 << 20:   "5.0 MiB",
+		3 * (1 << 30): "3.0 GiB",
+	}
+	for n, want := range tests {
+		if got := HumanBytes(n); got != want {
+			t.Errorf("HumanBytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
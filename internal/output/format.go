@@ -0,0 +1,232 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Formats lists the values accepted for RenderOpts.Format.
+var Formats = []string{"json", "yaml", "table", "csv", "jsonl"}
+
+// ValidFormat reports whether f is empty (default rendering) or one of Formats.
+func ValidFormat(f string) bool {
+	if f == "" {
+		return true
+	}
+	for _, v := range Formats {
+		if v == f {
+			return true
+		}
+	}
+	return false
+}
+
+// formatValue renders decoded JSON data in one of the structured formats.
+func formatValue(v interface{}, format string) ([]byte, error) {
+	switch format {
+	case "json":
+		return json.MarshalIndent(v, "", "  ")
+	case "yaml":
+		return yaml.Marshal(yamlNumbers(v))
+	case "jsonl":
+		var b bytes.Buffer
+		items, ok := v.([]interface{})
+		if !ok {
+			items = []interface{}{v}
+		}
+		for _, item := range items {
+			line, err := json.Marshal(item)
+			if err != nil {
+				return nil, err
+			}
+			b.Write(line)
+			b.WriteByte('\n')
+		}
+		return b.Bytes(), nil
+	case "table", "csv":
+		cols, rows := tabulate(v)
+		if format == "csv" {
+			return writeCSV(cols, rows)
+		}
+		return writeTable(cols, rows), nil
+	default:
+		return nil, fmt.Errorf("unknown output format %q (supported: %s)", format, strings.Join(Formats, ", "))
+	}
+}
+
+// DecodeJSON decodes a JSON document keeping numbers as json.Number, so the
+// structured formats print them as the server wrote them and RunJQ sees
+// large ids exactly instead of rounded through float64.
+func DecodeJSON(data []byte, v *interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return fmt.Errorf("invalid character after top-level value")
+	}
+	return nil
+}
+
+// yamlNumbers replaces the numbers in v, which yaml would quote as
+// strings or round, with plain scalars carrying their JSON text.
+func yamlNumbers(v interface{}) interface{} {
+	switch t := v.(type) {
+	case json.Number:
+		return &yaml.Node{Kind: yaml.ScalarNode, Value: t.String()}
+	case *big.Int:
+		return &yaml.Node{Kind: yaml.ScalarNode, Value: t.String()}
+	case []interface{}:
+		out := make([]interface{}, len(t))
+		for i, item := range t {
+			out[i] = yamlNumbers(item)
+		}
+		return out
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(t))
+		for k, item := range t {
+			out[k] = yamlNumbers(item)
+		}
+		return out
+	}
+	return v
+}
+
+// tabulate turns JSON data into columns and rows. Arrays of objects become
+// one row per element with nested objects flattened to dotted column names;
+// a single object becomes a key/value listing; scalars get a "value" column.
+func tabulate(v interface{}) ([]string, [][]string) {
+	switch t := v.(type) {
+	case []interface{}:
+		objects := true
+		for _, item := range t {
+			if _, ok := item.(map[string]interface{}); !ok {
+				objects = false
+				break
+			}
+		}
+		if !objects || len(t) == 0 {
+			rows := make([][]string, 0, len(t))
+			for _, item := range t {
+				rows = append(rows, []string{cell(item)})
+			}
+			return []string{"value"}, rows
+		}
+		seen := map[string]bool{}
+		var cols []string
+		flat := make([]map[string]interface{}, len(t))
+		for i, item := range t {
+			flat[i] = map[string]interface{}{}
+			flatten("", item.(map[string]interface{}), flat[i])
+			for k := range flat[i] {
+				if !seen[k] {
+					seen[k] = true
+					cols = append(cols, k)
+				}
+			}
+		}
+		sort.Strings(cols)
+		rows := make([][]string, len(flat))
+		for i, m := range flat {
+			row := make([]string, len(cols))
+			for j, c := range cols {
+				if val, ok := m[c]; ok {
+					row[j] = cell(val)
+				}
+			}
+			rows[i] = row
+		}
+		return cols, rows
+	case map[string]interface{}:
+		flat := map[string]interface{}{}
+		flatten("", t, flat)
+		keys := make([]string, 0, len(flat))
+		for k := range flat {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		rows := make([][]string, len(keys))
+		for i, k := range keys {
+			rows[i] = []string{k, cell(flat[k])}
+		}
+		return []string{"key", "value"}, rows
+	default:
+		return []string{"value"}, [][]string{{cell(v)}}
+	}
+}
+
+// flatten copies nested objects into out using dotted keys ("user.name").
+func flatten(prefix string, m map[string]interface{}, out map[string]interface{}) {
+	for k, v := range m {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		if nested, ok := v.(map[string]interface{}); ok && len(nested) > 0 {
+			flatten(key, nested, out)
+			continue
+		}
+		out[key] = v
+	}
+}
+
+// cell renders a single value; arrays and objects stay compact JSON.
+func cell(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case json.Number:
+		return t.String()
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(t)
+	default:
+		b, _ := json.Marshal(t)
+		return string(b)
+	}
+}
+
+func writeTable(cols []string, rows [][]string) []byte {
+	var b bytes.Buffer
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	upper := make([]string, len(cols))
+	for i, c := range cols {
+		upper[i] = strings.ToUpper(c)
+	}
+	fmt.Fprintln(tw, strings.Join(upper, "\t"))
+	for _, r := range rows {
+		for i := range r {
+			// tabs and newlines would break the grid
+			r[i] = strings.NewReplacer("\t", " ", "\n", " ").Replace(r[i])
+		}
+		fmt.Fprintln(tw, strings.Join(r, "\t"))
+	}
+	tw.Flush()
+	return b.Bytes()
+}
+
+func writeCSV(cols []string, rows [][]string) ([]byte, error) {
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	if err := w.Write(cols); err != nil {
+		return nil, err
+	}
+	if err := w.WriteAll(rows); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
@@ -0,0 +1,148 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const usersJSON = `{"items":[{"id":1,"name":"Ann","address":{"city":"Oslo"}},{"id":2,"name":"Bob","tags":["a","b"]}],"total":2}`
+
+func TestValidFormat(t *testing.T) {
+	for _, f := range append([]string{""}, Formats...) {
+		if !ValidFormat(f) {
+			t.Errorf("ValidFormat(%q) = false", f)
+		}
+	}
+	if ValidFormat("xml") {
+		t.Errorf("ValidFormat(xml) = true")
+	}
+}
+
+func TestRender_FormatYAML(t *testing.T) {
+	resp := newResp(t, 200, map[string]string{"Content-Type": "application/json"}, `{"name":"test","age":30}`)
+	var buf bytes.Buffer
+	if err := Render(resp, &buf, RenderOpts{Format: "yaml"}); err != nil {
+		t.Fatalf("Render() error: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "name: test") || !strings.Contains(out, "age: 30") {
+		t.Errorf("yaml output = %q", out)
+	}
+}
+
+func TestRender_FormatTableWithJQ(t *testing.T) {
+	resp := newResp(t, 200, map[string]string{"Content-Type": "application/json"}, usersJSON)
+	var buf bytes.Buffer
+	if err := Render(resp, &buf, RenderOpts{JQExpr: ".items", Format: "table"}); err != nil {
+		t.Fatalf("Render() error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("table should have header + 2 rows, got %q", buf.String())
+	}
+	for _, col := range []string{"ADDRESS.CITY", "ID", "NAME", "TAGS"} {
+		if !strings.Contains(lines[0], col) {
+			t.Errorf("header should contain %s: %q", col, lines[0])
+		}
+	}
+	if !strings.Contains(lines[1], "Oslo") || !strings.Contains(lines[1], "Ann") {
+		t.Errorf("row 1 = %q", lines[1])
+	}
+	if !strings.Contains(lines[2], `["a","b"]`) {
+		t.Errorf("arrays should be rendered as compact JSON: %q", lines[2])
+	}
+}
+
+func TestRender_FormatCSV(t *testing.T) {
+	resp := newResp(t, 200, map[string]string{"Content-Type": "application/json"}, `[{"id":1,"note":"a, b"},{"id":2}]`)
+	var buf bytes.Buffer
+	if err := Render(resp, &buf, RenderOpts{Format: "csv"}); err != nil {
+		t.Fatalf("Render() error: %v", err)
+	}
+	want := "id,note\n1,\"a, b\"\n2,\n"
+	if buf.String() != want {
+		t.Errorf("csv output = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestRender_FormatJSONL(t *testing.T) {
+	resp := newResp(t, 200, map[string]string{"Content-Type": "application/json"}, usersJSON)
+	var buf bytes.Buffer
+	if err := Render(resp, &buf, RenderOpts{JQExpr: ".items", Format: "jsonl"}); err != nil {
+		t.Fatalf("Render() error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], `{"address":{"city":"Oslo"}`) {
+		t.Errorf("jsonl output = %q", buf.String())
+	}
+}
+
+func TestRender_FormatJSONKeepsOrder(t *testing.T) {
+	resp := newResp(t, 200, map[string]string{"Content-Type": "text/plain"}, `{"z":1,"a":2}`)
+	var buf bytes.Buffer
+	if err := Render(resp, &buf, RenderOpts{Format: "json"}); err != nil {
+		t.Fatalf("Render() error: %v", err)
+	}
+	if strings.Index(buf.String(), `"z"`) > strings.Index(buf.String(), `"a"`) {
+		t.Errorf("json output should keep key order: %q", buf.String())
+	}
+}
+
+func TestRender_FormatNonJSONBody(t *testing.T) {
+	resp := newResp(t, 200, map[string]string{"Content-Type": "text/plain"}, "hello")
+	var buf bytes.Buffer
+	if err := Render(resp, &buf, RenderOpts{Format: "table"}); err == nil {
+		t.Errorf("expected error for table output of non-JSON body")
+	}
+}
+
+func TestTabulate_Object(t *testing.T) {
+	cols, rows := tabulate(map[string]interface{}{"b": 1.0, "a": map[string]interface{}{"x": true}})
+	if strings.Join(cols, ",") != "key,value" {
+		t.Errorf("cols = %v", cols)
+	}
+	if len(rows) != 2 || rows[0][0] != "a.x" || rows[0][1] != "true" || rows[1][1] != "1" {
+		t.Errorf("rows = %v", rows)
+	}
+}
+
+func TestTabulate_Scalars(t *testing.T) {
+	cols, rows := tabulate([]interface{}{"x", 2.5})
+	if len(cols) != 1 || cols[0] != "value" || len(rows) != 2 || rows[1][0] != "2.5" {
+		t.Errorf("cols = %v rows = %v", cols, rows)
+	}
+}
+
+func TestRender_FormatLargeNumbers(t *testing.T) {
+	// above 2^53, where float64 would round them
+	body := `[{"id":9007199254740993,"big":123456789012345678901,"price":1.50}]`
+	tests := map[string][]string{
+		"yaml":  {"id: 9007199254740993", "big: 123456789012345678901", "price: 1.50"},
+		"table": {"9007199254740993", "123456789012345678901", "1.50"},
+		"csv":   {"123456789012345678901,9007199254740993,1.50"},
+		"jsonl": {`{"big":123456789012345678901,"id":9007199254740993,"price":1.50}`},
+	}
+	for format, wants := range tests {
+		resp := newResp(t, 200, map[string]string{"Content-Type": "application/json"}, body)
+		var buf bytes.Buffer
+		if err := Render(resp, &buf, RenderOpts{Format: format}); err != nil {
+			t.Fatalf("%s: Render() error: %v", format, err)
+		}
+		for _, want := range wants {
+			if !strings.Contains(buf.String(), want) {
+				t.Errorf("%s output should contain %q:\n%s", format, want, buf.String())
+			}
+		}
+	}
+
+	// jq results keep every digit too
+	resp := newResp(t, 200, map[string]string{"Content-Type": "application/json"}, body)
+	var buf bytes.Buffer
+	if err := Render(resp, &buf, RenderOpts{JQExpr: ".[0].id", Format: "csv"}); err != nil {
+		t.Fatalf("Render() error: %v", err)
+	}
+	if buf.String() != "value\n9007199254740993\n" {
+		t.Errorf("jq csv = %q", buf.String())
+	}
+}
@@ -0,0 +1,265 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+	"strings"
+)
+
+// Formatter pretty‑prints a response body. It returns an error when the
+// body does not parse, in which case the body is shown unchanged.
+type Formatter func(body []byte) ([]byte, error)
+
+// Syntax names the token grammar of formatted output, used for highlighting.
+type Syntax string
+
+const (
+	SyntaxNone Syntax = ""
+	SyntaxJSON Syntax = "json"
+	SyntaxXML  Syntax = "xml"
+)
+
+type formatterEntry struct {
+	match  func(mediaType string) bool
+	syntax Syntax
+	format Formatter
+}
+
+// formatters is consulted in reverse order so later registrations win.
+var formatters []formatterEntry
+
+// RegisterFormatter adds a formatter for the media types accepted by match.
+// match receives the lower‑cased media type without parameters, e.g.
+// "application/problem+json".
+func RegisterFormatter(match func(mediaType string) bool, syntax Syntax, f Formatter) {
+	formatters = append(formatters, formatterEntry{match: match, syntax: syntax, format: f})
+}
+
+func init() {
+	RegisterFormatter(isJSONType, SyntaxJSON, indentJSON)
+	RegisterFormatter(isXMLType, SyntaxXML, indentXML)
+	RegisterFormatter(isHTMLType, SyntaxXML, indentHTML)
+	RegisterFormatter(func(mt string) bool { return mt == "application/x-www-form-urlencoded" }, SyntaxNone, decodeForm)
+}
+
+// formatBody pretty‑prints body according to its Content-Type. When no
+// formatter matches (or the matching one fails) but the body looks like
+// JSON, it is indented as JSON anyway.
+func formatBody(contentType string, body []byte) ([]byte, Syntax) {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mt = strings.ToLower(strings.TrimSpace(contentType))
+	}
+	for i := len(formatters) - 1; i >= 0; i-- {
+		f := formatters[i]
+		if mt == "" || !f.match(mt) {
+			continue
+		}
+		if out, err := f.format(body); err == nil {
+			return out, f.syntax
+		}
+		break
+	}
+	if looksLikeJSON(body) {
+		if out, err := indentJSON(body); err == nil {
+			return out, SyntaxJSON
+		}
+	}
+	return body, SyntaxNone
+}
+
+func isJSONType(mt string) bool {
+	return mt == "application/json" || mt == "text/json" || strings.HasSuffix(mt, "+json")
+}
+
+func isXMLType(mt string) bool {
+	return mt == "application/xml" || mt == "text/xml" || strings.HasSuffix(mt, "+xml") && mt != "application/xhtml+xml"
+}
+
+func isHTMLType(mt string) bool {
+	return mt == "text/html" || mt == "application/xhtml+xml"
+}
+
+func looksLikeJSON(body []byte) bool {
+	t := bytes.TrimSpace(body)
+	return len(t) > 0 && (t[0] == '{' || t[0] == '[') && json.Valid(t)
+}
+
+func indentJSON(body []byte) ([]byte, error) {
+	var pretty bytes.Buffer
+	if err := json.Indent(&pretty, body, "", "  "); err != nil {
+		return nil, err
+	}
+	return pretty.Bytes(), nil
+}
+
+// decodeForm lists the fields of an x-www-form-urlencoded body, one
+// "key = value" pair per line in their original order.
+func decodeForm(body []byte) ([]byte, error) {
+	var b bytes.Buffer
+	for _, pair := range strings.Split(strings.TrimSpace(string(body)), "&") {
+		if pair == "" {
+			continue
+		}
+		k, v, _ := strings.Cut(pair, "=")
+		key, err := url.QueryUnescape(k)
+		if err != nil {
+			return nil, err
+		}
+		val, err := url.QueryUnescape(v)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&b, "%s = %s\n", key, val)
+	}
+	return b.Bytes(), nil
+}
+
+// XML / HTML ----------------------------------------------------------------
+
+// xmlNode is a minimal document tree; RawToken is used so namespace
+// prefixes are printed exactly as received.
+type xmlNode struct {
+	token    xml.Token // StartElement, CharData, Comment, ProcInst or Directive
+	children []*xmlNode
+}
+
+func indentXML(body []byte) ([]byte, error) {
+	d := xml.NewDecoder(bytes.NewReader(body))
+	return indentMarkup(d, false)
+}
+
+func indentHTML(body []byte) ([]byte, error) {
+	d := xml.NewDecoder(bytes.NewReader(body))
+	d.Strict = false
+	d.AutoClose = xml.HTMLAutoClose
+	d.Entity = xml.HTMLEntity
+	return indentMarkup(d, true)
+}
+
+func indentMarkup(d *xml.Decoder, html bool) ([]byte, error) {
+	root := &xmlNode{}
+	stack := []*xmlNode{root}
+	for {
+		tok, err := d.RawToken()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		parent := stack[len(stack)-1]
+		switch t := tok.(type) {
+		case xml.StartElement:
+			n := &xmlNode{token: t.Copy()}
+			parent.children = append(parent.children, n)
+			if html && htmlVoid[strings.ToLower(t.Name.Local)] {
+				continue
+			}
+			stack = append(stack, n)
+		case xml.EndElement:
+			if html && htmlVoid[strings.ToLower(t.Name.Local)] {
+				continue
+			}
+			if len(stack) == 1 {
+				if html {
+					continue // stray end tag
+				}
+				return nil, fmt.Errorf("unexpected </%s>", t.Name.Local)
+			}
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(bytes.TrimSpace(t)) == 0 {
+				continue
+			}
+			parent.children = append(parent.children, &xmlNode{token: t.Copy()})
+		default:
+			parent.children = append(parent.children, &xmlNode{token: xml.CopyToken(tok)})
+		}
+	}
+	if len(stack) != 1 && !html {
+		return nil, errors.New("unclosed element")
+	}
+	var b bytes.Buffer
+	for _, n := range root.children {
+		writeNode(&b, n, 0, html)
+	}
+	return b.Bytes(), nil
+}
+
+func writeNode(b *bytes.Buffer, n *xmlNode, depth int, html bool) {
+	indent := strings.Repeat("  ", depth)
+	switch t := n.token.(type) {
+	case xml.StartElement:
+		name := qname(t.Name)
+		b.WriteString(indent + "<" + name)
+		for _, a := range t.Attr {
+			b.WriteString(" " + qname(a.Name) + `="` + markupEscaper.Replace(a.Value) + `"`)
+		}
+		switch {
+		case len(n.children) == 0 && html && htmlVoid[strings.ToLower(t.Name.Local)]:
+			b.WriteString(">\n")
+		case len(n.children) == 0 && !html:
+			b.WriteString("/>\n")
+		case len(n.children) == 0:
+			b.WriteString("></" + name + ">\n")
+		case len(n.children) == 1 && isText(n.children[0]):
+			b.WriteString(">")
+			writeText(b, n.children[0].token.(xml.CharData))
+			b.WriteString("</" + name + ">\n")
+		default:
+			b.WriteString(">\n")
+			for _, c := range n.children {
+				writeNode(b, c, depth+1, html)
+			}
+			b.WriteString(indent + "</" + name + ">\n")
+		}
+	case xml.CharData:
+		b.WriteString(indent)
+		writeText(b, t)
+		b.WriteString("\n")
+	case xml.Comment:
+		b.WriteString(indent + "<!--" + string(t) + "-->\n")
+	case xml.ProcInst:
+		b.WriteString(indent + "<?" + t.Target)
+		if len(t.Inst) > 0 {
+			b.WriteString(" " + string(t.Inst))
+		}
+		b.WriteString("?>\n")
+	case xml.Directive:
+		b.WriteString(indent + "<!" + string(t) + ">\n")
+	}
+}
+
+func isText(n *xmlNode) bool {
+	_, ok := n.token.(xml.CharData)
+	return ok
+}
+
+func writeText(b *bytes.Buffer, t xml.CharData) {
+	b.WriteString(markupEscaper.Replace(string(bytes.TrimSpace(t))))
+}
+
+// markupEscaper re‑escapes decoded text; unlike xml.EscapeText it keeps
+// newlines readable.
+var markupEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+
+func qname(n xml.Name) string {
+	if n.Space == "" {
+		return n.Local
+	}
+	return n.Space + ":" + n.Local
+}
+
+// htmlVoid lists HTML elements that never have a closing tag.
+var htmlVoid = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true,
+	"img": true, "input": true, "link": true, "meta": true, "source": true,
+	"track": true, "wbr": true,
+}
@@ -0,0 +1,110 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestFormatBody_JSONMediaTypes(t *testing.T) {
+	for _, ct := range []string{
+		"application/json",
+		"application/json; charset=utf-8",
+		"application/problem+json",
+		"application/vnd.api+json",
+		"text/json",
+	} {
+		out, syntax := formatBody(ct, []byte(`{"a":1}`))
+		if syntax != SyntaxJSON || !strings.Contains(string(out), "\n  \"a\": 1") {
+			t.Errorf("formatBody(%q) = %q, %q", ct, out, syntax)
+		}
+	}
+}
+
+func TestFormatBody_SniffsJSON(t *testing.T) {
+	for _, ct := range []string{"", "text/plain", "application/octet-stream"} {
+		out, syntax := formatBody(ct, []byte(` [1,2]`))
+		if syntax != SyntaxJSON || !strings.Contains(string(out), "\n  1,") {
+			t.Errorf("formatBody(%q) should sniff JSON, got %q", ct, out)
+		}
+	}
+}
+
+func TestFormatBody_PlainTextUnchanged(t *testing.T) {
+	out, syntax := formatBody("text/plain", []byte("hello {world}"))
+	if syntax != SyntaxNone || string(out) != "hello {world}" {
+		t.Errorf("plain text should be unchanged: %q", out)
+	}
+}
+
+func TestFormatBody_XML(t *testing.T) {
+	src := `<?xml version="1.0"?><soap:Envelope xmlns:soap="http://www.w3.org/2003/05/soap-envelope"><soap:Body><m:Price xmlns:m="urn:x" cur="EUR">1 &amp; 2</m:Price><empty/></soap:Body></soap:Envelope>`
+	out, syntax := formatBody("application/soap+xml; charset=utf-8", []byte(src))
+	if syntax != SyntaxXML {
+		t.Fatalf("syntax = %q", syntax)
+	}
+	want := `<?xml version="1.0"?>
+<soap:Envelope xmlns:soap="http://www.w3.org/2003/05/soap-envelope">
+  <soap:Body>
+    <m:Price xmlns:m="urn:x" cur="EUR">1 &amp; 2</m:Price>
+    <empty/>
+  </soap:Body>
+</soap:Envelope>
+`
+	if string(out) != want {
+		t.Errorf("formatBody(xml) =\n%s\nwant\n%s", out, want)
+	}
+}
+
+func TestFormatBody_InvalidXMLUnchanged(t *testing.T) {
+	src := `<a><b></a>`
+	out, syntax := formatBody("application/xml", []byte(src))
+	if syntax != SyntaxNone || string(out) != src {
+		t.Errorf("invalid XML should be unchanged: %q", out)
+	}
+}
+
+func TestFormatBody_HTML(t *testing.T) {
+	src := `<!DOCTYPE html><html><head><meta charset="utf-8"><title>Hi</title></head><body><p>a&nbsp;b<br>c</p><div></div></body></html>`
+	out, syntax := formatBody("text/html", []byte(src))
+	if syntax != SyntaxXML {
+		t.Fatalf("syntax = %q, out = %q", syntax, out)
+	}
+	s := string(out)
+	for _, want := range []string{"<!DOCTYPE html>\n", "\n  <head>\n", "    <meta charset=\"utf-8\">\n", "    <title>Hi</title>\n", "      <br>\n", "    <div></div>\n"} {
+		if !strings.Contains(s, want) {
+			t.Errorf("HTML output should contain %q:\n%s", want, s)
+		}
+	}
+}
+
+func TestFormatBody_Form(t *testing.T) {
+	out, _ := formatBody("application/x-www-form-urlencoded", []byte("b=2&a=hello+world&c=%26"))
+	want := "b = 2\na = hello world\nc = &\n"
+	if string(out) != want {
+		t.Errorf("form output = %q, want %q", out, want)
+	}
+}
+
+func TestRegisterFormatter_Override(t *testing.T) {
+	saved := formatters
+	defer func() { formatters = saved }()
+	RegisterFormatter(func(mt string) bool { return mt == "text/csv" }, SyntaxNone, func(b []byte) ([]byte, error) {
+		return bytes.ToUpper(b), nil
+	})
+	out, _ := formatBody("text/csv", []byte("a,b"))
+	if string(out) != "A,B" {
+		t.Errorf("registered formatter not used: %q", out)
+	}
+}
+
+func TestRender_ProblemJSON(t *testing.T) {
+	resp := newResp(t, 400, map[string]string{"Content-Type": "application/problem+json"}, `{"title":"bad"}`)
+	var buf bytes.Buffer
+	if err := Render(resp, &buf, RenderOpts{}); err != nil {
+		t.Fatalf("Render() error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "  \"title\": \"bad\"") {
+		t.Errorf("problem+json should be pretty-printed: %q", buf.String())
+	}
+}
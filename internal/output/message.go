@@ -0,0 +1,40 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// WriteMessage prints one message of a stream (an SSE event's data or a
+// WebSocket frame) on its own line and reports whether it was shown. With
+// jqExpr set, each result is printed as compact JSON and messages that are
+// not JSON are skipped.
+func WriteMessage(out io.Writer, data []byte, jqExpr string, color bool) (bool, error) {
+	if jqExpr != "" {
+		var v interface{}
+		if json.Unmarshal(data, &v) != nil {
+			return false, nil // e.g. a "[DONE]" sentinel
+		}
+		results, err := runJQ(jqExpr, v)
+		if err != nil {
+			return false, err
+		}
+		for _, res := range results {
+			line, err := json.Marshal(res)
+			if err != nil {
+				return false, err
+			}
+			if color {
+				line = colorJSON(line)
+			}
+			fmt.Fprintf(out, "%s\n", line)
+		}
+		return true, nil
+	}
+	if color && looksLikeJSON(data) {
+		data = colorJSON(data)
+	}
+	fmt.Fprintf(out, "%s\n", data)
+	return true, nil
+}
@@ -1,84 +1,313 @@
 package output
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
+	"sort"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/itchyny/gojq"
 )
 
 // RenderOpts controls how the response is printed.
 type RenderOpts struct {
-	ShowHeaders bool // -i / --include
-	RawOutput   bool // --raw
-	JQExpr      string
+	ShowHeaders bool   // -i / --include
+	RawOutput   bool   // --raw
+	JQExpr      string // --jq
+	Format      string // --output: json, yaml, table, csv, jsonl (empty = auto)
+	Color       bool   // ANSI highlighting, see ColorEnabled
+
+	OutputFile    string       // --output-file / -O: stream the body here ("-" forces stdout)
+	Download      DownloadOpts // progress, resume offset and checksum for OutputFile
+	MaxFormatSize int64        // larger bodies are streamed unformatted (0 = DefaultMaxFormatSize)
+	GraphQL       bool         // fail when the response carries a GraphQL "errors" array
 }
 
+// DefaultMaxFormatSize is the largest body buffered for pretty‑printing.
+// Explicit --jq or --output requests always read the whole body.
+const DefaultMaxFormatSize = 10 << 20
+
+// isTTY is swapped in tests to simulate a terminal.
+var isTTY = isTerminal
+
 // Render writes the HTTP response to out according to opts.
 func Render(resp *http.Response, out io.Writer, opts RenderOpts) error {
+	if !opts.GraphQL {
+		return render(resp, out, opts)
+	}
+	// keep a copy of the body whichever way it is written, so errors are
+	// found in streamed and saved responses too
+	var raw bytes.Buffer
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.TeeReader(resp.Body, &raw), resp.Body}
+	if err := render(resp, out, opts); err != nil {
+		return err
+	}
+	return graphQLErrors(raw.Bytes())
+}
+
+func render(resp *http.Response, out io.Writer, opts RenderOpts) error {
 	if opts.ShowHeaders {
-		fmt.Fprintf(out, "HTTP/%.1f %d %s\n", float64(resp.ProtoMajor)+float64(resp.ProtoMinor)/10, resp.StatusCode, resp.Status)
-		for k, vv := range resp.Header {
-			for _, v := range vv {
-				fmt.Fprintf(out, "%s: %s\n", k, v)
-			}
-		}
-		fmt.Fprintln(out)
+		writeHead(resp, out, opts.Color)
 	}
 
-	if opts.RawOutput {
-		_, err := io.Copy(out, resp.Body)
+	defer resp.Body.Close()
+
+	if opts.OutputFile != "" && opts.OutputFile != "-" {
+		_, err := Download(resp, opts.OutputFile, opts.Download)
 		return err
 	}
 
-	// Read all body (small responses – for large streams you would stream).
-	bodyBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
+	if IsSSE(resp) && !opts.RawOutput && opts.OutputFile == "" {
+		return StreamSSE(resp.Body, out, SSEOpts{JQExpr: opts.JQExpr, Color: opts.Color}, &SSEState{})
+	}
+
+	body := bufio.NewReader(resp.Body)
+	if opts.OutputFile == "" && isTTY(out) {
+		sniff, _ := body.Peek(512)
+		if contentEncoded(resp) || isBinary(resp.Header.Get("Content-Type"), sniff) {
+			return fmt.Errorf("binary response (%s) not printed to the terminal; use --output-file <path>, -O, or --output-file - to force", describeSize(resp.ContentLength))
+		}
+	}
+
+	if opts.RawOutput || opts.OutputFile == "-" || contentEncoded(resp) {
+		_, err := io.Copy(out, body)
+		return err
+	}
+	// binary content piped elsewhere is passed through byte for byte
+	if sniff, _ := body.Peek(512); isBinary(resp.Header.Get("Content-Type"), sniff) {
+		_, err := io.Copy(out, body)
 		return err
 	}
-	defer resp.Body.Close()
 
-	ct := resp.Header.Get("Content-Type")
-	if strings.Contains(ct, "application/json") {
-		var pretty bytes.Buffer
-		if err = json.Indent(&pretty, bodyBytes, "", "  "); err == nil {
-			bodyBytes = pretty.Bytes()
+	var bodyBytes []byte
+	var err error
+	if opts.JQExpr != "" || opts.Format != "" {
+		bodyBytes, err = io.ReadAll(body)
+		if err != nil {
+			return err
+		}
+	} else {
+		limit := opts.MaxFormatSize
+		if limit <= 0 {
+			limit = DefaultMaxFormatSize
+		}
+		bodyBytes, err = io.ReadAll(io.LimitReader(body, limit+1))
+		if err != nil {
+			return err
+		}
+		if int64(len(bodyBytes)) > limit {
+			// too large to format – stream it through unchanged
+			if _, err = out.Write(bodyBytes); err != nil {
+				return err
+			}
+			_, err = io.Copy(out, body)
+			return err
 		}
 	}
 
+	return renderBody(resp.Header.Get("Content-Type"), bodyBytes, out, opts)
+}
+
+// RenderJSON prints a JSON document that did not come from an HTTP body,
+// such as a decoded gRPC message, honouring JQExpr, Format and Color.
+func RenderJSON(data []byte, out io.Writer, opts RenderOpts) error {
+	return renderBody("application/json", data, out, opts)
+}
+
+// renderBody formats, filters and highlights a complete body.
+func renderBody(contentType string, bodyBytes []byte, out io.Writer, opts RenderOpts) error {
+	var err error
+	var syntax Syntax
+	bodyBytes, syntax = formatBody(contentType, bodyBytes)
+
 	// JQ filter if requested.
+	var data interface{}
+	haveData := false
 	if opts.JQExpr != "" {
-		query, err := gojq.Parse(opts.JQExpr)
-		if err != nil {
-			return fmt.Errorf("invalid jq expression: %w", err)
-		}
-		var data interface{}
-		if err = json.Unmarshal(bodyBytes, &data); err != nil {
+		if err = DecodeJSON(bodyBytes, &data); err != nil {
 			return fmt.Errorf("cannot unmarshal JSON for jq: %w", err)
 		}
-		iter := query.Run(data)
-		results := []interface{}{}
-		for {
-			v, ok := iter.Next()
-			if !ok {
-				break
-			}
-			if err, isErr := v.(error); isErr {
-				return fmt.Errorf("jq execution error: %w", err)
-			}
-			results = append(results, v)
+		results, err := runJQ(opts.JQExpr, data)
+		if err != nil {
+			return err
 		}
 		outBytes, _ := json.MarshalIndent(results, "", "  ")
 		bodyBytes = outBytes
+		// a single result is shown as is by the structured formats
+		data, haveData = results, true
+		if len(results) == 1 {
+			data = results[0]
+		}
 	}
 
-	_, err = out.Write(bodyBytes)
+	switch {
+	case opts.Format == "" || opts.Format == "json" && haveData:
+		// auto mode, or jq output which is already indented JSON
+	case opts.Format == "json":
+		// keep the server's key order
+		var pretty bytes.Buffer
+		if err = json.Indent(&pretty, bodyBytes, "", "  "); err != nil {
+			return fmt.Errorf("--output json needs a JSON response: %w", err)
+		}
+		bodyBytes = pretty.Bytes()
+	default:
+		if !haveData {
+			if err = DecodeJSON(bodyBytes, &data); err != nil {
+				return fmt.Errorf("--output %s needs a JSON response: %w", opts.Format, err)
+			}
+		}
+		if bodyBytes, err = formatValue(data, opts.Format); err != nil {
+			return err
+		}
+	}
+
+	if opts.JQExpr != "" || opts.Format == "json" {
+		syntax = SyntaxJSON
+	} else if opts.Format != "" {
+		syntax = SyntaxNone
+	}
+	if opts.Color {
+		switch syntax {
+		case SyntaxJSON:
+			bodyBytes = colorJSON(bodyBytes)
+		case SyntaxXML:
+			bodyBytes = colorXML(bodyBytes)
+		}
+	}
+
+	if _, err = out.Write(bodyBytes); err != nil {
+		return err
+	}
 	if len(bodyBytes) > 0 && bodyBytes[len(bodyBytes)-1] != '\n' {
 		fmt.Fprintln(out)
 	}
-	return err
+	return nil
+}
+
+// graphQLErrors returns an error listing the messages of a GraphQL
+// response's "errors" array, or nil when there is none.
+func graphQLErrors(body []byte) error {
+	var resp struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if json.Unmarshal(body, &resp) != nil || len(resp.Errors) == 0 {
+		return nil
+	}
+	msgs := make([]string, len(resp.Errors))
+	for i, e := range resp.Errors {
+		msgs[i] = e.Message
+	}
+	return fmt.Errorf("graphql: %s", strings.Join(msgs, "; "))
+}
+
+// WriteHead prints the status line and headers the way Render does with
+// ShowHeaders.
+func WriteHead(resp *http.Response, out io.Writer, color bool) {
+	writeHead(resp, out, color)
+}
+
+// writeHead prints the status line and the response headers sorted by name.
+func writeHead(resp *http.Response, out io.Writer, color bool) {
+	// resp.Status usually repeats the code ("200 OK")
+	text := strings.TrimPrefix(resp.Status, strconv.Itoa(resp.StatusCode)+" ")
+	status := fmt.Sprintf("HTTP/%.1f %d %s", float64(resp.ProtoMajor)+float64(resp.ProtoMinor)/10, resp.StatusCode, text)
+	if color {
+		status = paint(ansiBold+statusColor(resp.StatusCode), status)
+	}
+	fmt.Fprintln(out, status)
+
+	keys := make([]string, 0, len(resp.Header))
+	for k := range resp.Header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		name := k
+		if color {
+			name = paint(ansiCyan, k)
+		}
+		for _, v := range resp.Header[k] {
+			fmt.Fprintf(out, "%s: %s\n", name, v)
+		}
+	}
+	fmt.Fprintln(out)
+}
+
+// isBinary guesses whether a body should not be printed as text, from its
+// Content-Type or, for unknown types, from the first bytes.
+func isBinary(contentType string, sniff []byte) bool {
+	mt, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case strings.HasPrefix(mt, "text/"), isJSONType(mt), isXMLType(mt), isHTMLType(mt),
+		mt == "application/javascript", mt == "application/x-www-form-urlencoded", mt == "application/graphql":
+		return false
+	case strings.HasPrefix(mt, "image/"), strings.HasPrefix(mt, "audio/"), strings.HasPrefix(mt, "video/"),
+		strings.HasPrefix(mt, "font/"), strings.HasPrefix(mt, "application/grpc"),
+		mt == "application/octet-stream", mt == "application/pdf", mt == "application/zip",
+		mt == "application/gzip", mt == "application/x-protobuf", mt == "application/protobuf",
+		mt == "application/msgpack", mt == "application/x-msgpack":
+		return true
+	}
+	if bytes.IndexByte(sniff, 0) >= 0 {
+		return true
+	}
+	// ignore a multi‑byte rune cut off at the end of the sniffed window
+	for i := 0; i < utf8.UTFMax && len(sniff) > 0 && !utf8.Valid(sniff); i++ {
+		sniff = sniff[:len(sniff)-1]
+	}
+	return !utf8.Valid(sniff)
+}
+
+// contentEncoded reports whether the body is still compressed, as with
+// --no-decompress; such bodies are never formatted.
+func contentEncoded(resp *http.Response) bool {
+	enc := strings.TrimSpace(resp.Header.Get("Content-Encoding"))
+	return enc != "" && !strings.EqualFold(enc, "identity")
+}
+
+func describeSize(n int64) string {
+	if n < 0 {
+		return "unknown size"
+	}
+	return HumanBytes(n)
+}
+
+// RunJQ evaluates a jq expression against decoded JSON data, as --jq does,
+// and returns every emitted value.
+func RunJQ(expr string, data interface{}) ([]interface{}, error) {
+	return runJQ(expr, data)
+}
+
+// runJQ evaluates a jq expression against decoded JSON data and collects
+// every emitted value.
+func runJQ(expr string, data interface{}) ([]interface{}, error) {
+	query, err := gojq.Parse(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid jq expression: %w", err)
+	}
+	iter := query.Run(data)
+	results := []interface{}{}
+	for {
+		v, ok := iter.Next()
+		if !ok {
+			break
+		}
+		if err, isErr := v.(error); isErr {
+			return nil, fmt.Errorf("jq execution error: %w", err)
+		}
+		results = append(results, v)
+	}
+	return results, nil
 }
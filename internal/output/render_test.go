@@ -5,6 +5,8 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -259,3 +261,130 @@ func TestRender_ServerResponse(t *testing.T) {
 		t.Errorf("output should contain response body: %q", buf.String())
 	}
 }
+
+func TestRender_LargeBodyNotFormatted(t *testing.T) {
+	raw := `{"name":"test","items":[1,2,3]}`
+	resp := newResp(t, 200, map[string]string{"Content-Type": "application/json"}, raw)
+	var buf bytes.Buffer
+	if err := Render(resp, &buf, RenderOpts{MaxFormatSize: 10}); err != nil {
+		t.Fatalf("Render() error: %v", err)
+	}
+	if buf.String() != raw {
+		t.Errorf("bodies over the threshold should stream unchanged: %q", buf.String())
+	}
+}
+
+func TestRender_BinaryRefusedOnTerminal(t *testing.T) {
+	defer func(f func(io.Writer) bool) { isTTY = f }(isTTY)
+	isTTY = func(io.Writer) bool { return true }
+
+	resp := newResp(t, 200, map[string]string{"Content-Type": "image/png"}, "\x89PNG\r\n\x1a\n")
+	var buf bytes.Buffer
+	err := Render(resp, &buf, RenderOpts{})
+	if err == nil || !strings.Contains(err.Error(), "--output-file") {
+		t.Fatalf("expected refusal, got %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("nothing should be written: %q", buf.String())
+	}
+}
+
+func TestRender_EncodedBody(t *testing.T) {
+	defer func(f func(io.Writer) bool) { isTTY = f }(isTTY)
+	isTTY = func(io.Writer) bool { return true }
+
+	// a body kept compressed (--no-decompress) is neither shown on a
+	// terminal nor pretty-printed
+	headers := map[string]string{"Content-Type": "application/json", "Content-Encoding": "gzip"}
+	var buf bytes.Buffer
+	if err := Render(newResp(t, 200, headers, "{}"), &buf, RenderOpts{}); err == nil {
+		t.Error("encoded body should be refused on a terminal")
+	}
+	isTTY = func(io.Writer) bool { return false }
+	if err := Render(newResp(t, 200, headers, `{"a":1}`), &buf, RenderOpts{}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != `{"a":1}` {
+		t.Errorf("encoded body should be copied unchanged: %q", buf.String())
+	}
+}
+
+func TestRender_BinaryPiped(t *testing.T) {
+	// no newline is added, so piped downloads stay intact
+	for ct, body := range map[string]string{
+		"application/octet-stream": "\x00\x01\x02",
+		"image/png":                "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR",
+	} {
+		resp := newResp(t, 200, map[string]string{"Content-Type": ct}, body)
+		var buf bytes.Buffer
+		if err := Render(resp, &buf, RenderOpts{}); err != nil {
+			t.Fatalf("Render() error: %v", err)
+		}
+		if buf.String() != body {
+			t.Errorf("%s should pass through to a pipe unchanged: %q", ct, buf.String())
+		}
+	}
+}
+
+func TestIsBinary(t *testing.T) {
+	tests := []struct {
+		ct    string
+		sniff string
+		want  bool
+	}{
+		{"application/json", "{}", false},
+		{"text/plain; charset=utf-8", "hi", false},
+		{"image/jpeg", "", true},
+		{"", "plain text", false},
+		{"", "abc\x00def", true},
+		{"", "caf\xc3", false}, // rune cut at the sniff boundary
+		{"", "\xff\xfe\xfd\xfc\xfb", true},
+	}
+	for _, tt := range tests {
+		if got := isBinary(tt.ct, []byte(tt.sniff)); got != tt.want {
+			t.Errorf("isBinary(%q, %q) = %v, want %v", tt.ct, tt.sniff, got, tt.want)
+		}
+	}
+}
+
+func TestRender_GraphQLErrors(t *testing.T) {
+	body := `{"data":null,"errors":[{"message":"not found"},{"message":"denied"}]}`
+	resp := newResp(t, 200, map[string]string{"Content-Type": "application/json"}, body)
+	var buf bytes.Buffer
+	err := Render(resp, &buf, RenderOpts{GraphQL: true})
+	if err == nil || err.Error() != "graphql: not found; denied" {
+		t.Errorf("err = %v", err)
+	}
+	if !strings.Contains(buf.String(), "not found") {
+		t.Errorf("body should still be printed: %q", buf.String())
+	}
+}
+
+func TestRender_GraphQLErrorsStreamedOrSaved(t *testing.T) {
+	body := `{"errors":[{"message":"denied"}],"data":{"blob":"` + strings.Repeat("x", 64) + `"}}`
+	resp := newResp(t, 200, map[string]string{"Content-Type": "application/json"}, body)
+	var buf bytes.Buffer
+	// larger than MaxFormatSize, so streamed through unformatted
+	err := Render(resp, &buf, RenderOpts{GraphQL: true, MaxFormatSize: 16})
+	if err == nil || err.Error() != "graphql: denied" || buf.String() != body {
+		t.Errorf("streamed: err = %v, out = %q", err, buf.String())
+	}
+
+	path := filepath.Join(t.TempDir(), "out.json")
+	resp = newResp(t, 200, map[string]string{"Content-Type": "application/json"}, body)
+	resp.ContentLength = -1
+	if err := Render(resp, io.Discard, RenderOpts{GraphQL: true, OutputFile: path}); err == nil || err.Error() != "graphql: denied" {
+		t.Errorf("saved: err = %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != body {
+		t.Errorf("saved body = %q", data)
+	}
+}
+
+func TestRender_GraphQLSuccess(t *testing.T) {
+	resp := newResp(t, 200, map[string]string{"Content-Type": "application/json"}, `{"data":{"a":1},"errors":[]}`)
+	var buf bytes.Buffer
+	if err := Render(resp, &buf, RenderOpts{GraphQL: true, RawOutput: true}); err != nil {
+		t.Errorf("empty errors array should succeed: %v", err)
+	}
+}
@@ -0,0 +1,134 @@
+package output
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SSEEvent is one dispatched Server‑Sent Event.
+type SSEEvent struct {
+	ID    string
+	Event string // "message" when the stream sets no type
+	Data  string
+}
+
+// SSEOpts controls how StreamSSE prints events.
+type SSEOpts struct {
+	JQExpr    string // applied to each event's data when it is JSON; other events are skipped
+	MaxEvents int    // stop after this many events in total (0 = unlimited)
+	Color     bool
+}
+
+// SSEState carries what a reconnecting client needs across connections.
+type SSEState struct {
+	LastEventID string
+	Retry       time.Duration // reconnection delay requested by the server (0 = unset)
+	Events      int           // events printed so far
+}
+
+// Done reports whether the MaxEvents limit has been reached.
+func (s *SSEState) Done(opts SSEOpts) bool {
+	return opts.MaxEvents > 0 && s.Events >= opts.MaxEvents
+}
+
+// IsSSE reports whether resp is a text/event-stream.
+func IsSSE(resp *http.Response) bool {
+	mt, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	return mt == "text/event-stream"
+}
+
+// StreamSSE prints events from r as they arrive until the stream ends or
+// opts.MaxEvents is reached. A clean end of stream returns nil; state is
+// updated in place so the caller can reconnect with Last-Event-ID.
+func StreamSSE(r io.Reader, out io.Writer, opts SSEOpts, state *SSEState) error {
+	if state.Done(opts) {
+		return nil
+	}
+	return ReadSSE(r, state, func(ev SSEEvent) error {
+		printed, err := writeEvent(out, ev, opts)
+		if err != nil {
+			return err
+		}
+		if printed {
+			state.Events++
+		}
+		if state.Done(opts) {
+			return errStopStream
+		}
+		return nil
+	})
+}
+
+var errStopStream = errors.New("stop stream")
+
+// ReadSSE parses an event stream and calls fn for every dispatched event.
+// id and retry fields are recorded in state as they are read.
+func ReadSSE(r io.Reader, state *SSEState, fn func(SSEEvent) error) error {
+	br := bufio.NewReader(r)
+	var data strings.Builder
+	event := ""
+	for {
+		line, err := br.ReadString('\n')
+		if errors.Is(err, io.EOF) {
+			return nil // an unterminated final event is discarded
+		}
+		if err != nil {
+			return err
+		}
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+
+		if line == "" {
+			if data.Len() > 0 {
+				ev := SSEEvent{ID: state.LastEventID, Event: event, Data: strings.TrimSuffix(data.String(), "\n")}
+				if ev.Event == "" {
+					ev.Event = "message"
+				}
+				if ferr := fn(ev); ferr != nil {
+					if errors.Is(ferr, errStopStream) {
+						return nil
+					}
+					return ferr
+				}
+			}
+			data.Reset()
+			event = ""
+		} else if !strings.HasPrefix(line, ":") { // ":" starts a comment
+			field, value, _ := strings.Cut(line, ":")
+			value = strings.TrimPrefix(value, " ")
+			switch field {
+			case "data":
+				data.WriteString(value)
+				data.WriteByte('\n')
+			case "event":
+				event = value
+			case "id":
+				if !strings.ContainsRune(value, 0) {
+					state.LastEventID = value
+				}
+			case "retry":
+				if ms, perr := strconv.Atoi(value); perr == nil && ms >= 0 {
+					state.Retry = time.Duration(ms) * time.Millisecond
+				}
+			}
+		}
+	}
+}
+
+// writeEvent prints a single event and reports whether it was shown.
+func writeEvent(out io.Writer, ev SSEEvent, opts SSEOpts) (bool, error) {
+	if ev.Event != "message" && opts.JQExpr == "" {
+		label := "event: " + ev.Event
+		if opts.Color {
+			label = paint(ansiGray, label)
+		}
+		fmt.Fprintln(out, label)
+	}
+	return WriteMessage(out, []byte(ev.Data), opts.JQExpr, opts.Color)
+}
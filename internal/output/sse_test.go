@@ -0,0 +1,102 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadSSE_Fields(t *testing.T) {
+	stream := ": keep-alive\r\n" +
+		"retry: 250\r\n" +
+		"id: 1\r\n" +
+		"event: update\r\n" +
+		"data: line one\r\n" +
+		"data: line two\r\n" +
+		"\r\n" +
+		"data:no space\n\n" +
+		"id: 2\n\n" + // id without data dispatches nothing
+		"data: unterminated"
+	var state SSEState
+	var events []SSEEvent
+	err := ReadSSE(strings.NewReader(stream), &state, func(ev SSEEvent) error {
+		events = append(events, ev)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ReadSSE() error: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2: %+v", len(events), events)
+	}
+	if events[0] != (SSEEvent{ID: "1", Event: "update", Data: "line one\nline two"}) {
+		t.Errorf("event[0] = %+v", events[0])
+	}
+	if events[1] != (SSEEvent{ID: "1", Event: "message", Data: "no space"}) {
+		t.Errorf("event[1] = %+v", events[1])
+	}
+	if state.LastEventID != "2" {
+		t.Errorf("LastEventID = %q, want 2", state.LastEventID)
+	}
+	if state.Retry != 250*time.Millisecond {
+		t.Errorf("Retry = %v", state.Retry)
+	}
+}
+
+func TestStreamSSE_Print(t *testing.T) {
+	stream := "data: {\"n\":1}\n\nevent: ping\ndata: hi\n\n"
+	var buf bytes.Buffer
+	var state SSEState
+	if err := StreamSSE(strings.NewReader(stream), &buf, SSEOpts{}, &state); err != nil {
+		t.Fatalf("StreamSSE() error: %v", err)
+	}
+	want := "{\"n\":1}\nevent: ping\nhi\n"
+	if buf.String() != want {
+		t.Errorf("output = %q, want %q", buf.String(), want)
+	}
+	if state.Events != 2 {
+		t.Errorf("Events = %d", state.Events)
+	}
+}
+
+func TestStreamSSE_JQSkipsNonJSON(t *testing.T) {
+	stream := "data: {\"delta\":\"Hel\"}\n\ndata: {\"delta\":\"lo\"}\n\ndata: [DONE]\n\n"
+	var buf bytes.Buffer
+	var state SSEState
+	if err := StreamSSE(strings.NewReader(stream), &buf, SSEOpts{JQExpr: ".delta"}, &state); err != nil {
+		t.Fatalf("StreamSSE() error: %v", err)
+	}
+	if buf.String() != "\"Hel\"\n\"lo\"\n" {
+		t.Errorf("output = %q", buf.String())
+	}
+	if state.Events != 2 {
+		t.Errorf("Events = %d, want 2", state.Events)
+	}
+}
+
+func TestStreamSSE_MaxEvents(t *testing.T) {
+	stream := "id: a\ndata: 1\n\nid: b\ndata: 2\n\nid: c\ndata: 3\n\n"
+	var buf bytes.Buffer
+	state := SSEState{Events: 1} // one event already seen on a previous connection
+	if err := StreamSSE(strings.NewReader(stream), &buf, SSEOpts{MaxEvents: 3}, &state); err != nil {
+		t.Fatalf("StreamSSE() error: %v", err)
+	}
+	if buf.String() != "1\n2\n" {
+		t.Errorf("output = %q", buf.String())
+	}
+	if state.LastEventID != "b" {
+		t.Errorf("LastEventID = %q, want b", state.LastEventID)
+	}
+}
+
+func TestRender_SSE(t *testing.T) {
+	resp := newResp(t, 200, map[string]string{"Content-Type": "text/event-stream"}, "data: hello\n\n")
+	var buf bytes.Buffer
+	if err := Render(resp, &buf, RenderOpts{}); err != nil {
+		t.Fatalf("Render() error: %v", err)
+	}
+	if buf.String() != "hello\n" {
+		t.Errorf("output = %q", buf.String())
+	}
+}
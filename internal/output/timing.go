@@ -0,0 +1,52 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Timing summarises a finished request for --timing.
+type Timing struct {
+	StatusCode int
+	TTFB       time.Duration // until the response headers arrived
+	Total      time.Duration // until the body was fully handled
+	Encoding   string        // response Content-Encoding, "" when not encoded
+	Encoded    int64         // body bytes received
+	Decoded    int64         // body size after decoding, -1 when unknown
+}
+
+// WriteTiming prints t on one line, e.g.
+//
+//	200  ttfb 12ms  total 40ms  size 1.2 KiB gzip → 4.6 KiB
+func WriteTiming(out io.Writer, t Timing, color bool) {
+	status := strconv.Itoa(t.StatusCode)
+	if color {
+		status = paint(ansiBold+statusColor(t.StatusCode), status)
+	}
+	size := HumanBytes(t.Encoded)
+	if t.Encoding != "" {
+		size += " " + t.Encoding + " → " + describeSize(t.Decoded)
+	}
+	label := func(s string) string {
+		if color {
+			return paint(ansiGray, s)
+		}
+		return s
+	}
+	fmt.Fprintf(out, "%s  %s %s  %s %s  %s %s\n", status,
+		label("ttfb"), roundDuration(t.TTFB), label("total"), roundDuration(t.Total), label("size"), size)
+}
+
+// roundDuration trims a duration to a readable precision.
+func roundDuration(d time.Duration) time.Duration {
+	switch {
+	case d >= time.Second:
+		return d.Round(10 * time.Millisecond)
+	case d >= time.Millisecond:
+		return d.Round(100 * time.Microsecond)
+	default:
+		return d.Round(time.Microsecond)
+	}
+}
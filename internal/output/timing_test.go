@@ -0,0 +1,40 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestWriteTiming(t *testing.T) {
+	tests := []struct {
+		name string
+		in   Timing
+		want string
+	}{
+		{
+			name: "plain",
+			in:   Timing{StatusCode: 200, TTFB: 12345 * time.Microsecond, Total: 40 * time.Millisecond, Encoded: 512, Decoded: 512},
+			want: "200  ttfb 12.3ms  total 40ms  size 512 B\n",
+		},
+		{
+			name: "encoded",
+			in:   Timing{StatusCode: 404, TTFB: 1500 * time.Millisecond, Total: 2 * time.Second, Encoding: "br", Encoded: 1024, Decoded: 4096},
+			want: "404  ttfb 1.5s  total 2s  size 1.0 KiB br → 4.0 KiB\n",
+		},
+		{
+			name: "undecodable",
+			in:   Timing{StatusCode: 200, TTFB: 800 * time.Nanosecond, Total: 900 * time.Nanosecond, Encoding: "compress", Encoded: 10, Decoded: -1},
+			want: "200  ttfb 1µs  total 1µs  size 10 B compress → unknown size\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			WriteTiming(&buf, tt.in, false)
+			if buf.String() != tt.want {
+				t.Errorf("got %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package project
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Assertion is one check on the response of a saved call. Exactly one
+// subject is set: Status, Header, JQ, BodyContains, TimeUnderMS or Schema.
+// Header and JQ subjects are compared with Equals or Matches, or tested
+// with Exists; without any of them the header or value must exist.
+//
+//	assert:
+//	  - status: [200, 201]
+//	  - header: Content-Type
+//	    matches: ^application/json
+//	  - jq: .id
+//	    exists: true
+//	  - jq: .name
+//	    equals: Ada
+//	  - body_contains: ok
+//	  - time_under_ms: 500
+//	  - schema: schemas/user.json
+type Assertion struct {
+	Status       StatusCodes `yaml:"status,omitempty"` // equals, or one of several
+	Header       string      `yaml:"header,omitempty"`
+	JQ           string      `yaml:"jq,omitempty"`
+	BodyContains string      `yaml:"body_contains,omitempty"`
+	TimeUnderMS  int         `yaml:"time_under_ms,omitempty"` // total response time
+	Schema       string      `yaml:"schema,omitempty"`        // JSON Schema file the body must match, relative to the project directory
+
+	Equals  interface{} `yaml:"equals,omitempty"`
+	Matches string      `yaml:"matches,omitempty"` // regular expression
+	Exists  *bool       `yaml:"exists,omitempty"`
+}
+
+// Subjects returns the number of subjects set on a, which should be one.
+func (a Assertion) Subjects() int {
+	n := 0
+	for _, set := range []bool{len(a.Status) > 0, a.Header != "", a.JQ != "", a.BodyContains != "", a.TimeUnderMS > 0, a.Schema != ""} {
+		if set {
+			n++
+		}
+	}
+	return n
+}
+
+// StatusCodes is a status code or a list of accepted codes.
+type StatusCodes []int
+
+// UnmarshalYAML accepts a single code or a list.
+func (s *StatusCodes) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		var code int
+		if err := node.Decode(&code); err != nil {
+			return fmt.Errorf("line %d: status must be a code or a list of codes", node.Line)
+		}
+		*s = StatusCodes{code}
+		return nil
+	}
+	var codes []int
+	if err := node.Decode(&codes); err != nil {
+		return fmt.Errorf("line %d: status must be a code or a list of codes", node.Line)
+	}
+	*s = codes
+	return nil
+}
+
+// MarshalYAML writes a single code as a scalar.
+func (s StatusCodes) MarshalYAML() (interface{}, error) {
+	if len(s) == 1 {
+		return s[0], nil
+	}
+	return []int(s), nil
+}
@@ -0,0 +1,69 @@
+package project
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestAssertion_YAML(t *testing.T) {
+	src := `
+- status: 200
+- status: [200, 201]
+- jq: .active
+  equals: false
+- header: Content-Type
+  matches: ^application/json
+- time_under_ms: 500
+`
+	var list []Assertion
+	if err := yaml.Unmarshal([]byte(src), &list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 5 {
+		t.Fatalf("len = %d", len(list))
+	}
+	if len(list[0].Status) != 1 || list[0].Status[0] != 200 || len(list[1].Status) != 2 {
+		t.Errorf("status = %v, %v", list[0].Status, list[1].Status)
+	}
+	if list[2].Equals != false || list[2].Subjects() != 1 {
+		t.Errorf("equals = %#v", list[2].Equals)
+	}
+	if list[4].TimeUnderMS != 500 {
+		t.Errorf("time_under_ms = %d", list[4].TimeUnderMS)
+	}
+
+	out, err := yaml.Marshal(list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"- status: 200\n", "equals: false", "- 201\n"} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("marshalled YAML should contain %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestAssertion_InvalidStatus(t *testing.T) {
+	var a Assertion
+	if err := yaml.Unmarshal([]byte("status: ok"), &a); err == nil {
+		t.Error("a non-numeric status should be rejected")
+	}
+}
+
+func TestAssertion_Subjects(t *testing.T) {
+	if n := (Assertion{}).Subjects(); n != 0 {
+		t.Errorf("empty = %d", n)
+	}
+	if n := (Assertion{JQ: ".a", BodyContains: "x"}).Subjects(); n != 2 {
+		t.Errorf("two subjects = %d", n)
+	}
+}
+
+func TestCall_HasTag(t *testing.T) {
+	c := Call{Tags: []string{"smoke", "users"}}
+	if !c.HasTag("users") || c.HasTag("slow") {
+		t.Error("HasTag mismatch")
+	}
+}
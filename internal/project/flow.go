@@ -0,0 +1,39 @@
+package project
+
+// Flow is an ordered list of saved calls run by `reqo flow run`. Values
+// captured by a step become template vars of the steps after it.
+//
+//	flows:
+//	  signup:
+//	    steps:
+//	      - call: create-user
+//	        vars: {email: "ada@example.com"}
+//	        capture: {user_id: .id}
+//	        assert:
+//	          - status: 201
+//	      - call: verify-email
+//	        skip_if: .skip_verify == "true"
+//	      - call: get-user
+//	        vars: {id: "${user_id}"}
+type Flow struct {
+	Description string     `yaml:"description,omitempty"`
+	Steps       []FlowStep `yaml:"steps"`
+}
+
+// FlowStep runs one saved call.
+type FlowStep struct {
+	Name    string            `yaml:"name,omitempty"` // defaults to the call alias
+	Call    string            `yaml:"call"`
+	Vars    map[string]string `yaml:"vars,omitempty"`    // overrides for this step; ${var} templates are expanded
+	Capture map[string]string `yaml:"capture,omitempty"` // var name → jq expression on the JSON response
+	Assert  []Assertion       `yaml:"assert,omitempty"`  // checked along with the call's own assertions
+	SkipIf  string            `yaml:"skip_if,omitempty"` // jq expression on the vars; the step is skipped when it is true
+}
+
+// Label names the step in output.
+func (s FlowStep) Label() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return s.Call
+}
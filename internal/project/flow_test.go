@@ -0,0 +1,40 @@
+package project
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestFlow_YAML(t *testing.T) {
+	src := `
+flows:
+  signup:
+    steps:
+      - call: create-user
+        vars: {email: "${email}"}
+        capture: {user_id: .id}
+        assert:
+          - status: 201
+      - name: check
+        call: get-user
+        skip_if: .user_id == ""
+`
+	var p Project
+	if err := yaml.Unmarshal([]byte(src), &p); err != nil {
+		t.Fatal(err)
+	}
+	steps := p.Flows["signup"].Steps
+	if len(steps) != 2 {
+		t.Fatalf("steps = %+v", steps)
+	}
+	if steps[0].Vars["email"] != "${email}" || steps[0].Capture["user_id"] != ".id" || len(steps[0].Assert) != 1 {
+		t.Errorf("first step = %+v", steps[0])
+	}
+	if steps[1].SkipIf != `.user_id == ""` {
+		t.Errorf("skip_if = %q", steps[1].SkipIf)
+	}
+	if steps[0].Label() != "create-user" || steps[1].Label() != "check" {
+		t.Errorf("labels = %q, %q", steps[0].Label(), steps[1].Label())
+	}
+}
@@ -0,0 +1,175 @@
+package project
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FormField is one multipart part. Exactly one of Value, File or JSON is
+// the content; Type and Filename override the part headers.
+type FormField struct {
+	Name     string      `yaml:"name"`
+	Value    string      `yaml:"value,omitempty"`
+	File     string      `yaml:"file,omitempty"`     // upload the file at this path
+	JSON     interface{} `yaml:"json,omitempty"`     // inline JSON part (application/json unless Type is set)
+	Type     string      `yaml:"type,omitempty"`     // part Content-Type
+	Filename string      `yaml:"filename,omitempty"` // file name sent for the part
+}
+
+// Form is an ordered list of multipart parts; names may repeat. In YAML it
+// is a list whose items are curl-style strings ("name=@file;type=...") or
+// FormField mappings. A plain mapping of name to value is still accepted
+// and is sent in key order.
+type Form []FormField
+
+// ParseFormField parses curl -F syntax: "name=value" or "name=@file",
+// optionally followed by ";type=..." and ";filename=..." parameters.
+func ParseFormField(s string) (FormField, error) {
+	name, rest, ok := strings.Cut(s, "=")
+	if !ok || name == "" {
+		return FormField{}, fmt.Errorf("invalid form field %q – must be name=value or name=@file", s)
+	}
+	f := FormField{Name: name}
+	// parameters are peeled off the end so values may contain ";"
+	for {
+		i := strings.LastIndex(rest, ";")
+		if i < 0 {
+			break
+		}
+		key, val, _ := strings.Cut(strings.TrimSpace(rest[i+1:]), "=")
+		if key == "type" && f.Type == "" {
+			f.Type = val
+		} else if key == "filename" && f.Filename == "" {
+			f.Filename = val
+		} else {
+			break
+		}
+		rest = rest[:i]
+	}
+	if strings.HasPrefix(rest, "@") {
+		f.File = strings.TrimPrefix(rest, "@")
+	} else {
+		f.Value = rest
+	}
+	return f, nil
+}
+
+// String returns f in the syntax accepted by ParseFormField. Inline JSON
+// parts have no such form and are rendered as their name only.
+func (f FormField) String() string {
+	s := f.Name + "=" + f.Value
+	if f.File != "" {
+		s = f.Name + "=@" + f.File
+	}
+	if f.Type != "" {
+		s += ";type=" + f.Type
+	}
+	if f.Filename != "" {
+		s += ";filename=" + f.Filename
+	}
+	return s
+}
+
+// UnmarshalYAML accepts a list of strings or mappings, or a legacy
+// name→value mapping.
+func (f *Form) UnmarshalYAML(node *yaml.Node) error {
+	*f = nil
+	switch node.Kind {
+	case yaml.MappingNode:
+		var m map[string]string
+		if err := node.Decode(&m); err != nil {
+			return err
+		}
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			field, err := ParseFormField(k + "=" + m[k])
+			if err != nil {
+				return err
+			}
+			*f = append(*f, field)
+		}
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			if item.Kind == yaml.ScalarNode {
+				field, err := ParseFormField(item.Value)
+				if err != nil {
+					return fmt.Errorf("line %d: %w", item.Line, err)
+				}
+				*f = append(*f, field)
+				continue
+			}
+			var field FormField
+			if err := item.Decode(&field); err != nil {
+				return err
+			}
+			if field.Name == "" {
+				return fmt.Errorf("line %d: form field needs a name", item.Line)
+			}
+			*f = append(*f, field)
+		}
+	default:
+		return fmt.Errorf("line %d: form must be a list of fields", node.Line)
+	}
+	return nil
+}
+
+// MarshalYAML writes each field as a curl-style string when that round-trips
+// and as a mapping otherwise.
+func (f Form) MarshalYAML() (interface{}, error) {
+	items := make([]interface{}, len(f))
+	for i, field := range f {
+		if back, err := ParseFormField(field.String()); field.JSON == nil && err == nil && back == field {
+			items[i] = field.String()
+		} else {
+			items[i] = field
+		}
+	}
+	return items, nil
+}
+
+// URLForm is an ordered list of "name=value" fields sent as
+// application/x-www-form-urlencoded; names may repeat. A plain mapping of
+// name to value is still accepted and is sent in key order.
+type URLForm []string
+
+// UnmarshalYAML accepts a list of "name=value" strings or a legacy
+// name→value mapping.
+func (f *URLForm) UnmarshalYAML(node *yaml.Node) error {
+	*f = nil
+	switch node.Kind {
+	case yaml.MappingNode:
+		var m map[string]string
+		if err := node.Decode(&m); err != nil {
+			return err
+		}
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			*f = append(*f, k+"="+m[k])
+		}
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			var field string
+			if err := item.Decode(&field); err != nil {
+				return err
+			}
+			if !strings.Contains(field, "=") {
+				return fmt.Errorf("line %d: invalid form field %q – must be k=v", item.Line, field)
+			}
+			*f = append(*f, field)
+		}
+	default:
+		return fmt.Errorf("line %d: urlencoded must be a list of k=v fields", node.Line)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package project
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestParseFormField(t *testing.T) {
+	tests := []struct {
+		in   string
+		want FormField
+	}{
+		{"name=John", FormField{Name: "name", Value: "John"}},
+		{"avatar=@me.png", FormField{Name: "avatar", File: "me.png"}},
+		{"avatar=@me.bin;type=image/png;filename=x.png", FormField{Name: "avatar", File: "me.bin", Type: "image/png", Filename: "x.png"}},
+		{"avatar=@me.bin; filename=x.png; type=image/png", FormField{Name: "avatar", File: "me.bin", Type: "image/png", Filename: "x.png"}},
+		{`meta={"a":"b;c"};type=application/json`, FormField{Name: "meta", Value: `{"a":"b;c"}`, Type: "application/json"}},
+		{"note=a;b", FormField{Name: "note", Value: "a;b"}},
+		{"tags[]=", FormField{Name: "tags[]"}},
+	}
+	for _, tt := range tests {
+		got, err := ParseFormField(tt.in)
+		if err != nil {
+			t.Errorf("ParseFormField(%q) error: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseFormField(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+	for _, bad := range []string{"novalue", "=value"} {
+		if _, err := ParseFormField(bad); err == nil {
+			t.Errorf("ParseFormField(%q) should fail", bad)
+		}
+	}
+}
+
+func TestForm_UnmarshalYAML(t *testing.T) {
+	var body BodySpec
+	src := `
+form:
+  - tags[]=a
+  - tags[]=b
+  - photo=@${file};type=image/jpeg
+  - name: meta
+    json: {id: 7, labels: [x]}
+`
+	if err := yaml.Unmarshal([]byte(src), &body); err != nil {
+		t.Fatalf("Unmarshal() error: %v", err)
+	}
+	if len(body.Form) != 4 || body.Form[1].Value != "b" || body.Form[2].Type != "image/jpeg" {
+		t.Fatalf("form = %+v", body.Form)
+	}
+	if m, ok := body.Form[3].JSON.(map[string]interface{}); !ok || m["id"] != 7 {
+		t.Errorf("json part = %#v", body.Form[3].JSON)
+	}
+}
+
+func TestForm_UnmarshalYAMLLegacyMapping(t *testing.T) {
+	var body BodySpec
+	if err := yaml.Unmarshal([]byte("form:\n  file: \"@data.txt\"\n  desc: hello\n"), &body); err != nil {
+		t.Fatalf("Unmarshal() error: %v", err)
+	}
+	want := Form{{Name: "desc", Value: "hello"}, {Name: "file", File: "data.txt"}}
+	if len(body.Form) != 2 || body.Form[0] != want[0] || body.Form[1] != want[1] {
+		t.Errorf("form = %+v", body.Form)
+	}
+}
+
+func TestForm_MarshalYAML(t *testing.T) {
+	body := BodySpec{Form: Form{
+		{Name: "b", Value: "2"},
+		{Name: "a", File: "x.png", Type: "image/png"},
+		{Name: "literal", Value: "@not-a-file"},
+		{Name: "meta", JSON: map[string]interface{}{"k": "v"}},
+	}}
+	out, err := yaml.Marshal(body)
+	if err != nil {
+		t.Fatalf("Marshal() error: %v", err)
+	}
+	s := string(out)
+	if !strings.Contains(s, "- b=2\n") || !strings.Contains(s, "- a=@x.png;type=image/png\n") {
+		t.Errorf("simple fields should be strings:\n%s", s)
+	}
+	if !strings.Contains(s, "value: '@not-a-file'") || !strings.Contains(s, "name: meta") {
+		t.Errorf("ambiguous and JSON fields should be mappings:\n%s", s)
+	}
+
+	var back BodySpec
+	if err := yaml.Unmarshal(out, &back); err != nil {
+		t.Fatalf("Unmarshal() error: %v", err)
+	}
+	if len(back.Form) != 4 || back.Form[0].Name != "b" || back.Form[2].Value != "@not-a-file" {
+		t.Errorf("round trip = %+v", back.Form)
+	}
+}
+
+func TestURLForm_UnmarshalYAML(t *testing.T) {
+	var body BodySpec
+	if err := yaml.Unmarshal([]byte("urlencoded:\n  - scope=read\n  - scope=write\n  - a=1\n"), &body); err != nil {
+		t.Fatalf("Unmarshal() error: %v", err)
+	}
+	if strings.Join(body.URLEncoded, "&") != "scope=read&scope=write&a=1" {
+		t.Errorf("urlencoded = %q", body.URLEncoded)
+	}
+	if err := yaml.Unmarshal([]byte("urlencoded:\n  b: \"2\"\n  a: x=y\n"), &body); err != nil {
+		t.Fatalf("Unmarshal() legacy error: %v", err)
+	}
+	if strings.Join(body.URLEncoded, "&") != "a=x=y&b=2" {
+		t.Errorf("legacy urlencoded = %q", body.URLEncoded)
+	}
+	if err := yaml.Unmarshal([]byte("urlencoded:\n  - novalue\n"), &body); err == nil || !strings.Contains(err.Error(), "must be k=v") {
+		t.Errorf("expected an error for a field without =, got %v", err)
+	}
+}
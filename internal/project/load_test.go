@@ -153,7 +153,7 @@ func TestSave_BodySpec(t *testing.T) {
 				Method: "POST",
 				Path:   "/upload",
 				Body: &BodySpec{
-					Form: map[string]string{"file": "@data.txt", "desc": "hello"},
+					Form: Form{{Name: "file", File: "data.txt"}, {Name: "desc", Value: "hello"}},
 				},
 			},
 		},
@@ -7,15 +7,36 @@ type Project struct {
 	Environments map[string]Environment `yaml:"environments,omitempty"`
 	HeaderSets   map[string][]string    `yaml:"header_sets,omitempty"` // name → list of “Key: Value”
 	Calls        map[string]Call        `yaml:"calls,omitempty"`       // alias → definition
+	Flows        map[string]Flow        `yaml:"flows,omitempty"`       // name → ordered steps, see Flow
+	Contract     string                 `yaml:"contract,omitempty"`    // OpenAPI file checked by req and call run, relative to the project directory
+	History      HistorySettings        `yaml:"history,omitempty"`     // request log kept by req and call run
+}
+
+// HistorySettings control the log of requests sent with `reqo req` and
+// `reqo call run`. By default it is kept in .reqo/history.jsonl without
+// response bodies.
+type HistorySettings struct {
+	Off          bool `yaml:"off,omitempty"`           // keep no history
+	Global       bool `yaml:"global,omitempty"`        // use ~/.reqo/history.jsonl, shared by all projects
+	ResponseBody int  `yaml:"response_body,omitempty"` // bytes of each response body kept, 0 for none
 }
 
 type Environment struct {
-	BaseURL string   `yaml:"base_url,omitempty"`
-	Headers []string `yaml:"headers,omitempty"` // raw header lines
+	BaseURL     string   `yaml:"base_url,omitempty"`
+	Headers     []string `yaml:"headers,omitempty"`      // raw header lines
+	GRPCAddress string   `yaml:"grpc_address,omitempty"` // gRPC target when it differs from base_url
 }
 
+// Call types stored in Call.Type.
+const (
+	CallHTTP      = ""     // default
+	CallWebSocket = "ws"   // run with `reqo ws <alias>`
+	CallGRPC      = "grpc" // run with `reqo grpc <alias>`
+)
+
 // Call describes a saved request.
 type Call struct {
+	Type         string            `yaml:"type,omitempty"`   // CallHTTP, CallWebSocket or CallGRPC
 	Method       string            `yaml:"method,omitempty"` // GET, POST …
 	Path         string            `yaml:"path,omitempty"`   // may contain ${var}
 	Headers      []string          `yaml:"headers,omitempty"`
@@ -24,10 +45,50 @@ type Call struct {
 	UseHeaderSet string            `yaml:"uses_header_set,omitempty"` // name of a header set
 	Description  string            `yaml:"description,omitempty"`
 	LastUsed     string            `yaml:"last_used,omitempty"` // timestamp (optional)
+	Send         []string          `yaml:"send,omitempty"`      // WebSocket messages sent on connect
+	GRPC         *GRPCSpec         `yaml:"grpc,omitempty"`      // descriptor source for gRPC calls
+	Tags         []string          `yaml:"tags,omitempty"`      // for selecting calls, e.g. `reqo test --tag smoke`
+	Assert       []Assertion       `yaml:"assert,omitempty"`    // checked by `reqo test`
+
+	SnapshotIgnore []string `yaml:"snapshot_ignore,omitempty"` // jq paths left out of snapshots, e.g. .created_at
+}
+
+// HasTag reports whether the call is tagged tag.
+func (c Call) HasTag(tag string) bool {
+	for _, t := range c.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// GRPCSpec tells `reqo grpc` where to find descriptors for a saved call;
+// without protos or a protoset server reflection is used. The method
+// ("package.Service/Method") is stored in Call.Path and the JSON request in
+// Body.JSON.
+type GRPCSpec struct {
+	Protos      []string `yaml:"protos,omitempty"`
+	ImportPaths []string `yaml:"import_paths,omitempty"`
+	Protoset    string   `yaml:"protoset,omitempty"` // FileDescriptorSet file
+	Web         bool     `yaml:"web,omitempty"`      // use gRPC-Web over HTTP
 }
 
 type BodySpec struct {
-	JSON *string           `yaml:"json,omitempty"` // raw JSON string or @file
-	Raw  *string           `yaml:"raw,omitempty"`  // raw body string or @file
-	Form map[string]string `yaml:"form,omitempty"` // key=value, file=@path supported
+	JSON   *string `yaml:"json,omitempty"`   // raw JSON string or @file
+	Raw    *string `yaml:"raw,omitempty"`    // raw body string or @file
+	Binary *string `yaml:"binary,omitempty"` // @file streamed as is, never template-expanded
+	Form   Form    `yaml:"form,omitempty"`   // multipart parts in order, see Form
+
+	URLEncoded URLForm `yaml:"urlencoded,omitempty"` // application/x-www-form-urlencoded fields in order, see URLForm
+
+	GraphQL *GraphQLSpec `yaml:"graphql,omitempty"`
+}
+
+// GraphQLSpec is a GraphQL operation saved with a call. Variables are real
+// YAML/JSON values; ${var} templates inside their strings are expanded.
+type GraphQLSpec struct {
+	Query         string                 `yaml:"query"` // inline document or @file.graphql
+	Variables     map[string]interface{} `yaml:"variables,omitempty"`
+	OperationName string                 `yaml:"operationName,omitempty"`
 }
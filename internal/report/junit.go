@@ -0,0 +1,125 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Errors   int          `xml:"errors,attr"`
+	Time     string       `xml:"time,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Errors     int             `xml:"errors,attr"`
+	Time       string          `xml:"time,attr"`
+	Timestamp  string          `xml:"timestamp,attr,omitempty"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	Cases      []junitCase     `xml:"testcase"`
+	SystemOut  *junitText      `xml:"system-out,omitempty"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",cdata"` // CDATA keeps the line breaks readable
+}
+
+type junitText struct {
+	Text string `xml:",cdata"`
+}
+
+// WriteJUnit writes r as JUnit XML: a testsuite per call and a testcase
+// per assertion, with the request as the suite's output and the request
+// and response excerpt in each failure. A call whose request failed has a
+// single "request" testcase with an error.
+func WriteJUnit(w io.Writer, r Run) error {
+	doc := junitSuites{Name: r.Name, Time: seconds(r.Duration)}
+	for _, c := range r.Cases {
+		s := junitSuite{
+			Name:      c.Name,
+			Time:      seconds(c.Duration),
+			Timestamp: r.Timestamp.UTC().Format("2006-01-02T15:04:05"),
+		}
+		if c.Status != 0 {
+			s.Properties = append(s.Properties, junitProperty{Name: "status", Value: fmt.Sprint(c.Status)})
+		}
+		switch {
+		case c.Error != "":
+			s.Errors++
+			s.Cases = append(s.Cases, junitCase{Name: "request", ClassName: c.Name, Time: seconds(c.Duration),
+				Error: &junitProblem{Message: c.Error, Text: problemText(c, c.Error)}})
+		case len(c.Checks) == 0:
+			s.Cases = append(s.Cases, junitCase{Name: "request", ClassName: c.Name, Time: seconds(c.Duration)})
+		}
+		if c.Error == "" {
+			for _, ch := range c.Checks {
+				tc := junitCase{Name: ch.Name, ClassName: c.Name, Time: seconds(0)}
+				if !ch.Passed {
+					s.Failures++
+					tc.Failure = &junitProblem{Message: ch.Message, Text: problemText(c, ch.Message)}
+				}
+				s.Cases = append(s.Cases, tc)
+			}
+		}
+		s.Tests = len(s.Cases)
+		if c.Request != "" {
+			s.SystemOut = &junitText{c.Request}
+		}
+		doc.Tests += s.Tests
+		doc.Failures += s.Failures
+		doc.Errors += s.Errors
+		doc.Suites = append(doc.Suites, s)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// problemText is the body of a failure or error element.
+func problemText(c Case, msg string) string {
+	var b strings.Builder
+	b.WriteString(msg)
+	if c.Request != "" {
+		b.WriteString("\n\nRequest:\n" + c.Request)
+	}
+	if c.Status != 0 {
+		fmt.Fprintf(&b, "\n\nResponse (%d):\n%s", c.Status, c.Excerpt)
+	}
+	return b.String()
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
@@ -0,0 +1,53 @@
+package report
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestWriteJUnit(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJUnit(&buf, sampleRun()); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.HasPrefix(out, xml.Header) {
+		t.Errorf("missing XML header: %q", out[:40])
+	}
+
+	var doc junitSuites
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, out)
+	}
+	if doc.Name != "shop" || doc.Tests != 4 || doc.Failures != 1 || doc.Errors != 1 || doc.Time != "1.500" {
+		t.Errorf("testsuites = %+v", doc)
+	}
+	if len(doc.Suites) != 3 {
+		t.Fatalf("suites = %d", len(doc.Suites))
+	}
+
+	user := doc.Suites[1]
+	if user.Name != "get-user" || user.Tests != 2 || user.Failures != 1 || user.Time != "1.200" || user.Timestamp != "2024-05-01T12:00:00" {
+		t.Errorf("get-user suite = %+v", user)
+	}
+	if user.SystemOut == nil || !strings.Contains(user.SystemOut.Text, "Authorization: REDACTED") {
+		t.Errorf("system-out should hold the request: %+v", user.SystemOut)
+	}
+	if !strings.Contains(out, "<![CDATA[got \"Bob\"\n\nRequest:\n") {
+		t.Errorf("failure text should keep its line breaks:\n%s", out)
+	}
+	f := user.Cases[1].Failure
+	if f == nil || f.Message != `got "Bob"` || !strings.Contains(f.Text, "Response (200):\n{\"name\":\"Bob\"}") {
+		t.Errorf("failure = %+v", f)
+	}
+	if user.Cases[1].ClassName != "get-user" || user.Cases[1].Name != `jq .name == "Ada"` {
+		t.Errorf("testcase = %+v", user.Cases[1])
+	}
+
+	down := doc.Suites[2]
+	if len(down.Cases) != 1 || down.Cases[0].Error == nil || down.Cases[0].Name != "request" {
+		t.Errorf("failed request should be one errored testcase: %+v", down)
+	}
+}
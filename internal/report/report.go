@@ -0,0 +1,104 @@
+// Package report writes test run results as JUnit XML or TAP for CI
+// systems.
+package report
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// Formats lists the supported report formats.
+var Formats = []string{"junit", "tap"}
+
+// MaxExcerpt is the number of response body bytes kept for a failed call.
+const MaxExcerpt = 2048
+
+// Run is a whole test run, e.g. one `reqo test` invocation.
+type Run struct {
+	Name      string // suite name, typically the project
+	Timestamp time.Time
+	Duration  time.Duration
+	Cases     []Case
+}
+
+// Case is one call of a run.
+type Case struct {
+	Name     string // call alias
+	Duration time.Duration
+	Request  string // the request as a (redacted) curl command
+	Status   int    // 0 when no response was received
+	Error    string // the request could not be built, sent or read
+	Checks   []Check
+	Excerpt  string // start of the response body, set for failed calls
+}
+
+// Check is one assertion of a case.
+type Check struct {
+	Name    string
+	Passed  bool
+	Message string // why it failed
+}
+
+// Failed reports whether the request failed or any check did not pass.
+func (c Case) Failed() bool {
+	if c.Error != "" {
+		return true
+	}
+	for _, ch := range c.Checks {
+		if !ch.Passed {
+			return true
+		}
+	}
+	return false
+}
+
+// Excerpt returns at most MaxExcerpt bytes of body, marking the cut.
+func Excerpt(body []byte) string {
+	if len(body) <= MaxExcerpt {
+		return string(body)
+	}
+	return string(body[:MaxExcerpt]) + fmt.Sprintf("\n… (%d more bytes)", len(body)-MaxExcerpt)
+}
+
+// Target is where a report goes: "junit=out.xml" or "tap" (standard output
+// when Path is empty).
+type Target struct {
+	Format string
+	Path   string
+}
+
+// ParseTarget parses a --report value of the form format[=path].
+func ParseTarget(s string) (Target, error) {
+	format, path, _ := strings.Cut(s, "=")
+	t := Target{Format: strings.ToLower(strings.TrimSpace(format)), Path: strings.TrimSpace(path)}
+	for _, f := range Formats {
+		if f == t.Format {
+			return t, nil
+		}
+	}
+	return Target{}, fmt.Errorf("unknown report format %q (supported: %s)", format, strings.Join(Formats, ", "))
+}
+
+// Write renders r in the target's format to w.
+func (t Target) Write(w io.Writer, r Run) error {
+	if t.Format == "junit" {
+		return WriteJUnit(w, r)
+	}
+	return WriteTAP(w, r)
+}
+
+// WriteFile renders r into the target's file.
+func (t Target) WriteFile(r Run) error {
+	f, err := os.Create(t.Path)
+	if err != nil {
+		return err
+	}
+	if err := t.Write(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,62 @@
+package report
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// sampleRun has a passing call, a call with a failed assertion and a call
+// whose request failed.
+func sampleRun() Run {
+	return Run{
+		Name:      "shop",
+		Timestamp: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		Duration:  1500 * time.Millisecond,
+		Cases: []Case{
+			{Name: "health", Duration: 20 * time.Millisecond, Status: 200, Request: "curl -X GET 'http://x/health'",
+				Checks: []Check{{Name: "status == 200", Passed: true}}},
+			{Name: "get-user", Duration: 1200 * time.Millisecond, Status: 200, Request: `curl -X GET -H "Authorization: REDACTED" 'http://x/users/1'`,
+				Checks: []Check{
+					{Name: "status == 200", Passed: true},
+					{Name: `jq .name == "Ada"`, Message: `got "Bob"`},
+				},
+				Excerpt: `{"name":"Bob"}`},
+			{Name: "down", Request: "curl -X GET 'http://y/'", Error: "request failed: connection refused"},
+		},
+	}
+}
+
+func TestParseTarget(t *testing.T) {
+	tests := map[string]Target{
+		"junit=out.xml": {Format: "junit", Path: "out.xml"},
+		"tap":           {Format: "tap"},
+		"TAP=r.tap":     {Format: "tap", Path: "r.tap"},
+	}
+	for in, want := range tests {
+		got, err := ParseTarget(in)
+		if err != nil || got != want {
+			t.Errorf("ParseTarget(%q) = %+v, %v", in, got, err)
+		}
+	}
+	if _, err := ParseTarget("html=x"); err == nil || !strings.Contains(err.Error(), "unknown report format") {
+		t.Errorf("expected unknown format error, got %v", err)
+	}
+}
+
+func TestCase_Failed(t *testing.T) {
+	r := sampleRun()
+	if r.Cases[0].Failed() || !r.Cases[1].Failed() || !r.Cases[2].Failed() {
+		t.Error("Failed mismatch")
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	if got := Excerpt([]byte("short")); got != "short" {
+		t.Errorf("Excerpt = %q", got)
+	}
+	got := Excerpt([]byte(strings.Repeat("x", MaxExcerpt+10)))
+	if !strings.HasSuffix(got, "… (10 more bytes)") || len(got) > MaxExcerpt+30 {
+		t.Errorf("long excerpt = …%q", got[len(got)-30:])
+	}
+}
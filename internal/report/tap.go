@@ -0,0 +1,76 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// tapDiag is the YAML diagnostic block of a failed call.
+type tapDiag struct {
+	DurationMS int64  `yaml:"duration_ms"`
+	Status     int    `yaml:"status,omitempty"`
+	Error      string `yaml:"error,omitempty"`
+	Request    string `yaml:"request,omitempty"`
+	Response   string `yaml:"response,omitempty"`
+}
+
+// WriteTAP writes r as TAP version 13 with one test point per call and its
+// assertions as an indented subtest. Failed calls carry a YAML block with
+// the timing, the request and the response excerpt.
+func WriteTAP(w io.Writer, r Run) error {
+	var b strings.Builder
+	b.WriteString("TAP version 13\n")
+	fmt.Fprintf(&b, "1..%d\n", len(r.Cases))
+	for i, c := range r.Cases {
+		if len(c.Checks) > 0 && c.Error == "" {
+			fmt.Fprintf(&b, "# Subtest: %s\n", c.Name)
+			fmt.Fprintf(&b, "    1..%d\n", len(c.Checks))
+			for j, ch := range c.Checks {
+				fmt.Fprintf(&b, "    %s %d - %s\n", tapResult(ch.Passed), j+1, tapEscape(ch.Name))
+				if !ch.Passed {
+					writeTAPYAML(&b, "      ", map[string]string{"message": ch.Message})
+				}
+			}
+		}
+		fmt.Fprintf(&b, "%s %d - %s # time=%dms\n", tapResult(!c.Failed()), i+1, tapEscape(c.Name), c.Duration.Milliseconds())
+		if c.Failed() {
+			writeTAPYAML(&b, "  ", tapDiag{
+				DurationMS: c.Duration.Milliseconds(),
+				Status:     c.Status,
+				Error:      c.Error,
+				Request:    c.Request,
+				Response:   c.Excerpt,
+			})
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func tapResult(passed bool) string {
+	if passed {
+		return "ok"
+	}
+	return "not ok"
+}
+
+// tapEscape keeps a description from being read as a directive.
+func tapEscape(s string) string {
+	return strings.NewReplacer("\\", "\\\\", "#", "\\#", "\n", " ").Replace(s)
+}
+
+// writeTAPYAML writes v as a "---" … "..." block indented by indent.
+func writeTAPYAML(b *strings.Builder, indent string, v interface{}) {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return
+	}
+	b.WriteString(indent + "---\n")
+	for _, line := range strings.Split(strings.TrimRight(string(data), "\n"), "\n") {
+		b.WriteString(indent + line + "\n")
+	}
+	b.WriteString(indent + "...\n")
+}
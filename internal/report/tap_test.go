@@ -0,0 +1,49 @@
+package report
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteTAP(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteTAP(&buf, sampleRun()); err != nil {
+		t.Fatal(err)
+	}
+	want := `TAP version 13
+1..3
+# Subtest: health
+    1..1
+    ok 1 - status == 200
+ok 1 - health # time=20ms
+# Subtest: get-user
+    1..2
+    ok 1 - status == 200
+    not ok 2 - jq .name == "Ada"
+      ---
+      message: got "Bob"
+      ...
+not ok 2 - get-user # time=1200ms
+  ---
+  duration_ms: 1200
+  status: 200
+  request: 'curl -X GET -H "Authorization: REDACTED" ''http://x/users/1'''
+  response: '{"name":"Bob"}'
+  ...
+not ok 3 - down # time=0ms
+  ---
+  duration_ms: 0
+  error: 'request failed: connection refused'
+  request: curl -X GET 'http://y/'
+  ...
+`
+	if buf.String() != want {
+		t.Errorf("TAP output:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestTAPEscape(t *testing.T) {
+	if got := tapEscape("a # b\nc"); got != `a \# b c` {
+		t.Errorf("tapEscape = %q", got)
+	}
+}
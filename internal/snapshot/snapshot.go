@@ -0,0 +1,137 @@
+// Package snapshot stores normalized responses of saved calls so later runs
+// can be compared against them.
+package snapshot
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/itchyny/gojq"
+)
+
+// Snapshot is a normalized response: the status, the media type and the
+// body, decoded when it is JSON and kept as text otherwise. Values matched
+// by the ignore expressions are left out.
+type Snapshot struct {
+	Status      int         `json:"status"`
+	ContentType string      `json:"content_type,omitempty"`
+	Body        interface{} `json:"body"`
+}
+
+// Path is where the snapshot of alias in env is kept inside the project
+// directory: .reqo/snapshots/<env>/<alias>.json.
+func Path(projectDir, env, alias string) string {
+	return filepath.Join(projectDir, ".reqo", "snapshots", env, alias+".json")
+}
+
+// Normalize builds the snapshot of a response. Each ignore expression is a
+// jq path into the JSON body, such as .created_at or .items[].id, whose
+// values are deleted.
+func Normalize(status int, header http.Header, body []byte, ignore []string) (*Snapshot, error) {
+	s := &Snapshot{Status: status}
+	if ct := header.Get("Content-Type"); ct != "" {
+		s.ContentType, _, _ = mime.ParseMediaType(ct)
+	}
+	var doc interface{}
+	if err := decode(body, &doc); err != nil {
+		if len(ignore) > 0 && len(bytes.TrimSpace(body)) > 0 {
+			return nil, fmt.Errorf("ignore paths need a JSON response body")
+		}
+		s.Body = string(body)
+		return s, nil
+	}
+	s.Body = doc
+	if err := s.Ignore(ignore); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Ignore deletes the values matched by the ignore expressions from a JSON
+// body, so a snapshot recorded before an expression was added compares
+// like a fresh one. A text body is left as it is.
+func (s *Snapshot) Ignore(ignore []string) error {
+	if _, isText := s.Body.(string); isText || len(ignore) == 0 {
+		return nil
+	}
+	doc := s.Body
+	for _, expr := range ignore {
+		q, err := gojq.Parse("del(" + expr + ")")
+		if err != nil {
+			return fmt.Errorf("ignore path %q: %w", expr, err)
+		}
+		v, ok := q.Run(doc).Next()
+		if !ok {
+			continue
+		}
+		if err, isErr := v.(error); isErr {
+			return fmt.Errorf("ignore path %q: %w", expr, err)
+		}
+		doc = v
+	}
+	// gojq may hand back its own number types; store plain JSON values
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	s.Body = nil
+	return decode(data, &s.Body)
+}
+
+// Load reads a snapshot file.
+func Load(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var s Snapshot
+	if err := decode(data, &s); err != nil {
+		return nil, fmt.Errorf("read snapshot %s: %w", path, err)
+	}
+	return &s, nil
+}
+
+// decode unmarshals JSON keeping numbers as json.Number, so two ids that
+// differ only beyond float64 precision still compare as different.
+func decode(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return fmt.Errorf("invalid character after top-level value")
+	}
+	return nil
+}
+
+// Save writes s as indented JSON with sorted keys, creating the directory.
+func (s *Snapshot) Save(path string) error {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(s); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, b.Bytes(), 0o644)
+}
+
+// Document returns s as a decoded JSON value, the form compared by
+// output.DiffJSON.
+func (s *Snapshot) Document() interface{} {
+	doc := map[string]interface{}{"status": float64(s.Status), "body": s.Body}
+	if s.ContentType != "" {
+		doc["content_type"] = s.ContentType
+	}
+	return doc
+}
@@ -0,0 +1,99 @@
+package snapshot
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var jsonHeader = http.Header{"Content-Type": {"application/json; charset=utf-8"}}
+
+func TestNormalize_Ignore(t *testing.T) {
+	body := `{"id":7,"name":"Ada","created_at":"2024-01-01","items":[{"id":1,"n":"a"},{"id":2,"n":"b"}]}`
+	s, err := Normalize(200, jsonHeader, []byte(body), []string{".id", ".created_at", ".items[].id", ".missing"})
+	if err != nil {
+		t.Fatalf("Normalize: %v", err)
+	}
+	want := map[string]interface{}{
+		"name":  "Ada",
+		"items": []interface{}{map[string]interface{}{"n": "a"}, map[string]interface{}{"n": "b"}},
+	}
+	if s.Status != 200 || s.ContentType != "application/json" || !reflect.DeepEqual(s.Body, want) {
+		t.Errorf("got %+v", s)
+	}
+}
+
+func TestSnapshot_Ignore(t *testing.T) {
+	s := &Snapshot{Status: 200, Body: map[string]interface{}{"id": 7.0, "name": "Ada"}}
+	if err := s.Ignore([]string{".id"}); err != nil {
+		t.Fatalf("Ignore: %v", err)
+	}
+	if want := map[string]interface{}{"name": "Ada"}; !reflect.DeepEqual(s.Body, want) {
+		t.Errorf("body = %v", s.Body)
+	}
+	text := &Snapshot{Status: 200, Body: "plain"}
+	if err := text.Ignore([]string{".id"}); err != nil || text.Body != "plain" {
+		t.Errorf("text body: %v %v", text.Body, err)
+	}
+}
+
+func TestNormalize_Errors(t *testing.T) {
+	if _, err := Normalize(200, jsonHeader, []byte(`{}`), []string{".["}); err == nil || !strings.Contains(err.Error(), `ignore path ".["`) {
+		t.Errorf("bad expression: %v", err)
+	}
+	if _, err := Normalize(200, jsonHeader, []byte(`{"a":1}`), []string{".a[0]"}); err == nil {
+		t.Error("expected an error indexing a number")
+	}
+	if _, err := Normalize(200, http.Header{}, []byte("plain"), []string{".id"}); err == nil {
+		t.Error("ignore paths on a text body should fail")
+	}
+	s, err := Normalize(204, http.Header{}, nil, nil)
+	if err != nil || s.Body != "" {
+		t.Errorf("empty body: %+v %v", s, err)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := Path(dir, "dev", "get-user")
+	if want := filepath.Join(dir, ".reqo", "snapshots", "dev", "get-user.json"); path != want {
+		t.Errorf("Path = %s, want %s", path, want)
+	}
+	s, _ := Normalize(200, jsonHeader, []byte(`{"b":1,"a":"<x>"}`), nil)
+	if err := s.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	want := "{\n  \"status\": 200,\n  \"content_type\": \"application/json\",\n  \"body\": {\n    \"a\": \"<x>\",\n    \"b\": 1\n  }\n}\n"
+	if string(data) != want {
+		t.Errorf("file:\n%s\nwant:\n%s", data, want)
+	}
+	got, err := Load(path)
+	if err != nil || !reflect.DeepEqual(got.Document(), s.Document()) {
+		t.Errorf("Load = %+v, %v", got, err)
+	}
+}
+
+func TestNormalize_LargeNumbers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "big.json")
+	old, _ := Normalize(200, jsonHeader, []byte(`{"id":9007199254740992,"n":1}`), []string{".n"})
+	if err := old.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	saved, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	same, _ := Normalize(200, jsonHeader, []byte(`{"id":9007199254740992,"n":2}`), []string{".n"})
+	if !reflect.DeepEqual(saved.Document(), same.Document()) {
+		t.Errorf("equal bodies differ: %v vs %v", saved.Body, same.Body)
+	}
+	// 2^53+1 rounds to 2^53 as a float64
+	next, _ := Normalize(200, jsonHeader, []byte(`{"id":9007199254740993}`), nil)
+	if reflect.DeepEqual(saved.Document(), next.Document()) {
+		t.Errorf("ids beyond float64 precision compare equal: %v", next.Body)
+	}
+}
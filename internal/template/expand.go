@@ -37,3 +37,26 @@ func ExpandMap(in map[string]string, vars map[string]string) map[string]string {
 	}
 	return out
 }
+
+// ExpandValue expands every string inside a decoded YAML or JSON value,
+// such as GraphQL variables, leaving keys and other scalars alone.
+func ExpandValue(v interface{}, vars map[string]string) interface{} {
+	switch t := v.(type) {
+	case string:
+		return Expand(t, vars)
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(t))
+		for k, val := range t {
+			out[k] = ExpandValue(val, vars)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(t))
+		for i, val := range t {
+			out[i] = ExpandValue(val, vars)
+		}
+		return out
+	default:
+		return v
+	}
+}
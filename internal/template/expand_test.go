@@ -171,3 +171,24 @@ func TestExpandMap_DoesNotMutateInput(t *testing.T) {
 		t.Errorf("input map was mutated")
 	}
 }
+
+func TestExpandValue(t *testing.T) {
+	in := map[string]interface{}{
+		"${id}": "${id}",
+		"list":  []interface{}{"a-${id}", 3, true},
+		"n":     1.5,
+	}
+	got := ExpandValue(in, map[string]string{"id": "42"}).(map[string]interface{})
+	if got["${id}"] != "42" {
+		t.Errorf("string values should be expanded, keys kept: %v", got)
+	}
+	if list := got["list"].([]interface{}); list[0] != "a-42" || list[1] != 3 || list[2] != true {
+		t.Errorf("list = %v", list)
+	}
+	if got["n"] != 1.5 {
+		t.Errorf("n = %v", got["n"])
+	}
+	if in["${id}"] != "${id}" {
+		t.Error("input should not be modified")
+	}
+}